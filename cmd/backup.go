@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [caminho]",
+	Short: "Criar um backup do banco de dados",
+	Long: `Cria uma cópia consistente do banco de dados usando a API de backup
+online do SQLite, segura mesmo se outro processo estiver escrevendo.
+
+Sem caminho, o backup é salvo em ~/.togo/backups/ com a data e hora no nome.
+
+Snapshots automáticos antes de comandos destrutivos (clear, restore) podem
+ser ativados com a variável TOGO_SNAPSHOTS=<N>, que mantém os N mais recentes
+em ~/.togo/snapshots/.
+
+Exemplos:
+  togo backup
+  togo backup ~/tarefas-2025.db`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.BackupFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Manutenção do banco de dados",
+	Long: `Comandos de manutenção do arquivo SQLite do togo.
+
+Subcomandos:
+  check   - Verificar a integridade do banco
+  vacuum  - Compactar o arquivo do banco
+  info    - Mostrar caminho, tamanho, versão do esquema e contagens
+  repair  - Reconstruir o banco copiando as linhas recuperáveis
+  encrypt - Criptografar o banco com uma senha
+  decrypt - Voltar o banco para texto puro
+
+Exemplos:
+  togo db check
+  togo db info`,
+}
+
+var dbCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Verificar a integridade do banco de dados",
+	Long: `Executa PRAGMA integrity_check e PRAGMA foreign_key_check e lista os
+problemas encontrados.
+
+Exemplo:
+  togo db check`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		// Um banco corrompido pode falhar nas migrações de openDB.
+		if err := openRawDB(); err != nil {
+			fmt.Println("Erro:", err)
+			return
+		}
+		err := internal.CheckFuncDB()
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var dbVacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Compactar o arquivo do banco de dados",
+	Long: `Reconstrói o arquivo do banco liberando o espaço ocupado por dados
+removidos.
+
+Exemplo:
+  togo db vacuum`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.VacuumFuncDB()
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var dbInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Mostrar informações do banco de dados",
+	Long: `Mostra o caminho e o tamanho do arquivo, a versão do esquema e a
+contagem de linhas de cada tabela, incluindo as removidas (soft delete).
+
+Exemplo:
+  togo db info`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.InfoFuncDB()
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var dbRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Reconstruir um banco de dados corrompido",
+	Long: `Copia todas as linhas que ainda podem ser lidas para um banco novo e
+passa a usá-lo. O arquivo original é mantido com o sufixo .corrupt-<data>.
+
+Exemplo:
+  togo db repair`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		// Um banco corrompido pode falhar nas migrações de openDB.
+		if err := openRawDB(); err != nil {
+			fmt.Println("Erro:", err)
+			return
+		}
+		err := internal.RepairFuncDB()
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var dbEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Criptografar o banco de dados",
+	Long: `Converte o banco para o formato criptografado (AES-256-GCM, chave
+derivada da senha com PBKDF2). O arquivo em texto puro é removido e o banco
+passa a ser salvo em ~/.togo/tasks.db.enc.
+
+A senha é lida, nesta ordem, de:
+  - variável TOGO_PASSPHRASE
+  - arquivo apontado por TOGO_KEYFILE (ou ~/.togo/keyfile), com permissão 600
+  - prompt no terminal
+
+Enquanto o banco estiver criptografado, todo comando precisa da senha.
+
+Exemplo:
+  togo db encrypt`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.EncryptFuncDB()
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var dbDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Descriptografar o banco de dados",
+	Long: `Grava o banco criptografado de volta em texto puro (~/.togo/tasks.db)
+e remove o arquivo criptografado.
+
+Exemplo:
+  TOGO_PASSPHRASE=minha-senha togo db decrypt`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.DecryptFuncDB()
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	dbCmd.AddCommand(dbCheckCmd, dbVacuumCmd, dbInfoCmd, dbRepairCmd, dbEncryptCmd, dbDecryptCmd)
+	rootCmd.AddCommand(dbCmd)
+}
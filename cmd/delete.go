@@ -12,11 +12,12 @@ var deleteCmd = &cobra.Command{
 	Short: "Deletar uma tarefa",
 	Long: `Remove permanentemente uma tarefa do sistema.
 
-O ID deve ser fornecido como um argumento numérico.
+A tarefa é indicada pelo ID numérico ou por um prefixo único do UUID.
 
 Exemplo:
   togo delete 1
-  togo delete 5`,
+  togo delete 5
+  togo delete 3f0c2a9e`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := internal.DeleteFuncDB(args)
 		if err != nil {
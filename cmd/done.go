@@ -12,10 +12,13 @@ var doneCmd = &cobra.Command{
 	Short: "Marcar uma tarefa como concluída",
 	Long: `Marca uma tarefa como concluída registrando o timestamp de conclusão.
 
-O ID deve ser fornecido como um argumento numérico.
+A tarefa é indicada pelo ID numérico ou pelo UUID, inteiro ou só o
+começo, desde que nenhuma outra tarefa comece igual (o UUID aparece no
+togo list). Um argumento só com dígitos é sempre lido como ID.
 
 Exemplo:
-  togo done 1`,
+  togo done 1
+  togo done 3f0c2a9e`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := internal.DoneFuncDB(args)
 		if err != nil {
@@ -12,8 +12,11 @@ var editCmd = &cobra.Command{
 	Short: "Edita a descricao de uma tarefa",
 	Long: `Edita a descrição de uma tarefa
 
+A tarefa é indicada pelo ID numérico ou por um prefixo único do UUID.
+
 Exemplo:
-	togo edit <id> <nova descrição>`,
+	togo edit <id> <nova descrição>
+	togo edit 3f0c2a9e "nova descrição"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := internal.EditFuncDB(args)
 		if err != nil {
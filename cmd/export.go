@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exportar as tarefas para outro formato",
+	Long: `Exporta todas as tarefas no formato escolhido, na saída padrão ou em
+um arquivo (--output).
+
+Formatos:
+  json         - formato completo do togo (IDs, datas e tarefas apagadas)
+  todotxt      - todo.txt (prioridade, datas, +projeto, @contexto, chave:valor)
+  taskwarrior  - JSON do "task export" do Taskwarrior
+  ics          - iCalendar (VTODO), para aplicativos de calendário
+  markdown     - checklist "- [ ]" agrupado por projeto
+  org          - headlines TODO/DONE do org-mode (Emacs)
+
+Exemplos:
+  togo export --format todotxt
+  togo export --format todotxt -o todo.txt
+  togo export --format taskwarrior -o tarefas.json
+  togo export --format ics -o tarefas.ics
+  togo export --format json -o lista.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.ExportFuncDB(args, exportFormat, exportOutput)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "todotxt", "formato de saída")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "arquivo de saída (padrão: saída padrão)")
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var gitHookForce bool
+
+var gitCmd = &cobra.Command{
+	Use:   "git",
+	Short: "Integração com o git",
+	Long: `Liga tarefas aos commits que as mencionam.
+
+Com os hooks instalados, uma mensagem de commit com "togo #12" vincula o
+commit à tarefa 12, e "closes togo #12" (ou fixes/resolves) também a
+conclui. Os commits vinculados aparecem em "togo list".
+
+Subcomandos:
+  hook install - Instalar os hooks no repositório atual`,
+}
+
+var gitHookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Gerenciar os hooks do git",
+}
+
+var gitHookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Instalar os hooks do togo no repositório atual",
+	Long: `Instala dois hooks no repositório do diretório atual:
+
+  commit-msg  - recusa o commit se a mensagem citar uma tarefa que não existe
+  post-commit - vincula o commit às tarefas citadas e conclui as fechadas
+
+Se --list ou --global for usado, os hooks usam a mesma lista. Hooks que não
+foram instalados pelo togo só são substituídos com --force (o original é
+guardado com a extensão .bak).
+
+Exemplos:
+  togo git hook install
+  togo --list trabalho git hook install`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		var togoArgs []string
+		if listName != "" {
+			togoArgs = append(togoArgs, "--list", listName)
+		}
+		if global {
+			togoArgs = append(togoArgs, "--global")
+		}
+		err := internal.GitHookInstallFunc(".", gitHookForce, togoArgs)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+// Os comandos abaixo são chamados pelos hooks e não aparecem na ajuda. Em
+// caso de erro saem com status 1, que é o que o git verifica.
+
+var gitCommitMsgCmd = &cobra.Command{
+	Use:    "commit-msg <arquivo>",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := internal.GitCommitMsgFunc(args); err != nil {
+			fmt.Fprintln(os.Stderr, "togo:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var gitPostCommitCmd = &cobra.Command{
+	Use:    "post-commit",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := internal.GitPostCommitFunc("."); err != nil {
+			fmt.Fprintln(os.Stderr, "togo:", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gitCmd)
+	gitCmd.AddCommand(gitHookCmd, gitCommitMsgCmd, gitPostCommitCmd)
+	gitHookCmd.AddCommand(gitHookInstallCmd)
+
+	gitHookInstallCmd.Flags().BoolVar(&gitHookForce, "force", false, "substituir hooks existentes que não são do togo")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hooksTestDescription string
+	hooksTestPost        bool
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Ver e testar os hooks das tarefas",
+	Long: `Os hooks são executáveis em ~/.togo/hooks que rodam antes (pre) e depois
+(post) de create, edit, done e delete, e das mesmas alterações pelo togo
+serve, pelo togo rpc, pelo sync-md e pelos commits com "closes togo #N". O
+nome começa com o evento: on-add, on-modify, on-done ou on-delete, com
+qualquer sufixo (on-add.ticket). Os hooks de um evento rodam em ordem
+alfabética.
+
+Cada hook recebe a fase (pre ou post) como argumento e a tarefa em JSON na
+entrada padrão. Na fase pre, sair com código diferente de zero recusa a
+alteração (a saída de erro vira a mensagem), e escrever a tarefa em JSON na
+primeira linha da saída a altera. As demais linhas são mostradas. Falhas na
+fase post só viram avisos.
+
+TOGO_HOOKS=0 desativa os hooks; TOGO_HOOK_TIMEOUT muda o tempo limite de
+cada hook (padrão: 10s).
+
+Subcomandos:
+  list                           - Listar os hooks
+  test <evento> [id] [--post]    - Rodar os hooks sem gravar nada
+
+Exemplos:
+  togo hooks list
+  togo hooks test add --description "PROJ-12 Corrigir login"
+  togo hooks test done 3 --post`,
+}
+
+var hooksListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "Listar os hooks de ~/.togo/hooks",
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.HooksListFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var hooksTestCmd = &cobra.Command{
+	Use:   "test <evento> [id]",
+	Short: "Rodar os hooks de um evento sem gravar nada",
+	Long: `Roda os hooks pre de um evento (add, modify, done ou delete) com a tarefa
+de ID id, ou, no add, com uma tarefa nova, e mostra a tarefa que seria
+gravada. --description troca a descrição; --post roda também os hooks post.
+Nada é gravado no banco.
+
+Exemplos:
+  togo hooks test add --description "Corrigir login"
+  togo hooks test modify 3 --description "PROJ-12 Corrigir login"
+  togo hooks test done 3 --post`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.HooksTestFuncDB(args, hooksTestDescription, hooksTestPost)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksListCmd, hooksTestCmd)
+
+	hooksTestCmd.Flags().StringVar(&hooksTestDescription, "description", "", "descrição da tarefa de teste")
+	hooksTestCmd.Flags().BoolVar(&hooksTestPost, "post", false, "rodar também os hooks post")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat     string
+	importOnConflict string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <arquivo>",
+	Short: "Importar tarefas de um arquivo",
+	Long: `Importa as tarefas de um arquivo criado pelo togo ou por outra
+ferramenta. O formato é detectado pela extensão (.json = togo, .txt =
+todo.txt, .ics = iCalendar, .md = Markdown, .org = org-mode), ou pode ser
+escolhido com --format.
+
+Todas as tarefas são importadas em uma única transação: se alguma linha for
+inválida, nada é importado.
+
+Nos formatos todo.txt, Taskwarrior, iCalendar e org-mode, cada tarefa leva o
+seu UUID (o token uuid:, o campo uuid, o UID ou a propriedade ID):
+reimportar um arquivo exportado (e editado em outro aplicativo) atualiza as
+tarefas existentes em vez de duplicá-las.
+
+No formato json, os IDs do arquivo são mantidos. Quando a tarefa já existe no
+banco (mesmo UUID, ou outra tarefa no mesmo ID), --on-conflict decide: skip
+(padrão) mantém a tarefa local, overwrite a substitui pela do arquivo e
+renumber importa a do arquivo como cópia, com ID e UUID novos.
+Todos os registros são validados antes de qualquer gravação, e ao final um
+resumo mostra o que foi criado, sobrescrito, ignorado ou renumerado.
+
+No Markdown, a importação é o sync-md: cada item "- [ ]" não marcado vira uma
+tarefa e ganha no arquivo o marcador <!-- togo:UUID -->, para que importar ou
+sincronizar de novo não duplique as tarefas; itens já marcados são acertados.
+
+Exemplos:
+  togo import lista.json --on-conflict renumber
+  togo import todo.txt
+  togo import tarefas --format todotxt
+  togo import tarefas.ics
+  togo import notas.md
+  togo import agenda.org
+  task export > tw.json && togo import tw.json --format taskwarrior`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.ImportFuncDB(args, importFormat, importOnConflict)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVarP(&importFormat, "format", "f", "", "formato do arquivo (padrão: detectado pela extensão)")
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", "", "no formato json, o que fazer com IDs já existentes: skip, overwrite ou renumber (padrão: skip)")
+}
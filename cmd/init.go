@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Criar uma lista de tarefas para o diretório atual",
+	Long: `Cria a pasta .togo/ no diretório atual com um banco de tarefas próprio.
+
+Ao rodar o togo, ele procura .togo/ ou .togo.toml no diretório atual e nos
+diretórios acima; se encontrar, usa a lista desse projeto. Caso contrário,
+usa a lista global (~/.togo). Use --global para ignorar a lista do projeto.
+
+O arquivo .togo.toml é opcional e aceita:
+  name = "meu-projeto"          # nome exibido (padrão: nome do diretório)
+  database = ".togo/tasks.db"   # caminho do banco, relativo ao arquivo
+
+Exemplo:
+  cd ~/code/meu-repo
+  togo init`,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.InitFunc(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var listsCmd = &cobra.Command{
+	Use:   "lists",
+	Short: "Listar as listas de tarefas",
+	Long: `Mostra todas as listas de tarefas. A lista padrão (usada quando --list
+não é informado) aparece marcada com '*'.
+
+Cada lista tem seu próprio banco de dados: a lista "default" fica em
+~/.togo/tasks.db e as demais em ~/.togo/lists/<nome>.db.
+
+Exemplo:
+  togo lists`,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.ListsFunc(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var useCmd = &cobra.Command{
+	Use:   "use <lista>",
+	Short: "Definir a lista de tarefas padrão",
+	Long: `Define a lista usada pelos comandos quando --list não é informado.
+A lista é criada no primeiro uso.
+
+Exemplos:
+  togo use trabalho
+  togo use default`,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.UseFunc(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listsCmd)
+	rootCmd.AddCommand(useCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+	"levyvix/togo/internal/plugin"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginAnnotation marca os subcomandos dos plugins.
+const pluginAnnotation = "togo/plugin"
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Listar os plugins (executáveis togo-<nome> no PATH)",
+	Long: `Lista os plugins: executáveis togo-<nome> no PATH, que viram o subcomando
+togo <nome>, como no git. Um plugin não substitui um comando do togo, e vale
+o primeiro executável de cada nome no PATH.
+
+O plugin recebe os argumentos depois do nome e roda com as variáveis:
+  TOGO_DB    - caminho do banco da lista atual
+  TOGO_LIST  - nome da lista atual
+  TOGO_BIN   - caminho do togo que o chamou
+
+Comandos do togo chamados pelo plugin usam o banco de TOGO_DB. Para ler e
+alterar as tarefas, o plugin deve usar "$TOGO_BIN rpc" (JSON-RPC na entrada
+e na saída padrão) em vez de abrir o banco: assim valem as validações e os
+hooks do togo, os webhooks recebem as alterações, e bancos criptografados
+funcionam.
+
+Exemplo (um plugin em shell, salvo como togo-pendentes no PATH):
+  #!/bin/sh
+  echo '{"jsonrpc":"2.0","id":1,"method":"tasks.list","params":{"status":"pending"}}' |
+    "$TOGO_BIN" rpc`,
+	Annotations: map[string]string{noDBAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.PluginsFuncDB(args, builtinCommands())
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+// builtinCommands retorna os nomes e apelidos dos comandos do togo, que
+// têm precedência sobre os plugins.
+func builtinCommands() []string {
+	names := []string{"help", "completion"}
+	for _, c := range rootCmd.Commands() {
+		if c.Annotations[pluginAnnotation] != "" {
+			continue
+		}
+		names = append(names, c.Name())
+		names = append(names, c.Aliases...)
+	}
+	return names
+}
+
+// registerPlugins adiciona um subcomando para cada plugin do PATH.
+func registerPlugins() {
+	builtins := builtinCommands()
+	for _, p := range plugin.Find(os.Getenv("PATH")) {
+		if !slices.Contains(builtins, p.Name) {
+			rootCmd.AddCommand(pluginCommand(p))
+		}
+	}
+}
+
+func pluginCommand(p plugin.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              "Plugin (" + p.Path + ")",
+		DisableFlagParsing: true,
+		Annotations:        map[string]string{noDBAnnotation: "true", pluginAnnotation: "true"},
+		Run: func(cmd *cobra.Command, args []string) {
+			// Sem o parse de flags, --list e --global antes do nome do
+			// plugin chegam em args; os argumentos do plugin vêm depois.
+			before, after := splitPluginArgs(os.Args[1:], p.Name)
+			if err := rootCmd.PersistentFlags().Parse(before); err != nil {
+				fmt.Println("Erro:", err)
+				os.Exit(1)
+			}
+			path, list, _, err := dbTarget()
+			if err != nil {
+				fmt.Println("Erro:", err)
+				os.Exit(1)
+			}
+			bin, err := os.Executable()
+			if err != nil {
+				fmt.Println("Erro:", err)
+				os.Exit(1)
+			}
+			code, err := plugin.Run(p, after, plugin.Env(path, list, bin))
+			if err != nil {
+				fmt.Println("Erro:", err)
+			}
+			os.Exit(code)
+		},
+	}
+}
+
+// splitPluginArgs separa os argumentos do togo, antes do nome do plugin,
+// dos argumentos do plugin.
+func splitPluginArgs(args []string, name string) (before, after []string) {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case name:
+			return args[:i], args[i+1:]
+		case "-l", "--list":
+			// O valor da flag pode ter o mesmo nome do plugin.
+			i++
+		}
+	}
+	return args, nil
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <arquivo>",
+	Short: "Restaurar o banco de dados a partir de um backup",
+	Long: `Substitui todas as tarefas atuais pelo conteúdo de um backup.
+
+O arquivo é validado antes: precisa ser um banco do togo com uma versão de
+esquema suportada por esta versão do programa.
+
+Exemplo:
+  togo restore ~/.togo/backups/tasks-20251221-143000.000000.db`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.RestoreFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
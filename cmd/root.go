@@ -1,11 +1,26 @@
 package cmd
 
 import (
+	"levyvix/togo/internal/config"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/plugin"
+	"log"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+// noDBAnnotation marca comandos que não precisam abrir o banco de dados.
+const noDBAnnotation = "togo/no-db"
+
+var (
+	// listName é a lista escolhida com --list.
+	listName string
+
+	// global ignora a lista do diretório atual (.togo/ ou .togo.toml).
+	global bool
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "togo",
 	Short: "Gerenciador de tarefas em linha de comando",
@@ -17,6 +32,24 @@ Comandos disponíveis:
   done <id>           - Marcar uma tarefa como concluída
   delete <id>         - Deletar uma tarefa
 	edit <id> <nova descricao> - Editar a descricao de uma tarefa
+  init                - Criar uma lista própria para o diretório atual
+  lists               - Listar as listas de tarefas
+  use <lista>         - Definir a lista padrão
+  backup [caminho]    - Criar um backup do banco de dados
+  restore <arquivo>   - Restaurar o banco a partir de um backup
+  sync [--remote url] - Sincronizar com outras máquinas via git
+  sync push|pull <peer> - Sincronizar com outro banco, sem conflitos
+  sync-md <arquivo>   - Sincronizar com um checklist em Markdown
+  serve [--addr end]  - Servir as tarefas por uma API REST (e gRPC) e interface web
+  user add <nome>     - Cadastrar um usuário da API
+  token create <user> - Criar um token de acesso à API
+  webhook add <url>   - Enviar os eventos de tarefas a um webhook
+  hooks list|test     - Ver e testar os scripts de ~/.togo/hooks
+  plugins             - Listar os plugins (togo-<nome> no PATH)
+  rpc                 - Servir as tarefas por JSON-RPC na entrada padrão
+  scan [diretório]    - Criar tarefas a partir de comentários TODO/FIXME
+  git hook install    - Vincular commits às tarefas ("closes togo #12")
+  db check|vacuum|info|repair - Manutenção do banco de dados
 
 Exemplos:
   togo create "Estudar Go"
@@ -24,12 +57,75 @@ Exemplos:
   togo done 1
   togo delete 2
 	togo edit 1 "nova descricao"
+  togo --list trabalho create "Revisar PR"
 
 
 Use "togo [command] --help" para mais informações sobre um comando.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if cmd.Annotations[noDBAnnotation] != "" {
+			return
+		}
+		if err := openDB(); err != nil {
+			log.Fatalf("Erro: %v", err)
+		}
+	},
+}
+
+// dbTarget resolve o banco do comando, sem abri-lo: o de TOGO_DB (dentro de
+// um plugin) ou o resolvido por database.Resolve. Retorna o projeto quando
+// a lista é a do diretório.
+func dbTarget() (path, list string, project *config.Project, err error) {
+	if path, list, ok := pluginDB(); ok {
+		return path, list, nil, nil
+	}
+	return database.Resolve(listName, global)
+}
+
+// pluginDB retorna o banco de TOGO_DB quando o togo é chamado por um plugin
+// sem --list nem --global.
+func pluginDB() (path, list string, ok bool) {
+	if listName != "" || global {
+		return "", "", false
+	}
+	path = os.Getenv(plugin.DBEnv)
+	return path, os.Getenv(plugin.ListEnv), path != ""
+}
+
+// openDB abre o banco do comando com database.InitDB, ou o de TOGO_DB
+// dentro de um plugin.
+func openDB() error {
+	path, list, ok := pluginDB()
+	if !ok {
+		return database.InitDB(listName, global)
+	}
+	if err := database.Open(path); err != nil {
+		return err
+	}
+	database.List = list
+	database.ProjectRoot = ""
+	return nil
+}
+
+// openRawDB abre o banco resolvido por dbTarget somente para leitura e sem
+// migrações (ver database.OpenRaw).
+func openRawDB() error {
+	path, list, project, err := dbTarget()
+	if err != nil {
+		return err
+	}
+	if err := database.OpenRaw(path); err != nil {
+		return err
+	}
+	database.List = list
+	database.ProjectRoot = ""
+	if project != nil {
+		database.ProjectRoot = project.Root
+	}
+	return nil
 }
 
 func Execute() {
+	registerPlugins()
 	err := rootCmd.Execute()
 	if err != nil {
 		os.Exit(1)
@@ -41,7 +137,8 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.go-todo-list.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&listName, "list", "l", "", "lista de tarefas a usar (padrão: a definida com 'togo use')")
+	rootCmd.PersistentFlags().BoolVarP(&global, "global", "g", false, "ignorar a lista do diretório atual e usar a lista global")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
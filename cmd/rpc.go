@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var rpcCmd = &cobra.Command{
+	Use:   "rpc",
+	Short: "Servir as tarefas por JSON-RPC na entrada e na saída padrão",
+	Long: `Atende chamadas JSON-RPC 2.0 sobre as tarefas da lista aberta: uma chamada
+por linha na entrada padrão, uma resposta por linha na saída, até o fim da
+entrada. É a forma de os plugins lerem e alterarem as tarefas (ver togo
+plugins); não há usuários, como na linha de comando. As alterações passam
+pelos hooks de ~/.togo/hooks (ver togo hooks), cujas mensagens vão para a
+saída de erro.
+
+Métodos:
+  togo.info                          - Lista e banco abertos
+  tasks.list     {status, q, page, per_page}
+  tasks.get      {id}
+  tasks.create   {description, priority, tags, due, ...}
+  tasks.update   {id, description, done, priority, tags, due, ...}
+  tasks.complete {id}
+  tasks.delete   {id}
+
+O id aceita o ID ou um prefixo do UUID, como nos comandos, e as tarefas usam
+os campos do export em json. Os erros usam os códigos do JSON-RPC (-32602
+para parâmetros inválidos) e -32001 (tarefa não encontrada) e -32002
+(conflito, como concluir uma tarefa já concluída).
+
+Exemplo:
+  echo '{"jsonrpc":"2.0","id":1,"method":"tasks.create","params":{"description":"Estudar Go"}}' | togo rpc`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.RPCFuncDB(args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rpcCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [diretório]",
+	Short: "Criar tarefas a partir de comentários TODO/FIXME/HACK",
+	Long: `Percorre os arquivos do diretório (padrão: o atual), respeitando os
+.gitignore, e cria uma tarefa para cada comentário TODO, FIXME ou HACK, com
+o local (arquivo:linha) no extra "source" e o tipo como tag.
+Em arquivos Markdown, só comentários <!-- TODO --> contam. Dentro de um
+repositório git, valem também os .gitignore dos diretórios acima e o
+.git/info/exclude.
+
+Cada comentário é identificado por um fingerprint (arquivo, tipo e texto),
+então rodar o scan de novo atualiza as tarefas existentes em vez de
+duplicá-las. Quando um comentário some do código, a tarefa é concluída.
+
+Exemplos:
+  togo scan
+  togo scan ~/code/meu-repo`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.ScanFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+}
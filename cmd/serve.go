@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr string
+	serveGRPC string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Servir as tarefas por uma API REST e uma interface web",
+	Long: `Inicia um servidor HTTP com uma API REST sobre as tarefas da lista aberta,
+até ser interrompido com Ctrl+C. Abra o endereço no navegador para usar a
+interface web (listar, buscar, criar, concluir, editar e apagar tarefas), que
+vem embutida no binário e funciona sem internet.
+
+Endpoints:
+  GET    /tasks            - Listar (?status=all|pending|done&q=texto&page=1&per_page=50)
+  POST   /tasks            - Criar
+  DELETE /tasks            - Apagar todas (como togo clear)
+  GET    /tasks/{id}       - Ler
+  PATCH  /tasks/{id}       - Alterar os campos enviados
+  DELETE /tasks/{id}       - Apagar
+  POST   /tasks/{id}/done  - Concluir
+  POST   /tasks/{id}/shares        - Compartilhar com um usuário ({"user": ...})
+  DELETE /tasks/{id}/shares/{user} - Desfazer o compartilhamento
+  GET    /events           - Alterações ao vivo por Server-Sent Events
+  GET    /events/ws        - Alterações ao vivo por WebSocket
+  GET    /openapi.json     - Documento OpenAPI da API
+
+O {id} aceita o ID ou um prefixo do UUID, como nos comandos. As tarefas usam
+os mesmos campos do export em json, e os erros respondem
+{"error": "mensagem"} com o status HTTP correspondente.
+
+Sem usuários cadastrados, a API não exige autenticação. Depois de
+togo user add, toda requisição precisa de um token (togo token create) no
+cabeçalho Authorization: Bearer <token>, e cada usuário só altera as
+próprias tarefas e as compartilhadas com ele.
+
+Enquanto roda, o servidor também envia aos webhooks cadastrados (togo
+webhook add) as entregas da fila, inclusive as das alterações feitas pela
+linha de comando e as pendentes de antes. As alterações pela API
+passam pelos hooks de ~/.togo/hooks (ver togo hooks).
+
+Com --grpc, atende também a API gRPC (o TaskService de
+api/togo/v1/togo.proto: Create, Get, List, Update, Complete, Delete e o
+fluxo Watch com as alterações ao vivo) em outra porta, com os mesmos tokens
+nos metadados authorization. Sem endereço, usa localhost:9090.
+
+Exemplos:
+  togo serve
+  togo serve --addr :8080
+  togo serve --grpc
+  togo serve --grpc=:9090
+  curl -X POST localhost:8080/tasks -H 'Content-Type: application/json' -d '{"description": "Estudar Go"}'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.ServeFuncDB(args, serveAddr, serveGRPC)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "endereço e porta para atender")
+	serveCmd.Flags().StringVar(&serveGRPC, "grpc", "", "atender também a API gRPC neste endereço")
+	serveCmd.Flags().Lookup("grpc").NoOptDefVal = "localhost:9090"
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var syncRemote string
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sincronizar as tarefas com outras máquinas por um repositório git",
+	Long: `Grava cada tarefa como um arquivo JSON em um repositório git ao lado do
+banco (~/.togo/sync/<lista>), faz commit, traz as mudanças do remoto,
+combina as duas versões e envia o resultado de volta.
+
+O remoto pode ser qualquer URL aceita pelo git, inclusive um repositório
+bare local. Ele é configurado na primeira vez com --remote e lembrado nas
+seguintes.
+
+As tarefas são identificadas entre máquinas por um UUID (o ID numérico é
+local a cada banco). Edições concorrentes da mesma tarefa são combinadas
+campo a campo: um campo alterado só de um lado fica com essa alteração, e
+um campo alterado dos dois lados fica com a edição mais recente
+(updated_at). Tarefas apagadas continuam no repositório, marcadas como
+apagadas, para que a remoção chegue às outras máquinas.
+
+Bancos criptografados não podem ser sincronizados, porque os arquivos do
+repositório ficam em texto puro.
+
+Subcomandos (sync sem git, por operações):
+  push <peer> - Enviar as mudanças locais ao peer
+  pull <peer> - Trazer as mudanças do peer
+  serve       - Atender push e pull de outras máquinas por HTTP
+
+Exemplos:
+  git init --bare ~/Dropbox/togo.git
+  togo sync --remote ~/Dropbox/togo.git
+  togo sync
+  togo --list trabalho sync --remote git@github.com:ana/togo-trabalho.git`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.SyncFuncDB(args, syncRemote)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+const syncPeerHelp = `Cada campo de cada tarefa é combinado de forma independente, então
+edições feitas offline em campos diferentes da mesma tarefa, em máquinas
+diferentes, são todas mantidas; se o mesmo campo mudou nas duas, vale a
+edição mais recente. Toda mudança vira uma operação em um log dentro do
+banco, datada por um relógio lógico híbrido (que continua correto mesmo com
+relógios de máquinas adiantados ou atrasados), e cada lado envia só as
+operações que o outro ainda não tem. Não importa em que ordem as máquinas
+trocam operações: quando todas tiverem as mesmas, as tarefas são iguais.
+
+O peer é o caminho de outro banco do togo (por exemplo, em uma pasta
+sincronizada ou em um pendrive) ou a URL de um togo sync serve.`
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push <peer>",
+	Short: "Enviar as mudanças locais para outro banco",
+	Long: `Envia ao peer as operações do banco local que ele ainda não tem e as
+aplica às tarefas de lá.
+
+` + syncPeerHelp + `
+
+Exemplos:
+  togo sync push ~/Dropbox/togo/tasks.db
+  togo sync push http://casa:8765`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.SyncPushFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull <peer>",
+	Short: "Trazer as mudanças de outro banco",
+	Long: `Traz do peer as operações que o banco local ainda não tem e as aplica
+às tarefas daqui.
+
+` + syncPeerHelp + `
+
+Exemplos:
+  togo sync pull ~/Dropbox/togo/tasks.db
+  togo sync pull http://casa:8765`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.SyncPullFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var syncServeAddr string
+
+var syncServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Atender push e pull de outras máquinas por HTTP",
+	Long: `Serve o banco aberto para togo sync push e pull de outras máquinas, até
+ser interrompido com Ctrl+C. Não há autenticação: use em uma rede
+confiável ou atrás de um proxy.
+
+Exemplos:
+  togo sync serve
+  togo --list trabalho sync serve --addr 0.0.0.0:9000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.SyncServeFuncDB(args, syncServeAddr)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncPushCmd, syncPullCmd, syncServeCmd)
+
+	syncCmd.Flags().StringVar(&syncRemote, "remote", "", "URL do repositório git remoto (lembrada para as próximas vezes)")
+	syncServeCmd.Flags().StringVar(&syncServeAddr, "addr", "localhost:8765", "endereço e porta para atender")
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var syncMdCmd = &cobra.Command{
+	Use:   "sync-md <arquivo>",
+	Short: "Sincronizar as tarefas com um checklist em Markdown",
+	Long: `Reconcilia os itens "- [ ] ..." de um arquivo Markdown (notas de
+reunião, README, etc.) com as tarefas, nos dois sentidos.
+
+  - Itens não marcados e ainda desconhecidos viram tarefas, e a linha ganha
+    o marcador <!-- togo:UUID -->.
+  - Se o checkbox de um item com marcador difere da tarefa, vale o lado
+    alterado por último: a tarefa é concluída/reaberta, ou o checkbox é
+    atualizado no arquivo.
+
+As tarefas passam pelos hooks (togo hooks); um item recusado por um hook
+fica como está e vira um aviso. O restante do arquivo não é alterado.
+
+Exemplo:
+  togo sync-md notas.md`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.SyncMarkdownFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncMdCmd)
+}
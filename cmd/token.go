@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var tokenName string
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Gerenciar os tokens de acesso à API (togo serve)",
+	Long: `Gerencia os tokens de acesso à API do togo serve. O token vai no
+cabeçalho Authorization: Bearer <token> de cada requisição. Só o hash do
+token fica no banco, então ele é mostrado uma única vez, na criação.
+
+Subcomandos:
+  create <usuário> [--name rótulo] - Criar um token
+  list                             - Listar os tokens
+  revoke <id>                      - Revogar um token
+
+Exemplos:
+  togo token create ana --name dashboard
+  togo token revoke 3`,
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create <usuário>",
+	Short: "Criar um token de acesso para um usuário",
+	Long: `Cria um token de acesso para o usuário e o mostra uma única vez.
+
+Exemplos:
+  togo token create ana
+  togo token create ana --name bot-do-slack`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.TokenCreateFuncDB(args, tokenName)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar os tokens de acesso",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.TokenListFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var tokenRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revogar um token de acesso",
+	Long: `Revoga o token de ID informado (veja os IDs com togo token list).
+
+Exemplo:
+  togo token revoke 3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.TokenRevokeFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+	tokenCmd.AddCommand(tokenCreateCmd, tokenListCmd, tokenRevokeCmd)
+
+	tokenCreateCmd.Flags().StringVar(&tokenName, "name", "", "rótulo para identificar o token (ex.: dashboard)")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+
+	"github.com/spf13/cobra"
+)
+
+var userAdmin bool
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Gerenciar os usuários da API (togo serve)",
+	Long: `Gerencia os usuários que acessam a API do togo serve.
+
+Enquanto não houver usuários, a API não exige autenticação. Com pelo menos
+um, toda requisição precisa de um token (togo token create). Cada usuário
+só altera as tarefas que criou e as compartilhadas com ele; as tarefas
+criadas pela linha de comando ficam só para leitura. Administradores podem
+alterar todas e apagar todas (DELETE /tasks).
+
+Subcomandos:
+  add <nome> [--admin] - Cadastrar um usuário
+  list                 - Listar os usuários
+
+Exemplos:
+  togo user add ana
+  togo user add root --admin`,
+}
+
+var userAddCmd = &cobra.Command{
+	Use:   "add <nome>",
+	Short: "Cadastrar um usuário da API",
+	Long: `Cadastra um usuário da API. O nome usa letras minúsculas, dígitos, '.',
+'-' e '_'.
+
+Exemplos:
+  togo user add ana
+  togo user add root --admin`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.UserAddFuncDB(args, userAdmin)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var userListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar os usuários da API",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.UserListFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(userCmd)
+	userCmd.AddCommand(userAddCmd, userListCmd)
+
+	userAddCmd.Flags().BoolVar(&userAdmin, "admin", false, "permitir alterar e apagar as tarefas de todos")
+}
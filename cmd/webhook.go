@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"levyvix/togo/internal"
+	"levyvix/togo/internal/webhook"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhookEvents string
+	webhookSecret string
+	webhookLogID  uint
+	webhookLimit  int
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Gerenciar os webhooks dos eventos de tarefas",
+	Long: `Gerencia os webhooks: endereços que recebem, por POST, um JSON a cada
+tarefa criada, alterada, concluída ou apagada, pela linha de comando ou pela
+API. Cada alteração põe as entregas em uma fila no banco; o togo serve as
+envia enquanto estiver rodando.
+
+Cada entrega leva os cabeçalhos X-Togo-Event (o tipo do evento),
+X-Togo-Delivery (o ID da entrega) e X-Togo-Signature, com "sha256=" e o
+HMAC-SHA256 do corpo usando a chave do webhook. As entregas que falham são
+tentadas de novo em intervalos crescentes (30s, 1min, 2min... até 1h), até 8
+vezes, e ficam registradas em togo webhook log.
+
+Subcomandos:
+  add <url> [--events done,create] - Cadastrar um webhook
+  list                             - Listar os webhooks
+  remove <id>                      - Remover um webhook
+  log [--webhook id]               - Ver as últimas entregas
+  retry <id-da-entrega>            - Tentar de novo uma entrega que falhou
+
+Exemplos:
+  togo webhook add https://chat.exemplo.com/hook --events done,create
+  togo webhook log`,
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Cadastrar um webhook",
+	Long: fmt.Sprintf(`Cadastra um webhook que recebe os eventos indicados em --events (%s;
+padrão: todos). Sem --secret, gera uma chave para a assinatura e a mostra.
+
+Exemplos:
+  togo webhook add https://chat.exemplo.com/hook --events done,create
+  togo webhook add http://localhost:9000/ci --events done --secret minha-chave`, strings.Join(webhook.EventNames(), ", ")),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.WebhookAddFuncDB(args, webhookEvents, webhookSecret)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Listar os webhooks",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.WebhookListFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remover um webhook e as suas entregas",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.WebhookRemoveFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var webhookLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Ver as últimas entregas dos webhooks",
+	Long: `Mostra as últimas entregas, da mais recente para a mais antiga, com o
+estado (pendente, entregue ou falhou), as tentativas e o último erro.
+
+Exemplos:
+  togo webhook log
+  togo webhook log --webhook 2 --limit 50`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.WebhookLogFuncDB(args, webhookLogID, webhookLimit)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+var webhookRetryCmd = &cobra.Command{
+	Use:   "retry <id-da-entrega>",
+	Short: "Tentar de novo uma entrega que falhou",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := internal.WebhookRetryFuncDB(args)
+		if err != nil {
+			fmt.Println("Erro:", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(webhookAddCmd, webhookListCmd, webhookRemoveCmd, webhookLogCmd, webhookRetryCmd)
+
+	webhookAddCmd.Flags().StringVar(&webhookEvents, "events", "all", "eventos a enviar, separados por vírgula")
+	webhookAddCmd.Flags().StringVar(&webhookSecret, "secret", "", "chave do HMAC das assinaturas (padrão: gerada)")
+	webhookLogCmd.Flags().UintVar(&webhookLogID, "webhook", 0, "mostrar só as entregas deste webhook")
+	webhookLogCmd.Flags().IntVar(&webhookLimit, "limit", 20, "quantidade de entregas")
+}
@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"fmt"
+	"levyvix/togo/internal/database"
+)
+
+const (
+	MsgBackupCreated = "Backup criado em %s\n"
+	MsgRestored      = "Banco restaurado a partir de %s\n"
+)
+
+func BackupFuncDB(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("este comando aceita no máximo um argumento, você passou %d", len(args))
+	}
+
+	dest := database.DefaultBackupPath()
+	if len(args) == 1 {
+		dest = args[0]
+	}
+
+	if err := database.Backup(dest); err != nil {
+		return fmt.Errorf("erro ao criar backup: %w", err)
+	}
+	fmt.Printf(MsgBackupCreated, dest)
+	return nil
+}
+
+func RestoreFuncDB(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d", len(args))
+	}
+
+	if err := database.Restore(args[0]); err != nil {
+		return fmt.Errorf("erro ao restaurar backup: %w", err)
+	}
+	fmt.Printf(MsgRestored, args[0])
+	return nil
+}
@@ -0,0 +1,68 @@
+// Package config lê e grava as preferências do usuário em ~/.togo/config.toml.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config são as preferências persistidas entre execuções.
+type Config struct {
+	// List é a lista usada quando --list não é informado.
+	List string `toml:"list,omitempty"`
+}
+
+// Dir retorna o diretório de dados do togo: ~/.togo.
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".togo"), nil
+}
+
+// Path retorna o caminho do arquivo de configuração.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load lê a configuração. Um arquivo inexistente resulta na configuração
+// padrão, sem erro.
+func Load() (Config, error) {
+	var cfg Config
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil && !os.IsNotExist(err) {
+		return cfg, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save grava a configuração, criando ~/.togo se necessário.
+func Save(cfg Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := toml.NewEncoder(f).Encode(cfg); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// ProjectDir é a pasta que marca um diretório com lista própria.
+	ProjectDir = ".togo"
+
+	// ProjectFile é a alternativa em arquivo a ProjectDir.
+	ProjectFile = ".togo.toml"
+)
+
+// Project é uma lista de tarefas ligada a um diretório (por exemplo, um
+// repositório), descoberta a partir do diretório de trabalho.
+type Project struct {
+	// Root é o diretório que contém .togo/ ou .togo.toml.
+	Root string
+	// Name é o nome exibido; por padrão, o nome de Root.
+	Name string
+	// DBPath é o arquivo do banco do projeto.
+	DBPath string
+}
+
+// projectFile é o conteúdo de .togo.toml. Ambos os campos são opcionais;
+// database é relativo ao diretório do arquivo.
+type projectFile struct {
+	Name     string `toml:"name"`
+	Database string `toml:"database"`
+}
+
+// FindProject sobe a partir de start procurando .togo.toml ou uma pasta
+// .togo/. O diretório home é ignorado, já que ~/.togo guarda as listas
+// globais. Retorna nil se nenhum projeto for encontrado.
+func FindProject(start string) (*Project, error) {
+	home, _ := os.UserHomeDir()
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if dir != home {
+			p, err := loadProject(dir)
+			if err != nil || p != nil {
+				return p, err
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+func loadProject(dir string) (*Project, error) {
+	p := &Project{
+		Root:   dir,
+		Name:   filepath.Base(dir),
+		DBPath: filepath.Join(dir, ProjectDir, "tasks.db"),
+	}
+
+	file := filepath.Join(dir, ProjectFile)
+	if _, err := os.Stat(file); err == nil {
+		var pf projectFile
+		if _, err := toml.DecodeFile(file, &pf); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if pf.Name != "" {
+			p.Name = pf.Name
+		}
+		if pf.Database != "" {
+			p.DBPath = pf.Database
+			if !filepath.IsAbs(p.DBPath) {
+				p.DBPath = filepath.Join(dir, p.DBPath)
+			}
+		}
+		return p, nil
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, ProjectDir)); err == nil && info.IsDir() {
+		return p, nil
+	}
+	return nil, nil
+}
+
+// InitProject cria a pasta .togo/ em dir e retorna o projeto resultante.
+func InitProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if home, _ := os.UserHomeDir(); dir == home {
+		return nil, fmt.Errorf("the home directory already holds the global lists")
+	}
+	if p, err := loadProject(dir); err != nil || p != nil {
+		if err == nil {
+			err = fmt.Errorf("%s already has a togo list", dir)
+		}
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ProjectDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", ProjectDir, err)
+	}
+	return loadProject(dir)
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindProject tests discovery of directory-scoped lists
+func TestFindProject(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "code", "repo")
+	deep := filepath.Join(repo, "a", "b")
+	tomlRepo := filepath.Join(home, "code", "outro")
+	for _, dir := range []string{deep, filepath.Join(repo, ProjectDir), filepath.Join(home, ProjectDir), tomlRepo} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	toml := "name = \"meu-projeto\"\ndatabase = \"dados/tarefas.db\"\n"
+	if err := os.WriteFile(filepath.Join(tomlRepo, ProjectFile), []byte(toml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		start    string
+		wantRoot string
+		wantName string
+		wantDB   string
+	}{
+		{
+			name:     "Project root",
+			start:    repo,
+			wantRoot: repo,
+			wantName: "repo",
+			wantDB:   filepath.Join(repo, ProjectDir, "tasks.db"),
+		},
+		{
+			name:     "Nested directory",
+			start:    deep,
+			wantRoot: repo,
+			wantName: "repo",
+			wantDB:   filepath.Join(repo, ProjectDir, "tasks.db"),
+		},
+		{
+			name:     "Project file",
+			start:    tomlRepo,
+			wantRoot: tomlRepo,
+			wantName: "meu-projeto",
+			wantDB:   filepath.Join(tomlRepo, "dados", "tarefas.db"),
+		},
+		{
+			name:  "Home .togo is not a project",
+			start: filepath.Join(home, "code"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := FindProject(tt.start)
+			if err != nil {
+				t.Fatalf("FindProject(%q) unexpected error: %v", tt.start, err)
+			}
+			if tt.wantRoot == "" {
+				if p != nil {
+					t.Errorf("FindProject(%q) = %+v, want nil", tt.start, p)
+				}
+				return
+			}
+			if p == nil {
+				t.Fatalf("FindProject(%q) = nil, want project at %s", tt.start, tt.wantRoot)
+			}
+			if p.Root != tt.wantRoot || p.Name != tt.wantName || p.DBPath != tt.wantDB {
+				t.Errorf("FindProject(%q) = %+v, want root %s, name %s, db %s", tt.start, p, tt.wantRoot, tt.wantName, tt.wantDB)
+			}
+		})
+	}
+}
+
+// TestInitProject tests creating a directory-scoped list
+func TestInitProject(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, "repo")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := InitProject(dir)
+	if err != nil {
+		t.Fatalf("InitProject(%q) unexpected error: %v", dir, err)
+	}
+	if p.Root != dir {
+		t.Errorf("InitProject(%q).Root = %q, want %q", dir, p.Root, dir)
+	}
+	if _, err := InitProject(dir); err == nil {
+		t.Errorf("InitProject(%q) twice expected error, got nil", dir)
+	}
+	if _, err := InitProject(home); err == nil {
+		t.Errorf("InitProject(home) expected error, got nil")
+	}
+}
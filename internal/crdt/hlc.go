@@ -0,0 +1,112 @@
+package crdt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp é um instante de relógio lógico híbrido (HLC): o tempo físico
+// em nanossegundos, um contador lógico que desempata eventos no mesmo
+// tempo físico (ou vistos depois de um relógio adiantado) e o banco que o
+// gerou, que torna a ordem total entre bancos diferentes.
+type Timestamp struct {
+	Wall    int64
+	Logical uint32
+	Node    string
+}
+
+// Less ordena os instantes por tempo físico, contador e banco.
+func (t Timestamp) Less(u Timestamp) bool {
+	if t.Wall != u.Wall {
+		return t.Wall < u.Wall
+	}
+	if t.Logical != u.Logical {
+		return t.Logical < u.Logical
+	}
+	return t.Node < u.Node
+}
+
+// IsZero informa se t é o instante zero (nenhum evento).
+func (t Timestamp) IsZero() bool {
+	return t == Timestamp{}
+}
+
+// Time retorna o tempo físico de t.
+func (t Timestamp) Time() time.Time {
+	return time.Unix(0, t.Wall)
+}
+
+// String codifica t com largura fixa, de modo que a ordem das strings é a
+// mesma de Less (para tempos físicos não negativos).
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%016x.%08x.%s", t.Wall, t.Logical, t.Node)
+}
+
+// ParseTimestamp desfaz Timestamp.String.
+func ParseTimestamp(s string) (Timestamp, error) {
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) != 3 || len(parts[0]) != 16 || len(parts[1]) != 8 || parts[2] == "" {
+		return Timestamp{}, fmt.Errorf("instante inválido '%s'", s)
+	}
+	wall, err := strconv.ParseInt(parts[0], 16, 64)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("instante inválido '%s'", s)
+	}
+	logical, err := strconv.ParseUint(parts[1], 16, 32)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("instante inválido '%s'", s)
+	}
+	return Timestamp{Wall: wall, Logical: uint32(logical), Node: parts[2]}, nil
+}
+
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Timestamp) UnmarshalText(data []byte) error {
+	parsed, err := ParseTimestamp(string(data))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Clock é o relógio lógico híbrido de um banco. Os instantes gerados são
+// sempre maiores que todos os gerados ou observados antes, mesmo que o
+// relógio da máquina volte no tempo, e ficam próximos do tempo físico.
+type Clock struct {
+	last Timestamp
+}
+
+// NewClock cria o relógio do banco node, continuando de last (o último
+// instante gerado ou observado, zero para um banco novo).
+func NewClock(node string, last Timestamp) *Clock {
+	last.Node = node
+	return &Clock{last: last}
+}
+
+// Last retorna o último instante gerado ou observado.
+func (c *Clock) Last() Timestamp {
+	return c.last
+}
+
+// Tick gera o instante de um evento local ocorrido em pt.
+func (c *Clock) Tick(pt time.Time) Timestamp {
+	if wall := pt.UnixNano(); wall > c.last.Wall {
+		c.last.Wall, c.last.Logical = wall, 0
+	} else {
+		c.last.Logical++
+	}
+	return c.last
+}
+
+// Observe avança o relógio para depois de um instante recebido de outro
+// banco, para que os próximos eventos locais venham depois dele.
+func (c *Clock) Observe(remote Timestamp) {
+	if remote.Wall > c.last.Wall || remote.Wall == c.last.Wall && remote.Logical > c.last.Logical {
+		c.last.Wall, c.last.Logical = remote.Wall, remote.Logical
+	}
+}
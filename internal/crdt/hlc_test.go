@@ -0,0 +1,57 @@
+package crdt
+
+import (
+	"testing"
+	"time"
+)
+
+// TestClockMonotonic tests that the clock never goes back, even when the physical time does
+func TestClockMonotonic(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := NewClock("a", Timestamp{})
+
+	t1 := c.Tick(base)
+	t2 := c.Tick(base.Add(-time.Hour))
+	if !t1.Less(t2) || t2.Wall != t1.Wall || t2.Logical != 1 {
+		t.Errorf("Tick after clock went back = %v, want %v with logical 1", t2, t1)
+	}
+
+	// Um instante remoto adiantado faz os próximos eventos virem depois dele.
+	remote := Timestamp{Wall: base.Add(time.Minute).UnixNano(), Logical: 7, Node: "b"}
+	c.Observe(remote)
+	if t3 := c.Tick(base); !remote.Less(t3) || t3.Node != "a" {
+		t.Errorf("Tick after Observe(%v) = %v, want later and from node a", remote, t3)
+	}
+
+	t4 := c.Tick(base.Add(time.Hour))
+	if t4.Wall != base.Add(time.Hour).UnixNano() || t4.Logical != 0 {
+		t.Errorf("Tick with later physical time = %v, want physical time and logical 0", t4)
+	}
+}
+
+// TestTimestampString tests the encoding round trip and that string order matches Less
+func TestTimestampString(t *testing.T) {
+	stamps := []Timestamp{
+		{Wall: 5, Logical: 0, Node: "b"},
+		{Wall: 5, Logical: 1, Node: "a"},
+		{Wall: 5, Logical: 1, Node: "b"},
+		{Wall: 1 << 40, Logical: 0, Node: "a"},
+	}
+	for i, ts := range stamps {
+		parsed, err := ParseTimestamp(ts.String())
+		if err != nil || parsed != ts {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", ts.String(), parsed, err, ts)
+		}
+		if i > 0 {
+			prev := stamps[i-1]
+			if !prev.Less(ts) || prev.String() >= ts.String() {
+				t.Errorf("%v should sort before %v", prev, ts)
+			}
+		}
+	}
+	for _, bad := range []string{"", "12.34.a", "000000000000000x.00000000.a", "0000000000000001.00000000."} {
+		if _, err := ParseTimestamp(bad); err == nil {
+			t.Errorf("ParseTimestamp(%q) expected error", bad)
+		}
+	}
+}
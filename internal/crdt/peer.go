@@ -0,0 +1,172 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Peer é o outro lado de um push ou pull: um arquivo de banco aberto como
+// Replica, ou um togo sync serve acessado por HTTP.
+type Peer interface {
+	Vector() (Vector, error)
+	Ops(since Vector) ([]Op, error)
+	Receive(ops []Op) (Result, error)
+	Close() error
+}
+
+// Dial abre o peer indicado: uma URL http(s):// de um togo sync serve ou o
+// caminho de um arquivo de banco.
+func Dial(target string) (Peer, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return &httpPeer{base: strings.TrimSuffix(target, "/"), client: &http.Client{Timeout: 30 * time.Second}}, nil
+	}
+	return Open(target)
+}
+
+// Push envia ao peer as operações do banco local que ele ainda não tem.
+func Push(local *Replica, peer Peer) (Result, error) {
+	since, err := peer.Vector()
+	if err != nil {
+		return Result{}, err
+	}
+	ops, err := local.Ops(since)
+	if err != nil {
+		return Result{}, err
+	}
+	return peer.Receive(ops)
+}
+
+// Pull traz do peer as operações que o banco local ainda não tem.
+func Pull(local *Replica, peer Peer) (Result, error) {
+	since, err := local.Vector()
+	if err != nil {
+		return Result{}, err
+	}
+	ops, err := peer.Ops(since)
+	if err != nil {
+		return Result{}, err
+	}
+	return local.Receive(ops)
+}
+
+// Handler expõe a réplica para peers HTTP:
+//
+//	GET  /sync/vector  vetor de versões
+//	POST /sync/ops     operações posteriores ao vetor enviado
+//	POST /sync/receive recebe operações; responde com o Result
+func Handler(r *Replica) http.Handler {
+	// As sessões já são transações; o mutex só evita que requisições
+	// simultâneas disputem o lock de escrita do SQLite.
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sync/vector", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		v, err := r.Vector()
+		respond(w, v, err)
+	})
+	mux.HandleFunc("/sync/ops", func(w http.ResponseWriter, req *http.Request) {
+		var since Vector
+		if !decode(w, req, &since) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		ops, err := r.Ops(since)
+		respond(w, ops, err)
+	})
+	mux.HandleFunc("/sync/receive", func(w http.ResponseWriter, req *http.Request) {
+		var ops []Op
+		if !decode(w, req, &ops) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		result, err := r.Receive(ops)
+		respond(w, result, err)
+	})
+	return mux
+}
+
+func decode(w http.ResponseWriter, req *http.Request, v any) bool {
+	if req.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		http.Error(w, "JSON inválido: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func respond(w http.ResponseWriter, v any, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// httpPeer é um togo sync serve remoto.
+type httpPeer struct {
+	base   string
+	client *http.Client
+}
+
+func (p *httpPeer) Vector() (Vector, error) {
+	var v Vector
+	err := p.call(http.MethodGet, "/sync/vector", nil, &v)
+	return v, err
+}
+
+func (p *httpPeer) Ops(since Vector) ([]Op, error) {
+	var ops []Op
+	err := p.call(http.MethodPost, "/sync/ops", since, &ops)
+	return ops, err
+}
+
+func (p *httpPeer) Receive(ops []Op) (Result, error) {
+	var result Result
+	err := p.call(http.MethodPost, "/sync/receive", ops, &result)
+	return result, err
+}
+
+func (p *httpPeer) Close() error { return nil }
+
+func (p *httpPeer) call(method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, p.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s respondeu %s: %s", p.base, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
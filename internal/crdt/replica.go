@@ -0,0 +1,454 @@
+// Package crdt sincroniza bancos do togo sem conflitos (togo sync push e
+// pull). Cada campo de cada tarefa é um registro last-writer-wins; toda
+// mudança local vira uma operação em um log, datada por um relógio lógico
+// híbrido, e os bancos trocam as operações que o outro ainda não tem.
+// Aplicar as mesmas operações, em qualquer ordem, leva ao mesmo estado.
+package crdt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/schema"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Vector é o vetor de versões de um banco: o instante da última operação
+// que ele tem de cada banco. Como cada banco gera operações em ordem
+// crescente e elas são sempre trocadas em bloco, quem tem a operação de
+// instante t de um banco tem todas as anteriores dele.
+type Vector map[string]Timestamp
+
+// Result resume as operações recebidas por um banco.
+type Result struct {
+	// Ops conta as operações novas para o banco.
+	Ops int `json:"ops"`
+	// Tasks conta as tarefas do banco criadas ou alteradas por elas.
+	Tasks int `json:"tasks"`
+}
+
+// Replica é um banco do togo participando do sync.
+type Replica struct {
+	db          *gorm.DB
+	transaction func(fn func(tx *gorm.DB) error) error
+	close       func() error
+}
+
+// Local retorna o banco global (o aberto pelo togo) como réplica.
+func Local() *Replica {
+	return &Replica{db: database.DB, transaction: database.Transaction}
+}
+
+// Open abre o banco em path como réplica; feche-o com Close.
+func Open(path string) (*Replica, error) {
+	db, err := database.OpenOther(path)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return &Replica{
+		db:          db,
+		transaction: func(fn func(tx *gorm.DB) error) error { return database.TransactionOn(db, fn) },
+		close:       sqlDB.Close,
+	}, nil
+}
+
+// Close fecha um banco aberto por Open; no banco global, não faz nada.
+func (r *Replica) Close() error {
+	if r.close == nil {
+		return nil
+	}
+	return r.close()
+}
+
+// Vector registra as mudanças locais pendentes e retorna o vetor de
+// versões do banco.
+func (r *Replica) Vector() (Vector, error) {
+	var v Vector
+	err := r.session(func(s *session) error {
+		var err error
+		v, err = s.vector()
+		return err
+	})
+	return v, err
+}
+
+// Ops registra as mudanças locais pendentes e retorna as operações que um
+// banco com o vetor since ainda não tem, em ordem.
+func (r *Replica) Ops(since Vector) ([]Op, error) {
+	var ops []Op
+	err := r.session(func(s *session) error {
+		var err error
+		ops, err = s.opsSince(since)
+		return err
+	})
+	return ops, err
+}
+
+// Receive registra as mudanças locais pendentes, acrescenta ao log as
+// operações recebidas que ainda não estavam nele e aplica às tarefas o
+// estado resultante.
+func (r *Replica) Receive(ops []Op) (Result, error) {
+	var result Result
+	err := r.session(func(s *session) error {
+		result = Result{}
+		for _, op := range ops {
+			if err := op.Validate(); err != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, op.Value); err != nil {
+				return err
+			}
+			op.Value = buf.Bytes()
+			isNew, err := s.record(op)
+			if err != nil {
+				return err
+			}
+			if isNew {
+				s.clock.Observe(op.Time)
+				result.Ops++
+			}
+		}
+		var err error
+		result.Tasks, err = s.apply()
+		return err
+	})
+	return result, err
+}
+
+// session é o estado do sync carregado dentro de uma transação.
+type session struct {
+	tx    *gorm.DB
+	node  schema.SyncNode
+	clock *Clock
+	state State
+	// changed guarda os registros alterados, a gravar no fim da sessão.
+	changed map[[2]string]bool
+	// touched guarda as tarefas cujo estado mudou com operações recebidas.
+	touched map[string]bool
+}
+
+// session roda fn em uma transação, depois de carregar o estado e
+// registrar as mudanças locais pendentes, e grava o estado no fim.
+func (r *Replica) session(fn func(s *session) error) error {
+	return r.transaction(func(tx *gorm.DB) error {
+		s, err := load(tx)
+		if err != nil {
+			return err
+		}
+		if err := s.capture(); err != nil {
+			return err
+		}
+		s.touched = map[string]bool{}
+		if err := fn(s); err != nil {
+			return err
+		}
+		return s.save()
+	})
+}
+
+// load lê a identidade do banco (criando-a no primeiro uso), o relógio e
+// os registros.
+func load(tx *gorm.DB) (*session, error) {
+	s := &session{tx: tx, state: State{}, changed: map[[2]string]bool{}, touched: map[string]bool{}}
+	err := tx.First(&s.node).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.node = schema.SyncNode{Node: uuid.NewString()}
+		err = tx.Create(&s.node).Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler a identidade do banco: %w", err)
+	}
+
+	var last Timestamp
+	if s.node.Clock != "" {
+		if last, err = ParseTimestamp(s.node.Clock); err != nil {
+			return nil, err
+		}
+	}
+	s.clock = NewClock(s.node.Node, last)
+
+	var registers []schema.SyncRegister
+	if err := tx.Find(&registers).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler os registros do sync: %w", err)
+	}
+	for _, reg := range registers {
+		t, err := ParseTimestamp(reg.Time)
+		if err != nil {
+			return nil, err
+		}
+		s.state.Apply(Op{Time: t, Task: reg.TaskUUID, Field: reg.Field, Value: json.RawMessage(reg.Value)})
+	}
+	return s, nil
+}
+
+// capture compara as tarefas com os registros e transforma cada campo
+// diferente em uma operação local, datada pelo updated_at da tarefa. Uma
+// tarefa que sumiu do banco (apagada de vez) vira uma operação em
+// deleted_at.
+func (s *session) capture() error {
+	var tasks []schema.Task
+	if err := s.tx.Unscoped().Order("id asc").Find(&tasks).Error; err != nil {
+		return fmt.Errorf("erro ao ler as tarefas: %w", err)
+	}
+
+	var ops []Op
+	present := map[string]bool{}
+	for _, t := range tasks {
+		if t.UUID == "" {
+			continue
+		}
+		present[t.UUID] = true
+		current, err := taskFields(t)
+		if err != nil {
+			return fmt.Errorf("erro ao converter a tarefa %d: %w", t.ID, err)
+		}
+		registered := s.state.Fields(t.UUID)
+		for _, name := range fieldNames(current, registered) {
+			v := current[name]
+			if bytes.Equal(v, registered[name]) {
+				continue
+			}
+			if v == nil {
+				v = json.RawMessage("null")
+			}
+			ops = append(ops, Op{Time: s.clock.Tick(t.UpdatedAt), Task: t.UUID, Field: name, Value: v})
+		}
+	}
+
+	var gone []string
+	for id := range s.state {
+		if !present[id] && s.state.Fields(id)["deleted_at"] == nil {
+			gone = append(gone, id)
+		}
+	}
+	sort.Strings(gone)
+	for _, id := range gone {
+		now := time.Now()
+		value, _ := json.Marshal(now.UTC())
+		ops = append(ops, Op{Time: s.clock.Tick(now), Task: id, Field: "deleted_at", Value: value})
+	}
+
+	for _, op := range ops {
+		if _, err := s.record(op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record acrescenta a operação ao log e a aplica aos registros. Retorna
+// false se ela já estava no log.
+func (s *session) record(op Op) (bool, error) {
+	row := schema.SyncOp{Time: op.Time.String(), Node: op.Time.Node, TaskUUID: op.Task, Field: op.Field, Value: string(op.Value)}
+	result := s.tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		return false, fmt.Errorf("erro ao gravar a operação %s: %w", op.Time, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	if s.state.Apply(op) {
+		s.changed[[2]string{op.Task, op.Field}] = true
+		s.touched[op.Task] = true
+	}
+	return true, nil
+}
+
+// apply grava nas tarefas o estado das que mudaram com operações recebidas
+// e retorna quantas foram criadas ou alteradas.
+func (s *session) apply() (int, error) {
+	ids := make([]string, 0, len(s.touched))
+	for id := range s.touched {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	count := 0
+	for _, id := range ids {
+		fields := s.state.Fields(id)
+		var existing schema.Task
+		found := s.tx.Unscoped().Where("uuid = ?", id).Limit(1).Find(&existing)
+		if found.Error != nil {
+			return count, fmt.Errorf("erro ao ler a tarefa %s: %w", id, found.Error)
+		}
+		if found.RowsAffected > 0 {
+			current, err := taskFields(existing)
+			if err != nil {
+				return count, err
+			}
+			if equalFields(current, fields) {
+				continue
+			}
+		}
+
+		t, err := taskFromFields(id, fields, s.state.Updated(id).Time())
+		if err != nil {
+			return count, fmt.Errorf("estado inválido da tarefa %s: %w", id, err)
+		}
+		if found.RowsAffected > 0 {
+			t.ID = existing.ID
+			err = s.tx.Unscoped().Model(&schema.Task{}).Where("id = ?", t.ID).Select("*").Omit("OwnerID").UpdateColumns(&t).Error
+		} else {
+			err = s.tx.Create(&t).Error
+		}
+		if err != nil {
+			return count, fmt.Errorf("erro ao gravar a tarefa %s: %w", id, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// save grava os registros alterados e o relógio.
+func (s *session) save() error {
+	keys := make([][2]string, 0, len(s.changed))
+	for k := range s.changed {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		reg := s.state[k[0]][k[1]]
+		row := schema.SyncRegister{TaskUUID: k[0], Field: k[1], Time: reg.Time.String(), Value: string(reg.Value)}
+		if err := s.tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error; err != nil {
+			return fmt.Errorf("erro ao gravar os registros do sync: %w", err)
+		}
+	}
+	s.node.Clock = s.clock.Last().String()
+	if err := s.tx.Save(&s.node).Error; err != nil {
+		return fmt.Errorf("erro ao gravar o relógio do sync: %w", err)
+	}
+	return nil
+}
+
+// vector calcula o vetor de versões a partir do log.
+func (s *session) vector() (Vector, error) {
+	var rows []struct {
+		Node string
+		Time string
+	}
+	if err := s.tx.Model(&schema.SyncOp{}).Select("node, MAX(time) AS time").Group("node").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler o log do sync: %w", err)
+	}
+	v := make(Vector, len(rows))
+	for _, row := range rows {
+		t, err := ParseTimestamp(row.Time)
+		if err != nil {
+			return nil, err
+		}
+		v[row.Node] = t
+	}
+	return v, nil
+}
+
+// opsSince retorna as operações do log posteriores ao vetor since.
+func (s *session) opsSince(since Vector) ([]Op, error) {
+	mine, err := s.vector()
+	if err != nil {
+		return nil, err
+	}
+	var rows []schema.SyncOp
+	for node, last := range mine {
+		known, ok := since[node]
+		if ok && !known.Less(last) {
+			continue
+		}
+		query := s.tx.Where("node = ?", node)
+		if ok {
+			query = query.Where("time > ?", known.String())
+		}
+		var nodeRows []schema.SyncOp
+		if err := query.Find(&nodeRows).Error; err != nil {
+			return nil, fmt.Errorf("erro ao ler o log do sync: %w", err)
+		}
+		rows = append(rows, nodeRows...)
+	}
+
+	ops := make([]Op, 0, len(rows))
+	for _, row := range rows {
+		t, err := ParseTimestamp(row.Time)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, Op{Time: t, Task: row.TaskUUID, Field: row.Field, Value: json.RawMessage(row.Value)})
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Time.Less(ops[j].Time) })
+	return ops, nil
+}
+
+// taskFields retorna os campos sincronizados da tarefa, em JSON compacto e
+// com as datas em UTC, sem os nulos.
+func taskFields(t schema.Task) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(formats.NewRecord(t).UTC())
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	for name, v := range all {
+		if syncedFields[name] && !isNull(v) {
+			fields[name] = v
+		}
+	}
+	return fields, nil
+}
+
+// taskFromFields monta a tarefa a partir dos valores dos registros.
+func taskFromFields(id string, fields map[string]json.RawMessage, updated time.Time) (schema.Task, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return schema.Task{}, err
+	}
+	var r formats.Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return schema.Task{}, err
+	}
+	r.ID = 0
+	r.UUID = id
+	r.UpdatedAt = updated
+	return r.Task(), nil
+}
+
+func equalFields(a, b map[string]json.RawMessage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, v := range a {
+		if !bytes.Equal(v, b[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldNames retorna os nomes dos campos de a e b, em ordem.
+func fieldNames(a, b map[string]json.RawMessage) []string {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,224 @@
+package crdt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"levyvix/togo/schema"
+)
+
+// openReplica creates an empty database file and opens it as a replica
+func openReplica(t *testing.T, name string) *Replica {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name+".db")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open(%q) unexpected error: %v", path, err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func mustExchange(t *testing.T, f func(*Replica, Peer) (Result, error), local *Replica, peer Peer) Result {
+	t.Helper()
+	result, err := f(local, peer)
+	if err != nil {
+		t.Fatalf("exchange unexpected error: %v", err)
+	}
+	return result
+}
+
+func findTask(t *testing.T, r *Replica, id string) schema.Task {
+	t.Helper()
+	var task schema.Task
+	if err := r.db.Unscoped().Where("uuid = ?", id).First(&task).Error; err != nil {
+		t.Fatalf("task %s not found: %v", id, err)
+	}
+	return task
+}
+
+// visible returns the fields of the tasks that are not deleted, by UUID
+func visible(t *testing.T, r *Replica) map[string]map[string]json.RawMessage {
+	t.Helper()
+	var tasks []schema.Task
+	if err := r.db.Find(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]map[string]json.RawMessage{}
+	for _, task := range tasks {
+		f, err := taskFields(task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[task.UUID] = f
+	}
+	return out
+}
+
+// TestPushPullMergesFields tests that offline edits to different fields of a task are all kept
+func TestPushPullMergesFields(t *testing.T) {
+	a := openReplica(t, "a")
+	b := openReplica(t, "b")
+
+	// O dono é local a cada banco e não é sincronizado.
+	owner := uint(7)
+	task := schema.Task{Description: "Comprar pão", OwnerID: &owner}
+	a.db.Create(&task)
+	if r := mustExchange(t, Push, a, b); r.Ops == 0 || r.Tasks != 1 {
+		t.Fatalf("first push = %+v, want ops and 1 task", r)
+	}
+	if r := mustExchange(t, Push, a, b); r.Ops != 0 || r.Tasks != 0 {
+		t.Errorf("repeated push = %+v, want nothing new", r)
+	}
+
+	// A muda a descrição e B a prioridade; depois os dois mudam o prazo,
+	// B por último.
+	taskA := findTask(t, a, task.UUID)
+	a.db.Model(&taskA).Update("description", "Comprar pão integral")
+	taskB := findTask(t, b, task.UUID)
+	b.db.Model(&taskB).Update("priority", "A")
+	a.db.Model(&taskA).Update("recurrence", "FREQ=DAILY")
+	b.db.Model(&taskB).Update("recurrence", "FREQ=WEEKLY")
+
+	mustExchange(t, Pull, a, b)
+	mustExchange(t, Push, a, b)
+
+	for name, r := range map[string]*Replica{"a": a, "b": b} {
+		got := findTask(t, r, task.UUID)
+		if got.Description != "Comprar pão integral" || got.Priority != "A" || got.Recurrence != "FREQ=WEEKLY" {
+			t.Errorf("%s: task = %q, priority %q, recurrence %q; want all edits merged", name, got.Description, got.Priority, got.Recurrence)
+		}
+	}
+	if r := mustExchange(t, Pull, b, a); r.Ops != 0 {
+		t.Errorf("pull after convergence = %+v, want nothing new", r)
+	}
+	if got := findTask(t, a, task.UUID).OwnerID; got == nil || *got != owner {
+		t.Errorf("owner on a = %v, want %d kept", got, owner)
+	}
+}
+
+// TestHardDeleteSyncs tests that a task removed from the database reaches the peer as deleted
+func TestHardDeleteSyncs(t *testing.T) {
+	a := openReplica(t, "a")
+	b := openReplica(t, "b")
+	task := schema.Task{Description: "Temporária"}
+	a.db.Create(&task)
+	mustExchange(t, Push, a, b)
+
+	a.db.Unscoped().Delete(&schema.Task{}, task.ID)
+	mustExchange(t, Push, a, b)
+	if got := findTask(t, b, task.UUID); !got.DeletedAt.Valid {
+		t.Errorf("task removed on a should be deleted on b")
+	}
+	var count int64
+	a.db.Unscoped().Model(&schema.Task{}).Count(&count)
+	if count != 0 {
+		t.Errorf("a has %d tasks, want the removed task to stay removed", count)
+	}
+}
+
+// TestHTTPPeer tests push and pull against a served replica
+func TestHTTPPeer(t *testing.T) {
+	server := openReplica(t, "server")
+	srv := httptest.NewServer(Handler(server))
+	defer srv.Close()
+	peer, err := Dial(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := openReplica(t, "a")
+	b := openReplica(t, "b")
+	a.db.Create(&schema.Task{Description: "De A"})
+	mustExchange(t, Push, a, peer)
+	b.db.Create(&schema.Task{Description: "De B"})
+	mustExchange(t, Push, b, peer)
+	if r := mustExchange(t, Pull, a, peer); r.Tasks != 1 {
+		t.Errorf("pull on a = %+v, want 1 task", r)
+	}
+	if got := visible(t, a); len(got) != 2 || !reflect.DeepEqual(got, visible(t, server)) {
+		t.Errorf("a = %v, want the 2 tasks of the server", got)
+	}
+
+	if _, err := peer.Receive([]Op{{Time: Timestamp{Wall: 1, Node: "x"}, Task: "t", Field: "id", Value: json.RawMessage("1")}}); err == nil {
+		t.Errorf("Receive() with unknown field expected error")
+	}
+}
+
+// TestReplicasConverge tests that replicas exchanging random edits in random orders end up equal
+func TestReplicasConverge(t *testing.T) {
+	for seed := int64(0); seed < 8; seed++ {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(seed))
+			replicas := []*Replica{openReplica(t, "a"), openReplica(t, "b"), openReplica(t, "c")}
+
+			for step := 0; step < 40; step++ {
+				r := replicas[rng.Intn(len(replicas))]
+				other := replicas[rng.Intn(len(replicas))]
+				switch n := rng.Intn(10); {
+				case n < 2:
+					r.db.Create(&schema.Task{Description: fmt.Sprintf("tarefa %d", step)})
+				case n < 7:
+					var tasks []schema.Task
+					r.db.Order("id asc").Find(&tasks)
+					if len(tasks) == 0 {
+						continue
+					}
+					task := tasks[rng.Intn(len(tasks))]
+					switch rng.Intn(5) {
+					case 0:
+						r.db.Model(&task).Update("description", fmt.Sprintf("editada %d", step))
+					case 1:
+						r.db.Model(&task).Update("priority", string(rune('A'+rng.Intn(3))))
+					case 2:
+						r.db.Model(&task).Update("done", rng.Intn(2) == 0)
+					case 3:
+						r.db.Delete(&task)
+					case 4:
+						r.db.Unscoped().Delete(&task)
+					}
+				case n < 9:
+					if other != r {
+						mustExchange(t, Push, r, other)
+					}
+				default:
+					if other != r {
+						mustExchange(t, Pull, r, other)
+					}
+				}
+			}
+
+			for round := 0; round < 2; round++ {
+				for _, r := range replicas {
+					for _, other := range replicas {
+						if r != other {
+							mustExchange(t, Pull, r, other)
+						}
+					}
+				}
+			}
+
+			want := visible(t, replicas[0])
+			for i, r := range replicas[1:] {
+				if got := visible(t, r); !reflect.DeepEqual(got, want) {
+					t.Errorf("replica %d tasks = %v, want %v", i+1, got, want)
+				}
+				var ops, wantOps int64
+				r.db.Model(&schema.SyncOp{}).Count(&ops)
+				replicas[0].db.Model(&schema.SyncOp{}).Count(&wantOps)
+				if ops != wantOps {
+					t.Errorf("replica %d has %d operations, want %d", i+1, ops, wantOps)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package crdt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Op é uma operação do log: a atribuição de Value (em JSON; null apaga o
+// campo) ao campo Field da tarefa de UUID Task, no instante Time.
+type Op struct {
+	Time  Timestamp       `json:"time"`
+	Task  string          `json:"task"`
+	Field string          `json:"field"`
+	Value json.RawMessage `json:"value"`
+}
+
+// syncedFields são os campos de formats.Record que viram registros. O id é
+// local a cada banco, o uuid identifica a tarefa e o updated_at é derivado
+// das operações.
+var syncedFields = map[string]bool{
+	"created_at":  true,
+	"deleted_at":  true,
+	"description": true,
+	"done":        true,
+	"done_at":     true,
+	"priority":    true,
+	"projects":    true,
+	"contexts":    true,
+	"extras":      true,
+	"due":         true,
+	"scheduled":   true,
+	"recurrence":  true,
+	"tags":        true,
+	"annotations": true,
+	"fingerprint": true,
+}
+
+// Validate verifica uma operação recebida de outro banco.
+func (op Op) Validate() error {
+	switch {
+	case op.Task == "":
+		return fmt.Errorf("operação %s sem tarefa", op.Time)
+	case op.Time.Node == "":
+		return fmt.Errorf("operação da tarefa %s sem instante", op.Task)
+	case !syncedFields[op.Field]:
+		return fmt.Errorf("operação %s: campo desconhecido '%s'", op.Time, op.Field)
+	case !json.Valid(op.Value):
+		return fmt.Errorf("operação %s: valor inválido para %s", op.Time, op.Field)
+	}
+	return nil
+}
+
+// Register é um registro last-writer-wins: o valor de um campo e o instante
+// da operação que o definiu.
+type Register struct {
+	Time  Timestamp
+	Value json.RawMessage
+}
+
+// State é o estado combinado das tarefas: os registros de cada campo, por
+// UUID da tarefa e nome do campo.
+//
+// Aplicar uma operação mantém, em cada registro, a de maior instante. Como
+// isso não depende da ordem nem de quantas vezes cada operação é aplicada,
+// bancos que receberam o mesmo conjunto de operações chegam ao mesmo estado.
+type State map[string]map[string]Register
+
+// Apply aplica a operação e informa se o estado mudou.
+func (s State) Apply(op Op) bool {
+	task := s[op.Task]
+	if task == nil {
+		task = map[string]Register{}
+		s[op.Task] = task
+	}
+	if current, ok := task[op.Field]; ok && !current.Time.Less(op.Time) {
+		return false
+	}
+	task[op.Field] = Register{Time: op.Time, Value: op.Value}
+	return true
+}
+
+// Fields retorna os valores atuais dos campos da tarefa, sem os nulos.
+func (s State) Fields(task string) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	for name, r := range s[task] {
+		if !isNull(r.Value) {
+			fields[name] = r.Value
+		}
+	}
+	return fields
+}
+
+// Updated retorna o instante da operação mais recente da tarefa.
+func (s State) Updated(task string) Timestamp {
+	var last Timestamp
+	for _, r := range s[task] {
+		if last.Less(r.Time) {
+			last = r.Time
+		}
+	}
+	return last
+}
+
+func isNull(v json.RawMessage) bool {
+	return len(v) == 0 || bytes.Equal(v, []byte("null"))
+}
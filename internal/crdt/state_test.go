@@ -0,0 +1,86 @@
+package crdt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestStateConvergence tests that applying the same operations in random orders, with duplicates, yields the same state
+func TestStateConvergence(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+	tasks := []string{"t1", "t2", "t3"}
+	fields := []string{"description", "priority", "done", "deleted_at"}
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for seed := int64(0); seed < 200; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		clocks := map[string]*Clock{}
+		for _, n := range nodes {
+			clocks[n] = NewClock(n, Timestamp{})
+		}
+
+		// Relógios com desvios diferentes e eventos com o mesmo tempo físico.
+		var ops []Op
+		for i := 0; i < 60; i++ {
+			node := nodes[rng.Intn(len(nodes))]
+			pt := base.Add(time.Duration(rng.Intn(20)) * time.Second)
+			value, _ := json.Marshal(fmt.Sprintf("%s-%d", node, i))
+			ops = append(ops, Op{
+				Time:  clocks[node].Tick(pt),
+				Task:  tasks[rng.Intn(len(tasks))],
+				Field: fields[rng.Intn(len(fields))],
+				Value: value,
+			})
+			if rng.Intn(4) == 0 {
+				other := nodes[rng.Intn(len(nodes))]
+				clocks[other].Observe(ops[len(ops)-1].Time)
+			}
+		}
+
+		var want State
+		for replica := 0; replica < 4; replica++ {
+			order := append([]Op(nil), ops...)
+			order = append(order, ops[:rng.Intn(len(ops))]...)
+			rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+			got := State{}
+			for _, op := range order {
+				got.Apply(op)
+			}
+			if want == nil {
+				want = got
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("seed %d: replica %d diverged:\n got %v\nwant %v", seed, replica, got, want)
+			}
+		}
+	}
+}
+
+// TestStateApply tests the last-writer-wins rule of a register
+func TestStateApply(t *testing.T) {
+	s := State{}
+	older := Op{Time: Timestamp{Wall: 1, Node: "b"}, Task: "t", Field: "priority", Value: json.RawMessage(`"B"`)}
+	newer := Op{Time: Timestamp{Wall: 2, Node: "a"}, Task: "t", Field: "priority", Value: json.RawMessage(`"A"`)}
+	if !s.Apply(newer) {
+		t.Errorf("Apply(newer) on empty state = false, want true")
+	}
+	if s.Apply(older) {
+		t.Errorf("Apply(older) = true, want false")
+	}
+	if s.Apply(newer) {
+		t.Errorf("Apply(newer) twice = true, want false")
+	}
+	if got := string(s.Fields("t")["priority"]); got != `"A"` {
+		t.Errorf("priority = %s, want \"A\"", got)
+	}
+
+	s.Apply(Op{Time: Timestamp{Wall: 3, Node: "b"}, Task: "t", Field: "priority", Value: json.RawMessage("null")})
+	if _, ok := s.Fields("t")["priority"]; ok {
+		t.Errorf("priority set to null should be absent from Fields")
+	}
+}
@@ -0,0 +1,321 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SnapshotEnv é a variável de ambiente que ativa os snapshots automáticos.
+// Seu valor é a quantidade de snapshots mantidos; 0 ou vazio desativa.
+const SnapshotEnv = "TOGO_SNAPSHOTS"
+
+const backupTimeFormat = "20060102-150405.000000"
+
+// DefaultBackupPath retorna um caminho com timestamp ao lado do banco atual,
+// em <dir>/backups/<nome>-<timestamp>.db.
+func DefaultBackupPath() string {
+	return filepath.Join(filepath.Dir(Path), "backups", backupName(time.Now(), ""))
+}
+
+// Backup copia o banco aberto para dest usando a API de backup online do
+// SQLite, que produz uma cópia consistente mesmo com outro processo escrevendo.
+// O arquivo é escrito em um temporário e renomeado ao final. Se o banco for
+// criptografado, o backup também é.
+func Backup(dest string) error {
+	if DB == nil {
+		return fmt.Errorf("database is not open")
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("file %s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dest), err)
+	}
+
+	// Um banco criptografado só é copiado já criptografado.
+	if IsEncrypted() {
+		data, err := serialize(DB)
+		if err != nil {
+			return err
+		}
+		_, err = writeEncrypted(dest, enc, data)
+		return err
+	}
+
+	tmp := dest + ".tmp"
+	_ = os.Remove(tmp)
+	if err := copyDatabase(DB, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to move backup to %s: %w", dest, err)
+	}
+	return nil
+}
+
+// Restore substitui o conteúdo do banco aberto pelo backup em src. O arquivo
+// é validado antes (precisa ser um banco togo com versão de esquema
+// suportada) e um snapshot automático do estado atual é criado se ativado.
+func Restore(src string) error {
+	if DB == nil {
+		return fmt.Errorf("database is not open")
+	}
+	if err := ValidateBackup(src); err != nil {
+		return err
+	}
+	if _, err := Snapshot("restore"); err != nil {
+		return err
+	}
+
+	backup, err := openBackup(src)
+	if err != nil {
+		return err
+	}
+	srcDB, err := backup.DB()
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+
+	liveDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to access database: %w", err)
+	}
+	restore := func() error {
+		if err := runBackup(liveDB, srcDB); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", src, err)
+		}
+		return migrate(DB)
+	}
+	if IsEncrypted() {
+		return update(restore)
+	}
+	return restore()
+}
+
+// ValidateBackup verifica se path é um banco SQLite do togo que esta versão
+// consegue abrir.
+func ValidateBackup(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+
+	db, err := openBackup(path)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to open backup %s: %w", path, err)
+	}
+	defer sqlDB.Close()
+
+	version, err := schemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid SQLite database: %w", path, err)
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("backup has schema version %d, newer than supported version %d", version, SchemaVersion)
+	}
+	if !db.Migrator().HasTable("tasks") {
+		return fmt.Errorf("%s is not a togo database (table tasks not found)", path)
+	}
+	return nil
+}
+
+// openBackup abre um backup somente para leitura. Backups criptografados são
+// decifrados e carregados em memória.
+func openBackup(path string) (*gorm.DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	header := make([]byte, len(encMagic))
+	n, _ := f.Read(header)
+	f.Close()
+
+	if isEncrypted(header[:n]) {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		data, err := decryptFile(raw)
+		if err != nil {
+			return nil, err
+		}
+		return loadMemory(data)
+	}
+
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Snapshot cria um backup automático antes de uma operação destrutiva e
+// mantém apenas os N mais recentes, onde N vem de TOGO_SNAPSHOTS. Retorna o
+// caminho do snapshot, ou "" se os snapshots estiverem desativados.
+func Snapshot(reason string) (string, error) {
+	keep, err := snapshotRetention()
+	if err != nil {
+		return "", err
+	}
+	if keep <= 0 || Path == "" {
+		return "", nil
+	}
+
+	dir := snapshotDir()
+	dest := filepath.Join(dir, backupName(time.Now(), reason))
+	if err := Backup(dest); err != nil {
+		return "", fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	if err := pruneSnapshots(dir, keep); err != nil {
+		return dest, err
+	}
+	return dest, nil
+}
+
+func snapshotDir() string {
+	return filepath.Join(filepath.Dir(Path), "snapshots")
+}
+
+func snapshotRetention() (int, error) {
+	value := strings.TrimSpace(os.Getenv(SnapshotEnv))
+	if value == "" {
+		return 0, nil
+	}
+	keep, err := strconv.Atoi(value)
+	if err != nil || keep < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", SnapshotEnv, value)
+	}
+	return keep, nil
+}
+
+// backupName monta "<nome do banco>-<timestamp>[-<motivo>].db"; o timestamp
+// vem primeiro para que a ordem alfabética seja a cronológica.
+func backupName(t time.Time, reason string) string {
+	base := strings.TrimSuffix(filepath.Base(Path), filepath.Ext(Path))
+	name := base + "-" + t.Format(backupTimeFormat)
+	if reason != "" {
+		name += "-" + reason
+	}
+	return name + ".db"
+}
+
+func listSnapshots(dir string) ([]string, error) {
+	prefix := strings.TrimSuffix(filepath.Base(Path), filepath.Ext(Path)) + "-"
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) || filepath.Ext(e.Name()) != ".db" {
+			continue
+		}
+		files = append(files, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func pruneSnapshots(dir string, keep int) error {
+	files, err := listSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	for len(files) > keep {
+		if err := os.Remove(files[0]); err != nil {
+			return fmt.Errorf("failed to remove old snapshot %s: %w", files[0], err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// copyDatabase copia o banco de src para um novo arquivo em dest.
+func copyDatabase(src *gorm.DB, dest string) error {
+	srcDB, err := src.DB()
+	if err != nil {
+		return fmt.Errorf("failed to access database: %w", err)
+	}
+	destDB, err := sql.Open(sqlite.DriverName, dest)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	defer destDB.Close()
+
+	if err := runBackup(destDB, srcDB); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// runBackup executa sqlite3_backup de src para dest, esperando enquanto o
+// banco de origem estiver ocupado.
+func runBackup(dest, src *sql.DB) error {
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			d, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", destDriver)
+			}
+			s, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", srcDriver)
+			}
+
+			b, err := d.Backup("main", s, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := b.Step(-1)
+				if err != nil {
+					_ = b.Close()
+					return err
+				}
+				if done {
+					break
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+			return b.Finish()
+		})
+	})
+}
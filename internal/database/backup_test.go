@@ -0,0 +1,128 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"levyvix/togo/schema"
+)
+
+// openTestDB opens a fresh database file inside a temporary directory
+func openTestDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	openTestDBAt(t, path)
+	return path
+}
+
+// openTestDBAt opens the database file at path
+func openTestDBAt(t *testing.T, path string) {
+	t.Helper()
+	if err := Open(path); err != nil {
+		t.Fatalf("Open(%q) unexpected error: %v", path, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+}
+
+func countTasks(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	if err := DB.Model(&schema.Task{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count tasks: %v", err)
+	}
+	return count
+}
+
+// TestBackupRestore tests that a backup can be restored over a modified database
+func TestBackupRestore(t *testing.T) {
+	path := openTestDB(t)
+	DB.Create(&schema.Task{Description: "Estudar Go"})
+	DB.Create(&schema.Task{Description: "Fazer compras"})
+
+	dest := filepath.Join(filepath.Dir(path), "backup.db")
+	if err := Backup(dest); err != nil {
+		t.Fatalf("Backup(%q) unexpected error: %v", dest, err)
+	}
+	if err := Backup(dest); err == nil {
+		t.Errorf("Backup(%q) over an existing file expected error, got nil", dest)
+	}
+
+	DB.Where("1 = 1").Delete(&schema.Task{})
+	DB.Create(&schema.Task{Description: "Depois do backup"})
+
+	if err := Restore(dest); err != nil {
+		t.Fatalf("Restore(%q) unexpected error: %v", dest, err)
+	}
+	if got := countTasks(t); got != 2 {
+		t.Errorf("after Restore task count = %d, want 2", got)
+	}
+	var task schema.Task
+	DB.First(&task)
+	if task.Description != "Estudar Go" {
+		t.Errorf("after Restore first task = %q, want %q", task.Description, "Estudar Go")
+	}
+}
+
+// TestValidateBackup tests rejection of files that are not togo databases
+func TestValidateBackup(t *testing.T) {
+	openTestDB(t)
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("isto não é um banco"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateBackup(garbage); err == nil {
+		t.Errorf("ValidateBackup(garbage) expected error, got nil")
+	}
+
+	if err := ValidateBackup(filepath.Join(dir, "missing.db")); err == nil {
+		t.Errorf("ValidateBackup(missing) expected error, got nil")
+	}
+
+	newer := filepath.Join(dir, "newer.db")
+	if err := Backup(newer); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(newer); err != nil {
+		t.Fatal(err)
+	}
+	DB.Exec("PRAGMA user_version = 999")
+	if err := ValidateBackup(newer); err == nil {
+		t.Errorf("ValidateBackup(newer schema) expected error, got nil")
+	}
+}
+
+// TestSnapshotRetention tests that only the last N snapshots are kept
+func TestSnapshotRetention(t *testing.T) {
+	openTestDB(t)
+
+	t.Setenv(SnapshotEnv, "")
+	if path, err := Snapshot("clear"); err != nil || path != "" {
+		t.Errorf("Snapshot with snapshots disabled = (%q, %v), want no snapshot", path, err)
+	}
+
+	t.Setenv(SnapshotEnv, "2")
+	for i := 0; i < 4; i++ {
+		if _, err := Snapshot("clear"); err != nil {
+			t.Fatalf("Snapshot unexpected error: %v", err)
+		}
+	}
+	files, err := listSnapshots(snapshotDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("snapshots kept = %d, want 2", len(files))
+	}
+
+	t.Setenv(SnapshotEnv, "abc")
+	if _, err := Snapshot("clear"); err == nil {
+		t.Errorf("Snapshot with invalid %s expected error, got nil", SnapshotEnv)
+	}
+}
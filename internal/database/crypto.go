@@ -0,0 +1,584 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/config"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/term"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// PassphraseEnv contém a senha do banco criptografado.
+	PassphraseEnv = "TOGO_PASSPHRASE"
+
+	// KeyFileEnv aponta para um arquivo (permissão 0600) cuja primeira
+	// linha é a senha. Sem a variável, ~/.togo/keyfile é usado se existir.
+	KeyFileEnv = "TOGO_KEYFILE"
+
+	// EncryptedExt é a extensão do arquivo criptografado, ao lado de Path.
+	EncryptedExt = ".enc"
+
+	encMagic      = "TOGOENC1"
+	saltSize      = 16
+	kdfIterations = 600_000
+)
+
+// ErrWrongKey indica que a senha não abre o arquivo criptografado.
+var ErrWrongKey = errors.New("wrong passphrase or corrupted encrypted database")
+
+// encryption guarda a chave derivada enquanto um banco criptografado está
+// aberto. O salt é mantido para que a chave não precise ser derivada de novo
+// a cada gravação; cada gravação usa um nonce novo.
+//
+// sum é o hash do arquivo lido ou gravado por último por este processo, e
+// changes o total_changes() do SQLite na última gravação: juntos dizem se
+// outro processo gravou o arquivo e se há alterações ainda não gravadas.
+type encryption struct {
+	salt    []byte
+	key     []byte
+	sum     [sha256.Size]byte
+	changes int64
+}
+
+var enc *encryption
+
+// IsEncrypted informa se o banco aberto é criptografado.
+func IsEncrypted() bool {
+	return enc != nil
+}
+
+// EncryptedPath retorna o caminho do arquivo criptografado do banco atual.
+func EncryptedPath() string {
+	return Path + EncryptedExt
+}
+
+// diskPath retorna o arquivo que realmente guarda os dados em disco.
+func diskPath() string {
+	if IsEncrypted() {
+		return EncryptedPath()
+	}
+	return Path
+}
+
+// Encrypt converte o banco aberto (em texto puro) para o formato
+// criptografado com a senha informada e remove o arquivo original.
+func Encrypt(passphrase []byte) error {
+	if IsEncrypted() {
+		return fmt.Errorf("database is already encrypted")
+	}
+	if len(passphrase) == 0 {
+		return fmt.Errorf("passphrase cannot be empty")
+	}
+
+	data, err := serialize(DB)
+	if err != nil {
+		return err
+	}
+	e, err := newEncryption(passphrase, nil)
+	if err != nil {
+		return err
+	}
+	path := Path
+	sealed, err := writeEncrypted(path+EncryptedExt, e, data)
+	if err != nil {
+		return err
+	}
+	e.sum = sha256.Sum256(sealed)
+
+	closeDB()
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove plain database %s: %w", path+suffix, err)
+		}
+	}
+	return openMemory(path, e, data)
+}
+
+// Decrypt grava o banco criptografado aberto de volta em texto puro e remove
+// o arquivo criptografado.
+func Decrypt() error {
+	if !IsEncrypted() {
+		return fmt.Errorf("database is not encrypted")
+	}
+
+	unlock, err := lockFile(lockPath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := reload(); err != nil {
+		return err
+	}
+	data, err := serialize(DB)
+	if err != nil {
+		return err
+	}
+	path := Path
+	if err := WriteFileAtomic(path, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Remove(path + EncryptedExt); err != nil {
+		return fmt.Errorf("failed to remove encrypted database: %w", err)
+	}
+
+	closeDB()
+	return Open(path)
+}
+
+// Close grava as alterações pendentes do banco criptografado (se for o
+// caso) e fecha a conexão.
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	var err error
+	if IsEncrypted() && dirty() {
+		err = update(func() error { return nil })
+	}
+	closeDB()
+	return err
+}
+
+func closeDB() {
+	if DB != nil {
+		if sqlDB, err := DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+	DB = nil
+	enc = nil
+}
+
+func lockPath() string {
+	return EncryptedPath() + ".lock"
+}
+
+// update executa fn no banco criptografado com o arquivo travado contra
+// outros processos do togo. Cada processo tem sua cópia do banco em memória:
+// ela é recarregada antes de fn se outro processo gravou o arquivo, e
+// regravada depois, para que uma gravação não apague a de outro processo.
+func update(fn func() error) error {
+	unlock, err := lockFile(lockPath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := reload(); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return save()
+}
+
+// reload recarrega o banco em memória se o arquivo criptografado mudou
+// desde a última leitura ou gravação deste processo. Alterações feitas fora
+// de update e ainda não gravadas são descartadas.
+func reload() error {
+	raw, err := os.ReadFile(EncryptedPath())
+	if err != nil {
+		return fmt.Errorf("failed to read encrypted database: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	if sum == enc.sum {
+		return nil
+	}
+	data, err := enc.open(raw)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	if err := loadImage(sqlDB, data); err != nil {
+		return err
+	}
+	if err := migrate(DB); err != nil {
+		return err
+	}
+	enc.sum = sum
+	return nil
+}
+
+// save criptografa e grava o conteúdo atual do banco em memória. Deve ser
+// chamado dentro de update.
+func save() error {
+	data, err := serialize(DB)
+	if err != nil {
+		return err
+	}
+	sealed, err := writeEncrypted(EncryptedPath(), enc, data)
+	if err != nil {
+		return err
+	}
+	enc.sum = sha256.Sum256(sealed)
+	enc.changes = totalChanges()
+	return nil
+}
+
+// dirty informa se o banco em memória tem alterações ainda não gravadas.
+func dirty() bool {
+	return totalChanges() != enc.changes
+}
+
+// totalChanges retorna as linhas alteradas desde que a conexão, a única do
+// banco em memória, foi aberta.
+func totalChanges() int64 {
+	var n int64
+	DB.Raw("SELECT total_changes()").Scan(&n)
+	return n
+}
+
+// openEncrypted lê e decifra o arquivo em dbPath+EncryptedExt e carrega o
+// banco na memória.
+func openEncrypted(dbPath string) error {
+	raw, err := os.ReadFile(dbPath + EncryptedExt)
+	if err != nil {
+		return fmt.Errorf("failed to read encrypted database: %w", err)
+	}
+	salt, _, err := parseHeader(raw)
+	if err != nil {
+		return err
+	}
+	passphrase, err := Passphrase(false)
+	if err != nil {
+		return err
+	}
+	e, err := newEncryption(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	data, err := e.open(raw)
+	if err != nil {
+		return err
+	}
+	e.sum = sha256.Sum256(raw)
+	return openMemory(dbPath, e, data)
+}
+
+// openMemory carrega data em um banco SQLite em memória e o torna o banco
+// global. O pool fica restrito a uma conexão, já que cada conexão :memory:
+// é um banco separado.
+func openMemory(dbPath string, e *encryption, data []byte) error {
+	db, err := loadMemory(data)
+	if err != nil {
+		return err
+	}
+	if err := migrate(db); err != nil {
+		return err
+	}
+	DB = db
+	Path = dbPath
+	enc = e
+	return nil
+}
+
+func loadMemory(data []byte) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	if err := loadImage(sqlDB, data); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// loadImage substitui o conteúdo do banco em memória dst pela imagem data.
+func loadImage(dst *sql.DB, data []byte) error {
+	// sqlite3_deserialize cria um banco de tamanho fixo; por isso a imagem é
+	// carregada em uma conexão temporária e copiada com a API de backup.
+	tmp, err := sql.Open(sqlite.DriverName, ":memory:")
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+	tmp.SetMaxOpenConns(1)
+	conn, err := tmp.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		return c.Deserialize(data, "main")
+	})
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to load decrypted database: %w", err)
+	}
+
+	if err := runBackup(dst, tmp); err != nil {
+		return fmt.Errorf("failed to load decrypted database: %w", err)
+	}
+	return nil
+}
+
+// decryptFile decifra um arquivo criptografado do togo (o banco ou um
+// backup). A chave atual é reaproveitada quando o salt coincide; caso
+// contrário a senha é pedida de novo.
+func decryptFile(raw []byte) ([]byte, error) {
+	salt, _, err := parseHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	e := enc
+	if e == nil || !bytes.Equal(e.salt, salt) {
+		passphrase, err := Passphrase(false)
+		if err != nil {
+			return nil, err
+		}
+		if e, err = newEncryption(passphrase, salt); err != nil {
+			return nil, err
+		}
+	}
+	return e.open(raw)
+}
+
+// serialize retorna a imagem do banco como um arquivo SQLite em modo
+// rollback journal, que pode ser gravado em disco ou carregado em memória.
+func serialize(db *gorm.DB) ([]byte, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var data []byte
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		data, err = c.Serialize("main")
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize database: %w", err)
+	}
+	// Bytes 18 e 19 do cabeçalho marcam o modo WAL; uma imagem em WAL não
+	// pode ser aberta a partir da memória.
+	if len(data) > 19 {
+		data[18], data[19] = 1, 1
+	}
+	return data, nil
+}
+
+// Passphrase obtém a senha do banco criptografado, nesta ordem: variável
+// TOGO_PASSPHRASE, arquivo apontado por TOGO_KEYFILE (ou ~/.togo/keyfile) e
+// prompt no terminal. Com confirm, o prompt pede a senha duas vezes.
+func Passphrase(confirm bool) ([]byte, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return []byte(p), nil
+	}
+
+	keyFile := os.Getenv(KeyFileEnv)
+	if keyFile == "" {
+		if dir, err := config.Dir(); err == nil {
+			candidate := filepath.Join(dir, "keyfile")
+			if _, err := os.Stat(candidate); err == nil {
+				keyFile = candidate
+			}
+		}
+	}
+	if keyFile != "" {
+		return readKeyFile(keyFile)
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("database is encrypted: set %s or %s, or run in a terminal", PassphraseEnv, KeyFileEnv)
+	}
+	passphrase, err := prompt("Senha do banco: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		again, err := prompt("Confirme a senha: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase cannot be empty")
+	}
+	return passphrase, nil
+}
+
+func prompt(label string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, label)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("key file %s must not be readable by other users (chmod 600)", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	line = strings.TrimRight(line, "\r")
+	if line == "" {
+		return nil, fmt.Errorf("key file %s is empty", path)
+	}
+	return []byte(line), nil
+}
+
+// newEncryption deriva a chave AES-256 com PBKDF2-SHA256. Sem salt, um novo
+// salt aleatório é gerado.
+func newEncryption(passphrase, salt []byte) (*encryption, error) {
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	}
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, kdfIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return &encryption{salt: salt, key: key}, nil
+}
+
+// seal cifra data no formato: magic | salt | nonce | AES-GCM(data).
+// O cabeçalho entra como dado autenticado.
+func (e *encryption) seal(data []byte) ([]byte, error) {
+	gcm, err := e.gcm()
+	if err != nil {
+		return nil, err
+	}
+	header := append([]byte(encMagic), e.salt...)
+	header = header[:len(header):len(header)]
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(append(header, nonce...), gcm.Seal(nil, nonce, data, header)...)
+	return out, nil
+}
+
+func (e *encryption) open(raw []byte) ([]byte, error) {
+	salt, rest, err := parseHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(salt, e.salt) {
+		return nil, ErrWrongKey
+	}
+	gcm, err := e.gcm()
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted database is truncated")
+	}
+	header := raw[:len(encMagic)+saltSize]
+	data, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], header)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return data, nil
+}
+
+func (e *encryption) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// parseHeader valida o magic e separa o salt do restante do arquivo.
+func parseHeader(raw []byte) (salt, rest []byte, err error) {
+	if !isEncrypted(raw) {
+		return nil, nil, fmt.Errorf("not an encrypted togo database")
+	}
+	if len(raw) < len(encMagic)+saltSize {
+		return nil, nil, fmt.Errorf("encrypted database is truncated")
+	}
+	return raw[len(encMagic) : len(encMagic)+saltSize], raw[len(encMagic)+saltSize:], nil
+}
+
+func isEncrypted(raw []byte) bool {
+	return bytes.HasPrefix(raw, []byte(encMagic))
+}
+
+// writeEncrypted cifra data, grava em path e retorna o conteúdo gravado.
+func writeEncrypted(path string, e *encryption, data []byte) ([]byte, error) {
+	sealed, err := e.seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt database: %w", err)
+	}
+	return sealed, WriteFileAtomic(path, sealed, 0600)
+}
+
+// WriteFileAtomic grava em um temporário e renomeia, para que uma falha no
+// meio da gravação não corrompa o arquivo existente. Cada gravação usa um
+// temporário próprio, já que vários processos podem gravar ao mesmo tempo.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
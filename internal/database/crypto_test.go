@@ -0,0 +1,146 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"levyvix/togo/internal/config"
+	"levyvix/togo/schema"
+
+	"gorm.io/gorm"
+)
+
+// TestEncryptDecrypt tests converting a database to encrypted storage and back
+func TestEncryptDecrypt(t *testing.T) {
+	path := openTestDB(t)
+	DB.Create(&schema.Task{Description: "Ligar para o cliente ACME"})
+
+	if err := Encrypt([]byte("segredo")); err != nil {
+		t.Fatalf("Encrypt() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("plain database %s should be removed after Encrypt", path)
+	}
+	raw, err := os.ReadFile(path + EncryptedExt)
+	if err != nil {
+		t.Fatalf("encrypted file not written: %v", err)
+	}
+	if bytes.Contains(raw, []byte("ACME")) {
+		t.Errorf("encrypted file contains plain text description")
+	}
+
+	// Writes keep going to the encrypted file
+	if err := Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&schema.Task{Description: "Segunda tarefa"}).Error
+	}); err != nil {
+		t.Fatalf("Transaction() on encrypted database unexpected error: %v", err)
+	}
+	if err := Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+
+	t.Setenv(PassphraseEnv, "errada")
+	if err := Open(path); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Open() with wrong passphrase error = %v, want ErrWrongKey", err)
+	}
+
+	t.Setenv(PassphraseEnv, "segredo")
+	if err := Open(path); err != nil {
+		t.Fatalf("Open() encrypted database unexpected error: %v", err)
+	}
+	if !IsEncrypted() {
+		t.Errorf("IsEncrypted() = false after opening encrypted database")
+	}
+	if got := countTasks(t); got != 2 {
+		t.Errorf("encrypted database task count = %d, want 2", got)
+	}
+
+	if err := Decrypt(); err != nil {
+		t.Fatalf("Decrypt() unexpected error: %v", err)
+	}
+	if IsEncrypted() {
+		t.Errorf("IsEncrypted() = true after Decrypt")
+	}
+	if _, err := os.Stat(path + EncryptedExt); !os.IsNotExist(err) {
+		t.Errorf("encrypted file should be removed after Decrypt")
+	}
+	if got := countTasks(t); got != 2 {
+		t.Errorf("decrypted database task count = %d, want 2", got)
+	}
+}
+
+// TestEncryptedBackup tests that backups of an encrypted database stay encrypted
+func TestEncryptedBackup(t *testing.T) {
+	path := openTestDB(t)
+	DB.Create(&schema.Task{Description: "Cliente ACME"})
+	if err := Encrypt([]byte("segredo")); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(filepath.Dir(path), "backup.db")
+	if err := Backup(dest); err != nil {
+		t.Fatalf("Backup() unexpected error: %v", err)
+	}
+	raw, _ := os.ReadFile(dest)
+	if !isEncrypted(raw) {
+		t.Errorf("backup of encrypted database is not encrypted")
+	}
+
+	DB.Where("1 = 1").Delete(&schema.Task{})
+	if err := Restore(dest); err != nil {
+		t.Fatalf("Restore() of encrypted backup unexpected error: %v", err)
+	}
+	if got := countTasks(t); got != 1 {
+		t.Errorf("after Restore task count = %d, want 1", got)
+	}
+}
+
+// TestPassphraseKeyFile tests reading the passphrase from a key file
+func TestPassphraseKeyFile(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	keyFile := filepath.Join(t.TempDir(), "keyfile")
+	t.Setenv(KeyFileEnv, keyFile)
+
+	if err := os.WriteFile(keyFile, []byte("do-arquivo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Passphrase(false)
+	if err != nil {
+		t.Fatalf("Passphrase() unexpected error: %v", err)
+	}
+	if string(got) != "do-arquivo" {
+		t.Errorf("Passphrase() = %q, want %q", got, "do-arquivo")
+	}
+
+	if err := os.Chmod(keyFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Passphrase(false); err == nil {
+		t.Errorf("Passphrase() with world-readable key file expected error, got nil")
+	}
+}
+
+// TestPassphraseDefaultKeyFile tests that the key file in the data directory is used without TOGO_KEYFILE
+func TestPassphraseDefaultKeyFile(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv(KeyFileEnv, "")
+	t.Setenv("HOME", t.TempDir())
+	dir, err := config.Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(dir, 0700)
+	if err := os.WriteFile(filepath.Join(dir, "keyfile"), []byte("padrão\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Passphrase(false)
+	if err != nil {
+		t.Fatalf("Passphrase() unexpected error: %v", err)
+	}
+	if string(got) != "padrão" {
+		t.Errorf("Passphrase() = %q, want %q", got, "padrão")
+	}
+}
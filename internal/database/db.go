@@ -2,34 +2,219 @@ package database
 
 import (
 	"fmt"
+	"levyvix/togo/internal/config"
 	"levyvix/togo/schema"
 	"os"
 	"path/filepath"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// SchemaVersion é a versão do esquema gravada em PRAGMA user_version.
+// Deve ser incrementada sempre que o esquema mudar de forma incompatível.
+//
+// Versão 2: coluna uuid, única, em tasks.
+const SchemaVersion = 2
+
 var DB *gorm.DB
 
-func InitDB() error {
-	homeDir, err := os.UserHomeDir()
+// models lista todos os modelos migrados para o banco.
+var models = []any{&schema.Task{}, &schema.CommitLink{}, &schema.SyncNode{}, &schema.SyncOp{}, &schema.SyncRegister{}, &schema.User{}, &schema.Token{}, &schema.TaskShare{}, &schema.Webhook{}, &schema.WebhookDelivery{}}
+
+// Path é o caminho do arquivo SQLite aberto.
+var Path string
+
+// DefaultPath retorna o caminho padrão do banco: ~/.togo/tasks.db.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tasks.db"), nil
+}
+
+// InitDB abre o banco do comando, resolvido por Resolve: o da lista do
+// diretório atual, o da lista informada ou o da lista definida com
+// 'togo use'.
+func InitDB(list string, global bool) error {
+	dbPath, name, project, err := Resolve(list, global)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
+	}
+	if project != nil {
+		return OpenProject(project)
+	}
+	if err := Open(dbPath); err != nil {
+		return err
 	}
-	dbPath := filepath.Join(homeDir, ".togo", "tasks.db")
+	List = name
+	ProjectRoot = ""
+	return nil
+}
+
+// Open abre (ou cria) o banco em dbPath, aplica as migrações e o torna o
+// banco global usado pela aplicação. Se existir dbPath+".enc", o banco
+// criptografado é decifrado e carregado em memória.
+func Open(dbPath string) error {
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	DB, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if _, err := os.Stat(dbPath + EncryptedExt); err == nil {
+		if _, err := os.Stat(dbPath); err == nil {
+			return fmt.Errorf("both %s and %s exist; remove the one that is out of date", dbPath, dbPath+EncryptedExt)
+		}
+		return openEncrypted(dbPath)
+	}
+
+	db, err := openFile(dbPath)
+	if err != nil {
+		return err
+	}
+	if err := migrate(db); err != nil {
+		return err
+	}
+	DB = db
+	Path = dbPath
+	enc = nil
+	return nil
+}
+
+// OpenRaw abre o banco em dbPath somente para leitura e sem aplicar as
+// migrações, que leem as tarefas e falhariam em um arquivo corrompido. É
+// usado por togo db check e repair. Um banco criptografado é aberto como
+// em Open, já que a decifração detecta arquivos danificados.
+func OpenRaw(dbPath string) error {
+	if _, err := os.Stat(dbPath + EncryptedExt); err == nil {
+		return Open(dbPath)
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("failed to open %s: %w", dbPath, err)
+	}
+	dsn := fmt.Sprintf("file:%s?mode=ro&_busy_timeout=%d", dbPath, busyTimeout.Milliseconds())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
-	if err := DB.AutoMigrate(&schema.Task{}); err != nil {
+	DB = db
+	Path = dbPath
+	enc = nil
+	return nil
+}
+
+// OpenOther abre (e migra) outro banco do togo sem torná-lo o banco
+// global, como o banco de um peer do togo sync push/pull. O arquivo precisa
+// existir, e bancos criptografados não são aceitos.
+func OpenOther(dbPath string) (*gorm.DB, error) {
+	if _, err := os.Stat(dbPath + EncryptedExt); err == nil {
+		return nil, fmt.Errorf("%s is encrypted; decrypt it first", dbPath)
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", dbPath, err)
+	}
+	db, err := openFile(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		return nil, err
+	}
+	return db, nil
+}
+
+// openFile abre o arquivo SQLite em modo WAL, para que leitores não
+// bloqueiem escritores, com busy_timeout para esperar por locks de outros
+// processos em vez de falhar com "database is locked". Transações usam
+// BEGIN IMMEDIATE, pegando o lock de escrita logo no início.
+func openFile(dbPath string) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", dbPath, busyTimeout.Milliseconds())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// migrate cria/atualiza as tabelas e grava a versão do esquema.
+func migrate(db *gorm.DB) error {
+	if err := migrateUUIDs(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(models...); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)).Error; err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
 	return nil
 }
+
+// migrateUUIDs prepara bancos anteriores à coluna uuid: cria a coluna antes
+// do AutoMigrate, que criaria o índice único com todas as linhas vazias, e
+// dá um UUID às tarefas sem um. O extra "uuid" (gravado pelo togo sync e
+// pelas importações de Taskwarrior e iCalendar) é movido para a coluna.
+func migrateUUIDs(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&schema.Task{}) {
+		return nil
+	}
+	if !m.HasColumn(&schema.Task{}, "UUID") {
+		if err := m.AddColumn(&schema.Task{}, "UUID"); err != nil {
+			return fmt.Errorf("failed to add uuid column: %w", err)
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var tasks []schema.Task
+		if err := tx.Unscoped().Where("uuid IS NULL OR uuid = ''").Order("id asc").Find(&tasks).Error; err != nil {
+			return fmt.Errorf("failed to read tasks without uuid: %w", err)
+		}
+		if len(tasks) == 0 {
+			return nil
+		}
+
+		var existing []string
+		if err := tx.Unscoped().Model(&schema.Task{}).Where("uuid <> ''").Pluck("uuid", &existing).Error; err != nil {
+			return fmt.Errorf("failed to read task uuids: %w", err)
+		}
+		used := make(map[string]bool, len(existing))
+		for _, id := range existing {
+			used[id] = true
+		}
+
+		for i := range tasks {
+			t := &tasks[i]
+			id := t.Extras["uuid"]
+			if id != "" && !used[id] {
+				delete(t.Extras, "uuid")
+				if len(t.Extras) == 0 {
+					t.Extras = nil
+				}
+			} else {
+				id = uuid.NewString()
+			}
+			used[id] = true
+			t.UUID = id
+			if err := tx.Unscoped().Model(t).Select("UUID", "Extras").UpdateColumns(t).Error; err != nil {
+				return fmt.Errorf("failed to set uuid of task %d: %w", t.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// schemaVersion lê PRAGMA user_version do banco.
+func schemaVersion(db *gorm.DB) (int, error) {
+	var version int
+	if err := db.Raw("PRAGMA user_version").Scan(&version).Error; err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"levyvix/togo/internal/config"
+	"levyvix/togo/schema"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// legacyTask is the tasks table as it was before the uuid column
+type legacyTask struct {
+	gorm.Model
+	Description string
+	Extras      map[string]string `gorm:"serializer:json"`
+}
+
+func (legacyTask) TableName() string { return "tasks" }
+
+// TestMigrateUUIDs tests that opening an old database fills in task UUIDs
+func TestMigrateUUIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	old, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := old.AutoMigrate(&legacyTask{}); err != nil {
+		t.Fatal(err)
+	}
+	old.Create(&legacyTask{Description: "Sincronizada", Extras: map[string]string{"uuid": "3f0c2a9e-1111-4d6e-9a55-0b1c2d3e4f50", "fonte": "tw"}})
+	old.Create(&legacyTask{Description: "Sem uuid"})
+	apagada := legacyTask{Description: "Apagada"}
+	old.Create(&apagada)
+	old.Delete(&apagada)
+	if sqlDB, err := old.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	openTestDBAt(t, path)
+	var tasks []schema.Task
+	if err := DB.Unscoped().Order("id asc").Find(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, task := range tasks {
+		if task.UUID == "" || seen[task.UUID] {
+			t.Errorf("task %d has empty or repeated UUID %q", task.ID, task.UUID)
+		}
+		seen[task.UUID] = true
+	}
+	if tasks[0].UUID != "3f0c2a9e-1111-4d6e-9a55-0b1c2d3e4f50" {
+		t.Errorf("UUID = %q, want the one from Extras[\"uuid\"]", tasks[0].UUID)
+	}
+	if _, ok := tasks[0].Extras["uuid"]; ok || tasks[0].Extras["fonte"] != "tw" {
+		t.Errorf("Extras = %v, want uuid moved out and other extras kept", tasks[0].Extras)
+	}
+
+	// O índice único passa a valer.
+	dup := schema.Task{Description: "Duplicada", UUID: tasks[1].UUID}
+	if err := DB.Create(&dup).Error; err == nil {
+		t.Errorf("creating a task with a repeated UUID expected error, got nil")
+	}
+}
+
+// TestInitDB tests that InitDB finds the list of the working directory,
+// walking up from it, before falling back to the global lists
+func TestInitDB(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := filepath.Join(home, "repo")
+	deep := filepath.Join(repo, "a", "b")
+	for _, dir := range []string{deep, filepath.Join(repo, config.ProjectDir)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(deep)
+	t.Cleanup(closeDB)
+
+	tests := []struct {
+		name     string
+		list     string
+		global   bool
+		wantPath string
+		wantList string
+		wantRoot string
+	}{
+		{"Directory list", "", false, filepath.Join(repo, config.ProjectDir, "tasks.db"), "repo", repo},
+		{"Global", "", true, filepath.Join(home, ".togo", "tasks.db"), DefaultList, ""},
+		{"Named list", "trabalho", false, filepath.Join(home, ".togo", "lists", "trabalho.db"), "trabalho", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closeDB()
+			if err := InitDB(tt.list, tt.global); err != nil {
+				t.Fatalf("InitDB() unexpected error: %v", err)
+			}
+			if Path != tt.wantPath || List != tt.wantList || ProjectRoot != tt.wantRoot {
+				t.Errorf("InitDB() opened %q (list %q, root %q), want %q (list %q, root %q)",
+					Path, List, ProjectRoot, tt.wantPath, tt.wantList, tt.wantRoot)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"fmt"
+	"levyvix/togo/internal/config"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultList é a lista guardada em ~/.togo/tasks.db.
+const DefaultList = "default"
+
+// List é o nome da lista aberta por InitDB ou OpenProject.
+var List string
+
+// ProjectRoot é o diretório do projeto cuja lista está aberta, ou "" quando
+// a lista aberta é global.
+var ProjectRoot string
+
+var listNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateListName garante que name pode ser usado como nome de arquivo.
+func ValidateListName(name string) error {
+	if !listNameRe.MatchString(name) {
+		return fmt.Errorf("invalid list name %q: use only letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ListPath retorna o arquivo do banco da lista: ~/.togo/tasks.db para a
+// lista padrão e ~/.togo/lists/<nome>.db para as demais.
+func ListPath(name string) (string, error) {
+	if name == DefaultList {
+		return DefaultPath()
+	}
+	if err := ValidateListName(name); err != nil {
+		return "", err
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lists", name+".db"), nil
+}
+
+// Resolve encontra o banco de um comando sem abri-lo. Sem list e sem
+// global, procura a lista do diretório atual subindo a partir dele (um
+// .togo/ ou .togo.toml, ver config.FindProject) e retorna o projeto
+// encontrado. Caso contrário, usa a lista informada ou, sem ela, a definida
+// com 'togo use', e por fim a lista padrão.
+func Resolve(list string, global bool) (path, name string, project *config.Project, err error) {
+	if list == "" && !global {
+		project, err := config.FindProject(".")
+		if err != nil {
+			return "", "", nil, err
+		}
+		if project != nil {
+			return project.DBPath, project.Name, project, nil
+		}
+	}
+
+	if list == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return "", "", nil, err
+		}
+		list = cfg.List
+	}
+	if list == "" {
+		list = DefaultList
+	}
+	path, err = ListPath(list)
+	return path, list, nil, err
+}
+
+// OpenProject abre a lista de um diretório descoberta por config.FindProject.
+func OpenProject(p *config.Project) error {
+	if err := Open(p.DBPath); err != nil {
+		return err
+	}
+	List = p.Name
+	ProjectRoot = p.Root
+	return nil
+}
+
+// Lists retorna os nomes de todas as listas existentes, em ordem alfabética.
+// A lista padrão sempre aparece.
+func Lists() ([]string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "lists"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read lists: %w", err)
+	}
+
+	// Uma lista pode existir como <nome>.db ou, criptografada, <nome>.db.enc.
+	seen := map[string]bool{DefaultList: true}
+	var names []string
+	for _, e := range entries {
+		name := strings.TrimSuffix(strings.TrimSuffix(e.Name(), EncryptedExt), ".db")
+		if e.IsDir() || name == e.Name() || seen[name] || ValidateListName(name) != nil {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultList}, names...), nil
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TableInfo contém a contagem de linhas de uma tabela. Deleted conta as
+// linhas removidas por soft delete, que continuam no arquivo; SoftDelete
+// informa se a tabela tem soft delete (coluna deleted_at).
+type TableInfo struct {
+	Name       string
+	Active     int64
+	Deleted    int64
+	SoftDelete bool
+}
+
+// Info resume o estado do arquivo de banco.
+type Info struct {
+	Path          string
+	Size          int64
+	SchemaVersion int
+	JournalMode   string
+	Encrypted     bool
+	Tables        []TableInfo
+}
+
+// Check executa PRAGMA integrity_check e PRAGMA foreign_key_check e retorna
+// os problemas encontrados. Uma lista vazia significa que o banco está íntegro.
+func Check() ([]string, error) {
+	// Com páginas muito danificadas, o próprio PRAGMA falha; o erro é então
+	// o problema encontrado.
+	var problems []string
+	var integrity []string
+	if err := DB.Raw("PRAGMA integrity_check").Scan(&integrity).Error; err != nil {
+		if !isCorrupt(err) {
+			return nil, fmt.Errorf("integrity check failed: %w", err)
+		}
+		problems = append(problems, err.Error())
+	}
+	for _, line := range integrity {
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+
+	rows, err := DB.Raw("PRAGMA foreign_key_check").Rows()
+	if isCorrupt(err) {
+		return problems, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("foreign key check failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, parent string
+		var rowid, fkid any
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return nil, fmt.Errorf("foreign key check failed: %w", err)
+		}
+		problems = append(problems, fmt.Sprintf("foreign key violation: %s row %v references missing %s", table, rowid, parent))
+	}
+	return problems, rows.Err()
+}
+
+// Vacuum reconstrói o arquivo do banco, liberando o espaço de linhas
+// removidas. Retorna o tamanho do arquivo antes e depois.
+func Vacuum() (before, after int64, err error) {
+	before = fileSize(diskPath())
+	vacuum := func() error {
+		if err := DB.Exec("VACUUM").Error; err != nil {
+			return fmt.Errorf("vacuum failed: %w", err)
+		}
+		return nil
+	}
+	if IsEncrypted() {
+		err = update(vacuum)
+	} else {
+		err = vacuum()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, fileSize(diskPath()), nil
+}
+
+// Stats coleta caminho, tamanho, versão do esquema e contagem de linhas
+// (incluindo as removidas por soft delete) de cada tabela.
+func Stats() (Info, error) {
+	info := Info{Path: diskPath(), Size: fileSize(diskPath()), Encrypted: IsEncrypted()}
+
+	version, err := schemaVersion(DB)
+	if err != nil {
+		return info, err
+	}
+	info.SchemaVersion = version
+
+	if err := DB.Raw("PRAGMA journal_mode").Scan(&info.JournalMode).Error; err != nil {
+		return info, fmt.Errorf("failed to read journal mode: %w", err)
+	}
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(model); err != nil {
+			return info, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		table := TableInfo{Name: stmt.Schema.Table}
+		if err := DB.Model(model).Count(&table.Active).Error; err != nil {
+			return info, fmt.Errorf("failed to count %s: %w", table.Name, err)
+		}
+		if stmt.Schema.LookUpField("DeletedAt") != nil {
+			table.SoftDelete = true
+			if err := DB.Unscoped().Model(model).Where("deleted_at IS NOT NULL").Count(&table.Deleted).Error; err != nil {
+				return info, fmt.Errorf("failed to count deleted %s: %w", table.Name, err)
+			}
+		}
+		info.Tables = append(info.Tables, table)
+	}
+	return info, nil
+}
+
+// Repair copia todas as linhas legíveis do banco atual para um banco novo e
+// passa a usá-lo. O arquivo original é mantido ao lado com o sufixo
+// .corrupt-<timestamp>. Retorna quantas linhas foram recuperadas e quantas
+// foram perdidas.
+func Repair() (recovered, lost int, corruptPath string, err error) {
+	if Path == "" {
+		return 0, 0, "", fmt.Errorf("database is not backed by a file")
+	}
+	if IsEncrypted() {
+		return 0, 0, "", fmt.Errorf("repair is not available for encrypted databases; decrypt it first")
+	}
+
+	fresh := Path + ".repair"
+	_ = os.Remove(fresh)
+	dest, err := gorm.Open(sqlite.Open(fresh), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("failed to create %s: %w", fresh, err)
+	}
+	destSQL, err := dest.DB()
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if err := migrate(dest); err != nil {
+		destSQL.Close()
+		return 0, 0, "", err
+	}
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(model); err != nil {
+			destSQL.Close()
+			return 0, 0, "", fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		ok, bad := recoverTable(dest, stmt.Schema.Table)
+		recovered += ok
+		lost += bad
+	}
+	destSQL.Close()
+
+	corruptPath = Path + ".corrupt-" + time.Now().Format(backupTimeFormat)
+	if err := reopenWith(fresh, corruptPath); err != nil {
+		return recovered, lost, "", err
+	}
+	return recovered, lost, corruptPath, nil
+}
+
+// recoverTable copia, linha a linha, as linhas de table que ainda podem ser
+// lidas. Linhas cuja leitura falha são contadas como perdidas.
+func recoverTable(dest *gorm.DB, table string) (recovered, lost int) {
+	var ids []int64
+	if err := DB.Raw(fmt.Sprintf("SELECT rowid FROM %q", table)).Scan(&ids).Error; err != nil {
+		// O índice da tabela está danificado: tenta cada rowid até o maior conhecido.
+		var maxID int64
+		DB.Raw(fmt.Sprintf("SELECT max(rowid) FROM %q", table)).Scan(&maxID)
+		ids = ids[:0]
+		for id := int64(1); id <= maxID; id++ {
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range ids {
+		var row map[string]any
+		result := DB.Raw(fmt.Sprintf("SELECT * FROM %q WHERE rowid = ?", table), id).Scan(&row)
+		if result.Error != nil {
+			lost++
+			continue
+		}
+		if len(row) == 0 {
+			continue
+		}
+		if err := dest.Table(table).Create(row).Error; err != nil {
+			lost++
+			continue
+		}
+		recovered++
+	}
+	return recovered, lost
+}
+
+// reopenWith fecha o banco atual, move o arquivo para backupPath, coloca
+// replacement no lugar e reabre.
+func reopenWith(replacement, backupPath string) error {
+	path := Path
+	if sqlDB, err := DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(path + suffix); err == nil {
+			_ = os.Rename(path+suffix, backupPath+suffix)
+		}
+	}
+	if err := os.Rename(path, backupPath); err != nil {
+		return fmt.Errorf("failed to move %s aside: %w", filepath.Base(path), err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		return fmt.Errorf("failed to move repaired database into place: %w", err)
+	}
+	return Open(path)
+}
+
+// isCorrupt informa se err é um SQLITE_CORRUPT ou SQLITE_NOTADB.
+func isCorrupt(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrCorrupt || sqliteErr.Code == sqlite3.ErrNotADB
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"levyvix/togo/schema"
+)
+
+// TestCheck tests the integrity check on a healthy database
+func TestCheck(t *testing.T) {
+	openTestDB(t)
+	DB.Create(&schema.Task{Description: "Estudar Go"})
+
+	problems, err := Check()
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Check() problems = %v, want none", problems)
+	}
+}
+
+// TestStats tests row counts including soft-deleted rows
+func TestStats(t *testing.T) {
+	path := openTestDB(t)
+	for _, d := range []string{"A", "B", "C"} {
+		DB.Create(&schema.Task{Description: d})
+	}
+	DB.Delete(&schema.Task{}, 1)
+
+	info, err := Stats()
+	if err != nil {
+		t.Fatalf("Stats() unexpected error: %v", err)
+	}
+	if info.Path != path {
+		t.Errorf("Stats().Path = %q, want %q", info.Path, path)
+	}
+	if info.SchemaVersion != SchemaVersion {
+		t.Errorf("Stats().SchemaVersion = %d, want %d", info.SchemaVersion, SchemaVersion)
+	}
+	if info.Size == 0 {
+		t.Errorf("Stats().Size = 0, want > 0")
+	}
+
+	var tasks, links *TableInfo
+	for i := range info.Tables {
+		switch info.Tables[i].Name {
+		case "tasks":
+			tasks = &info.Tables[i]
+		case "commit_links":
+			links = &info.Tables[i]
+		}
+	}
+	if tasks == nil || links == nil {
+		t.Fatalf("Stats() has no tasks or commit_links table: %+v", info.Tables)
+	}
+	if tasks.Active != 2 || tasks.Deleted != 1 || !tasks.SoftDelete {
+		t.Errorf("tasks = %+v, want 2 active / 1 deleted with soft delete", *tasks)
+	}
+	if links.SoftDelete {
+		t.Errorf("commit_links = %+v, want no soft delete", *links)
+	}
+}
+
+// TestVacuum tests that vacuum runs and reports file sizes
+func TestVacuum(t *testing.T) {
+	openTestDB(t)
+	DB.Create(&schema.Task{Description: "Estudar Go"})
+
+	before, after, err := Vacuum()
+	if err != nil {
+		t.Fatalf("Vacuum() unexpected error: %v", err)
+	}
+	if before == 0 || after == 0 {
+		t.Errorf("Vacuum() sizes = %d -> %d, want both > 0", before, after)
+	}
+}
+
+// TestRepair tests that repair copies every row (including soft-deleted ones)
+// into a fresh database and keeps the original file
+func TestRepair(t *testing.T) {
+	path := openTestDB(t)
+	DB.Create(&schema.Task{Description: "Estudar Go"})
+	DB.Create(&schema.Task{Description: "Fazer compras"})
+	DB.Delete(&schema.Task{}, 2)
+
+	recovered, lost, corrupt, err := Repair()
+	if err != nil {
+		t.Fatalf("Repair() unexpected error: %v", err)
+	}
+	if recovered != 2 || lost != 0 {
+		t.Errorf("Repair() = %d recovered / %d lost, want 2 / 0", recovered, lost)
+	}
+	if _, err := os.Stat(corrupt); err != nil {
+		t.Errorf("original database should be kept at %s: %v", corrupt, err)
+	}
+	if Path != path {
+		t.Errorf("after Repair Path = %q, want %q", Path, path)
+	}
+
+	var task schema.Task
+	if err := DB.First(&task, 1).Error; err != nil || task.Description != "Estudar Go" {
+		t.Errorf("after Repair task 1 = %q (%v), want %q", task.Description, err, "Estudar Go")
+	}
+	var deleted int64
+	DB.Unscoped().Model(&schema.Task{}).Where("deleted_at IS NOT NULL").Count(&deleted)
+	if deleted != 1 {
+		t.Errorf("after Repair soft-deleted rows = %d, want 1", deleted)
+	}
+}
+
+// TestCheckRepairCorrupt tests check and repair on a file whose corruption
+// makes the regular Open fail during the migrations
+func TestCheckRepairCorrupt(t *testing.T) {
+	path := openTestDB(t)
+	for i := 0; i < 201; i++ {
+		DB.Create(&schema.Task{Description: fmt.Sprintf("Tarefa %d", i)})
+	}
+	var page, pageSize int64
+	DB.Raw("SELECT rootpage FROM sqlite_master WHERE type = 'index' AND name = 'idx_tasks_uuid'").Scan(&page)
+	DB.Raw("PRAGMA page_size").Scan(&pageSize)
+	if err := Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+	if page == 0 {
+		t.Fatal("uuid index not found")
+	}
+
+	// Overwrite the root page of the uuid index, which the migrations read.
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(raw[(page-1)*pageSize:page*pageSize], bytes.Repeat([]byte{0xAB}, int(pageSize)))
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(path); err == nil {
+		t.Fatal("Open() of the corrupted file should fail")
+	}
+
+	if err := OpenRaw(path); err != nil {
+		t.Fatalf("OpenRaw() unexpected error: %v", err)
+	}
+	problems, err := Check()
+	if err != nil || len(problems) == 0 {
+		t.Errorf("Check() = %v, %v, want problems and no error", problems, err)
+	}
+
+	recovered, _, _, err := Repair()
+	if err != nil {
+		t.Fatalf("Repair() unexpected error: %v", err)
+	}
+	if recovered < 201 || countTasks(t) != 201 {
+		t.Errorf("Repair() recovered %d rows / %d tasks, want the 201 tasks", recovered, countTasks(t))
+	}
+	if problems, err := Check(); err != nil || len(problems) != 0 {
+		t.Errorf("Check() after Repair = %v, %v, want none", problems, err)
+	}
+}
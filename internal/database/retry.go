@@ -0,0 +1,63 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+const (
+	// busyTimeout é quanto o SQLite espera por um lock antes de devolver SQLITE_BUSY.
+	busyTimeout = 5 * time.Second
+
+	// maxRetries limita quantas vezes uma transação é repetida após SQLITE_BUSY.
+	maxRetries = 5
+
+	retryBackoff = 50 * time.Millisecond
+)
+
+// Transaction executa fn dentro de uma transação no banco global. Se o banco
+// estiver ocupado por outro processo (SQLITE_BUSY/SQLITE_LOCKED mesmo após o
+// busy_timeout), a transação inteira é repetida algumas vezes com espera
+// crescente antes de desistir. Em um banco criptografado, a transação roda
+// com o arquivo travado, sobre o conteúdo mais recente dele, e o arquivo é
+// regravado após a confirmação.
+func Transaction(fn func(tx *gorm.DB) error) error {
+	if IsEncrypted() {
+		return update(func() error { return transaction(DB, fn) })
+	}
+	return transaction(DB, fn)
+}
+
+// TransactionOn é Transaction em um banco aberto por OpenOther.
+func TransactionOn(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return transaction(db, fn)
+}
+
+func transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	var err error
+	wait := retryBackoff
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		err = db.Transaction(fn)
+		if !IsBusy(err) {
+			return err
+		}
+		if attempt < maxRetries {
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}
+	return fmt.Errorf("database is busy, gave up after %d retries: %w", maxRetries, err)
+}
+
+// IsBusy informa se err é um SQLITE_BUSY ou SQLITE_LOCKED.
+func IsBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+}
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+const (
+	workers      = 8
+	tasksPerUnit = 25
+	helperEnv    = "TOGO_TEST_HELPER_DB"
+	encryptedEnv = "TOGO_TEST_ENCRYPTED"
+)
+
+// createAndComplete creates n tasks through db and marks each one as done,
+// every write in its own retried transaction
+func createAndComplete(db *gorm.DB, worker, n int) error {
+	return createAndCompleteWith(func(fn func(tx *gorm.DB) error) error {
+		return transaction(db, fn)
+	}, worker, n)
+}
+
+// createAndCompleteWith is createAndComplete with each write run by transact
+func createAndCompleteWith(transact func(fn func(tx *gorm.DB) error) error, worker, n int) error {
+	for i := 0; i < n; i++ {
+		task := schema.Task{Description: fmt.Sprintf("worker %d task %d", worker, i)}
+		if err := transact(func(tx *gorm.DB) error {
+			return tx.Create(&task).Error
+		}); err != nil {
+			return err
+		}
+
+		if err := transact(func(tx *gorm.DB) error {
+			var t schema.Task
+			if err := tx.First(&t, task.ID).Error; err != nil {
+				return err
+			}
+			now := time.Now()
+			t.Done = true
+			t.DoneAt = &now
+			return tx.Save(&t).Error
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkAllDone(t *testing.T, want int64) {
+	t.Helper()
+	var total, done int64
+	DB.Model(&schema.Task{}).Count(&total)
+	DB.Model(&schema.Task{}).Where("done = ?", true).Count(&done)
+	if total != want || done != want {
+		t.Errorf("tasks = %d total / %d done, want %d / %d", total, done, want, want)
+	}
+}
+
+// TestConcurrentWrites tests many connections writing to the same file at once
+func TestConcurrentWrites(t *testing.T) {
+	path := openTestDB(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			// Each worker has its own handle, like a separate togo process
+			db, err := openFile(path)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer func() {
+				if sqlDB, err := db.DB(); err == nil {
+					sqlDB.Close()
+				}
+			}()
+			errs <- createAndComplete(db, w, tasksPerUnit)
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("worker failed: %v", err)
+		}
+	}
+	checkAllDone(t, workers*tasksPerUnit)
+}
+
+// TestConcurrentProcesses tests several togo processes writing to the same file
+func TestConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-process test in short mode")
+	}
+	path := openTestDB(t)
+
+	cmds := make([]*exec.Cmd, 4)
+	for i := range cmds {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmds[i].Env = append(os.Environ(), helperEnv+"="+path, fmt.Sprintf("TOGO_TEST_WORKER=%d", i))
+		if err := cmds[i].Start(); err != nil {
+			t.Fatalf("failed to start helper process: %v", err)
+		}
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("helper process failed: %v", err)
+		}
+	}
+	checkAllDone(t, int64(len(cmds)*tasksPerUnit))
+}
+
+// TestConcurrentEncryptedProcesses tests several togo processes writing to
+// the same encrypted file, each with its own copy of the database in memory
+func TestConcurrentEncryptedProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-process test in short mode")
+	}
+	path := openTestDB(t)
+	t.Setenv(PassphraseEnv, "segredo")
+	if err := Encrypt([]byte("segredo")); err != nil {
+		t.Fatalf("Encrypt() unexpected error: %v", err)
+	}
+
+	cmds := make([]*exec.Cmd, 4)
+	stderr := make([]bytes.Buffer, len(cmds))
+	for i := range cmds {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmds[i].Env = append(os.Environ(), helperEnv+"="+path, encryptedEnv+"=1", fmt.Sprintf("TOGO_TEST_WORKER=%d", i))
+		cmds[i].Stderr = &stderr[i]
+		if err := cmds[i].Start(); err != nil {
+			t.Fatalf("failed to start helper process: %v", err)
+		}
+	}
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("helper process failed: %v: %s", err, stderr[i].String())
+		}
+	}
+
+	// This process still holds the image loaded before the helpers ran.
+	if err := Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+	openTestDBAt(t, path)
+	checkAllDone(t, int64(len(cmds)*tasksPerUnit))
+	if tmps, _ := filepath.Glob(path + EncryptedExt + ".*.tmp"); len(tmps) > 0 {
+		t.Errorf("temporary files left behind: %v", tmps)
+	}
+}
+
+// TestHelperProcess is not a real test: it is the body of each process
+// started by TestConcurrentProcesses
+func TestHelperProcess(t *testing.T) {
+	path := os.Getenv(helperEnv)
+	if path == "" {
+		t.Skip("helper process only")
+	}
+	var worker int
+	fmt.Sscan(os.Getenv("TOGO_TEST_WORKER"), &worker)
+	if os.Getenv(encryptedEnv) != "" {
+		if err := Open(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := createAndCompleteWith(Transaction, worker, tasksPerUnit); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	db, err := openFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := createAndComplete(db, worker, tasksPerUnit); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// TestTransactionGivesUp tests that a busy transaction is retried and that
+// the last failure returns without waiting
+func TestTransactionGivesUp(t *testing.T) {
+	openTestDB(t)
+	attempts := 0
+	var last time.Time
+	err := transaction(DB, func(tx *gorm.DB) error {
+		attempts++
+		last = time.Now()
+		return sqlite3.Error{Code: sqlite3.ErrBusy}
+	})
+	if !IsBusy(err) {
+		t.Errorf("transaction() error = %v, want a busy error", err)
+	}
+	if attempts != maxRetries+1 {
+		t.Errorf("attempts = %d, want %d", attempts, maxRetries+1)
+	}
+	if wait := time.Since(last); wait >= retryBackoff<<(maxRetries-1) {
+		t.Errorf("returned %v after the last attempt, want no backoff", wait)
+	}
+}
+
+// TestIsBusy tests detection of SQLite lock errors
+func TestIsBusy(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"locked", sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{"wrapped busy", fmt.Errorf("save: %w", sqlite3.Error{Code: sqlite3.ErrBusy}), true},
+		{"constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBusy(tt.err); got != tt.want {
+				t.Errorf("IsBusy(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package events é o barramento em memória dos eventos de alteração de
+// tarefas, publicados pelo serviço e transmitidos pelo togo serve (SSE e
+// WebSocket) a quem estiver inscrito.
+package events
+
+import (
+	"fmt"
+	"levyvix/togo/schema"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Type é o tipo de um evento.
+type Type string
+
+const (
+	Created Type = "task.created"
+	Updated Type = "task.updated"
+	Done    Type = "task.done"
+	Deleted Type = "task.deleted"
+	// Cleared é publicado pelo clear, que apaga todas as tarefas de uma vez;
+	// não traz tarefa.
+	Cleared Type = "tasks.cleared"
+	// Reset avisa ao inscrito que eventos foram perdidos (o ID pedido é de
+	// outra execução do servidor ou já saiu do buffer) e que ele deve reler
+	// as tarefas.
+	Reset Type = "reset"
+)
+
+// DefaultBuffer é quantos eventos o barramento guarda para a retomada.
+const DefaultBuffer = 1000
+
+// subscriberBuffer é a folga do canal de cada inscrito. Quem ficar para trás
+// mais do que isso é desligado e deve se reinscrever com o último ID lido.
+const subscriberBuffer = 64
+
+// Event é uma alteração de tarefa.
+type Event struct {
+	// ID é "<época>-<sequência>": a época identifica a execução do
+	// servidor, e a sequência cresce a cada evento.
+	ID   string
+	Type Type
+	Time time.Time
+	// Task é a tarefa depois da alteração (nil em Cleared e Reset).
+	Task *schema.Task
+
+	seq uint64
+}
+
+// Bus distribui os eventos aos inscritos e guarda os últimos para que um
+// inscrito que caiu retome de onde parou.
+type Bus struct {
+	mu     sync.Mutex
+	epoch  string
+	seq    uint64
+	buffer []Event // os últimos eventos, do mais antigo ao mais novo
+	size   int
+	subs   map[*Subscription]struct{}
+}
+
+// NewBus cria um barramento que guarda os últimos size eventos.
+func NewBus(size int) *Bus {
+	return &Bus{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:  size,
+		subs:  map[*Subscription]struct{}{},
+	}
+}
+
+// Publish publica um evento sobre t (que pode ser nil) e o retorna.
+func (b *Bus) Publish(typ Type, t *schema.Task) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	e := Event{ID: fmt.Sprintf("%s-%d", b.epoch, b.seq), Type: typ, Time: time.Now(), seq: b.seq}
+	if t != nil {
+		task := *t
+		e.Task = &task
+	}
+	b.buffer = append(b.buffer, e)
+	if len(b.buffer) > b.size {
+		b.buffer = b.buffer[len(b.buffer)-b.size:]
+	}
+	for s := range b.subs {
+		select {
+		case s.c <- e:
+		default:
+			b.drop(s)
+		}
+	}
+	return e
+}
+
+// Subscription é uma inscrição no barramento.
+type Subscription struct {
+	// Missed são os eventos publicados depois do ID pedido em Subscribe, a
+	// entregar antes dos de C (ou só um Reset, se não der para retomar).
+	Missed []Event
+	// C recebe os eventos novos. É fechado por Close ou quando o inscrito
+	// fica para trás demais.
+	C <-chan Event
+
+	c   chan Event
+	bus *Bus
+}
+
+// Subscribe inscreve um novo ouvinte. Com lastID (o ID do último evento
+// recebido), Missed traz o que foi publicado depois dele.
+func (b *Bus) Subscribe(lastID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &Subscription{c: make(chan Event, subscriberBuffer), bus: b}
+	s.C = s.c
+	if lastID != "" {
+		s.Missed = b.since(lastID)
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// since retorna os eventos depois de lastID, ou um Reset se eles não
+// estiverem mais todos no buffer.
+func (b *Bus) since(lastID string) []Event {
+	epoch, seqText, _ := strings.Cut(lastID, "-")
+	seq, err := strconv.ParseUint(seqText, 10, 64)
+	if err != nil || epoch != b.epoch || seq > b.seq {
+		return []Event{b.reset()}
+	}
+	if seq == b.seq {
+		return nil
+	}
+	if len(b.buffer) == 0 || b.buffer[0].seq > seq+1 {
+		return []Event{b.reset()}
+	}
+	missed := b.buffer[seq+1-b.buffer[0].seq:]
+	return append([]Event(nil), missed...)
+}
+
+// reset é o evento Reset, com o ID do último evento publicado para que o
+// inscrito retome dali.
+func (b *Bus) reset() Event {
+	return Event{ID: fmt.Sprintf("%s-%d", b.epoch, b.seq), Type: Reset, Time: time.Now(), seq: b.seq}
+}
+
+// Close cancela a inscrição.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.drop(s)
+}
+
+func (b *Bus) drop(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
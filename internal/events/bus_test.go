@@ -0,0 +1,110 @@
+package events
+
+import (
+	"levyvix/togo/schema"
+	"testing"
+)
+
+// receive reads the next event from s without blocking
+func receive(t *testing.T, s *Subscription) Event {
+	t.Helper()
+	select {
+	case e, ok := <-s.C:
+		if !ok {
+			t.Fatal("subscription closed unexpectedly")
+		}
+		return e
+	default:
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+// TestPublishSubscribe tests that subscribers receive published events in order
+func TestPublishSubscribe(t *testing.T) {
+	bus := NewBus(10)
+	a, b := bus.Subscribe(""), bus.Subscribe("")
+	defer a.Close()
+
+	bus.Publish(Created, &schema.Task{Description: "Estudar Go"})
+	bus.Publish(Done, &schema.Task{Description: "Estudar Go", Done: true})
+	for _, s := range []*Subscription{a, b} {
+		if e := receive(t, s); e.Type != Created || e.Task.Description != "Estudar Go" {
+			t.Errorf("first event = %v %v, want task.created", e.Type, e.Task)
+		}
+		if e := receive(t, s); e.Type != Done || !e.Task.Done {
+			t.Errorf("second event = %v %v, want task.done", e.Type, e.Task)
+		}
+	}
+
+	b.Close()
+	if _, ok := <-b.C; ok {
+		t.Error("closed subscription still receives events")
+	}
+	b.Close()
+}
+
+// TestResume tests resuming from a last event ID
+func TestResume(t *testing.T) {
+	bus := NewBus(3)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, bus.Publish(Updated, nil).ID)
+	}
+
+	tests := []struct {
+		name      string
+		lastID    string
+		wantIDs   []string
+		wantReset bool
+	}{
+		{name: "recent", lastID: ids[2], wantIDs: ids[3:]},
+		{name: "oldest kept", lastID: ids[1], wantIDs: ids[2:]},
+		{name: "up to date", lastID: ids[4], wantIDs: nil},
+		{name: "out of buffer", lastID: ids[0], wantReset: true},
+		{name: "previous run", lastID: "abc-3", wantReset: true},
+		{name: "future", lastID: ids[4][:len(ids[4])-1] + "9", wantReset: true},
+		{name: "garbage", lastID: "x", wantReset: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := bus.Subscribe(tt.lastID)
+			defer s.Close()
+			if tt.wantReset {
+				if len(s.Missed) != 1 || s.Missed[0].Type != Reset || s.Missed[0].ID != ids[4] {
+					t.Errorf("Missed = %v, want a single reset at %s", s.Missed, ids[4])
+				}
+				return
+			}
+			var got []string
+			for _, e := range s.Missed {
+				got = append(got, e.ID)
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Missed = %v, want %v", got, tt.wantIDs)
+			}
+			for i := range got {
+				if got[i] != tt.wantIDs[i] {
+					t.Errorf("Missed = %v, want %v", got, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+// TestSlowSubscriber tests that a subscriber that falls behind is dropped
+func TestSlowSubscriber(t *testing.T) {
+	bus := NewBus(DefaultBuffer)
+	slow := bus.Subscribe("")
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(Updated, nil)
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber got %d events before being dropped, want %d", n, subscriberBuffer)
+	}
+	slow.Close()
+}
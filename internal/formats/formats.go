@@ -0,0 +1,80 @@
+// Package formats converte tarefas de e para formatos de arquivo usados por
+// outras ferramentas (todo.txt, Taskwarrior, etc.).
+package formats
+
+import (
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Format é um formato de importação/exportação.
+type Format interface {
+	// Name é o nome usado em --format.
+	Name() string
+	// Extensions lista as extensões de arquivo reconhecidas na importação.
+	Extensions() []string
+	Export(w io.Writer, tasks []schema.Task) error
+	Import(r io.Reader) ([]schema.Task, error)
+}
+
+// Upserter é implementado por formatos em que cada tarefa tem um
+// identificador estável (como o UID do iCalendar). Na importação, uma tarefa
+// cujo identificador já existe no banco é atualizada em vez de duplicada.
+type Upserter interface {
+	// Key retorna o identificador da tarefa, ou "" se ela não tiver um.
+	Key(t schema.Task) string
+	// Fields lista os campos de schema.Task carregados pelo formato; só eles
+	// são sobrescritos em uma tarefa existente.
+	Fields() []string
+}
+
+// Archive é implementado por formatos que guardam todos os campos da tarefa.
+// A exportação inclui as tarefas apagadas (soft delete) e a importação
+// preserva os IDs, resolvendo conflitos com a política de --on-conflict.
+type Archive interface {
+	Complete()
+}
+
+var registry = map[string]Format{}
+
+// Register adiciona um formato ao registro. Cada formato se registra no
+// init do seu arquivo.
+func Register(f Format) {
+	registry[f.Name()] = f
+}
+
+// Get retorna o formato com o nome informado.
+func Get(name string) (Format, error) {
+	f, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("formato desconhecido '%s' (disponíveis: %s)", name, strings.Join(Names(), ", "))
+	}
+	return f, nil
+}
+
+// Detect escolhe o formato pela extensão do arquivo.
+func Detect(path string) (Format, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, name := range Names() {
+		for _, e := range registry[name].Extensions() {
+			if e == ext {
+				return registry[name], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("não foi possível detectar o formato de '%s', use --format", path)
+}
+
+// Names retorna os nomes dos formatos registrados, em ordem alfabética.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,51 @@
+package formats
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// gormModel returns a gorm.Model with only CreatedAt set
+func gormModel(created time.Time) gorm.Model {
+	return gorm.Model{CreatedAt: created}
+}
+
+// TestDetect tests choosing a format from the file extension
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path      string
+		want      string
+		wantError bool
+	}{
+		{path: "todo.txt", want: "todotxt"},
+		{path: "TODO.TXT", want: "todotxt"},
+		{path: "agenda.ics", want: "ics"},
+		{path: "notas.md", want: "markdown"},
+		{path: "agenda.org", want: "org"},
+		{path: "tarefas.json", want: "json"},
+		{path: "tarefas.xyz", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f, err := Detect(tt.path)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("Detect(%q) expected error, got nil", tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Detect(%q) unexpected error: %v", tt.path, err)
+			}
+			if f.Name() != tt.want {
+				t.Errorf("Detect(%q) = %s, want %s", tt.path, f.Name(), tt.want)
+			}
+		})
+	}
+
+	if _, err := Get("inexistente"); err == nil {
+		t.Errorf("Get(inexistente) expected error, got nil")
+	}
+}
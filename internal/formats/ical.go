@@ -0,0 +1,401 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	icalDateTime = "20060102T150405Z"
+	icalLocal    = "20060102T150405"
+	icalDate     = "20060102"
+	// icalLineLimit é o tamanho máximo de uma linha em octetos (RFC 5545, 3.1).
+	icalLineLimit = 75
+)
+
+// ICal implementa componentes VTODO do iCalendar (RFC 5545).
+//
+// Mapeamento:
+//   - UID ↔ UUID
+//   - SUMMARY ↔ Description
+//   - STATUS NEEDS-ACTION/IN-PROCESS ↔ pendente, COMPLETED ↔ Done,
+//     CANCELLED ↔ soft delete
+//   - COMPLETED ↔ DoneAt, DUE ↔ Due, CREATED ↔ CreatedAt,
+//     LAST-MODIFIED ↔ UpdatedAt
+//   - PRIORITY 1-9 ↔ A-I
+//   - CATEGORIES ↔ Tags
+//   - RRULE ↔ Recurrence
+//
+// Como o UID identifica a tarefa, a importação atualiza a tarefa existente
+// com o mesmo UID em vez de criar outra.
+type ICal struct{}
+
+func init() {
+	Register(ICal{})
+}
+
+func (ICal) Name() string { return "ics" }
+
+func (ICal) Extensions() []string { return []string{".ics"} }
+
+// Key retorna o UID da tarefa.
+func (ICal) Key(t schema.Task) string {
+	return t.UUID
+}
+
+// Fields são os campos carregados por um VTODO; os demais campos de uma
+// tarefa existente não são alterados pela importação.
+func (ICal) Fields() []string {
+	return []string{"Description", "Done", "DoneAt", "Due", "Priority", "Tags", "Recurrence"}
+}
+
+func (c ICal) Export(w io.Writer, tasks []schema.Task) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { writeICalLine(bw, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//levyvix//togo//PT")
+	for _, t := range tasks {
+		line("BEGIN:VTODO")
+		line("UID:" + escapeICalText(c.Key(t)))
+		stamp := t.UpdatedAt
+		if stamp.IsZero() {
+			stamp = time.Now()
+		}
+		line("DTSTAMP:" + formatICalDateTime(stamp))
+		if !t.CreatedAt.IsZero() {
+			line("CREATED:" + formatICalDateTime(t.CreatedAt))
+		}
+		if !t.UpdatedAt.IsZero() {
+			line("LAST-MODIFIED:" + formatICalDateTime(t.UpdatedAt))
+		}
+		line("SUMMARY:" + escapeICalText(t.Description))
+
+		switch {
+		case t.DeletedAt.Valid:
+			line("STATUS:CANCELLED")
+		case t.Done:
+			line("STATUS:COMPLETED")
+		default:
+			line("STATUS:NEEDS-ACTION")
+		}
+		if t.Done && t.DoneAt != nil {
+			line("COMPLETED:" + formatICalDateTime(*t.DoneAt))
+		}
+		if t.Due != nil {
+			line(formatICalDue(*t.Due))
+		}
+		if p := icalPriority(t.Priority); p != 0 {
+			line("PRIORITY:" + strconv.Itoa(p))
+		}
+		if len(t.Tags) > 0 {
+			escaped := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				escaped[i] = escapeICalText(tag)
+			}
+			line("CATEGORIES:" + strings.Join(escaped, ","))
+		}
+		if t.Recurrence != "" {
+			line("RRULE:" + t.Recurrence)
+		}
+		line("END:VTODO")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+func (ICal) Import(r io.Reader) ([]schema.Task, error) {
+	lines, err := unfoldICal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []schema.Task
+	var current []icalProperty
+	inTodo := false
+	depth := 0 // componentes aninhados dentro do VTODO, como VALARM
+	for n, raw := range lines {
+		if raw == "" {
+			continue
+		}
+		prop, err := parseICalLine(raw)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", n+1, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VTODO") && !inTodo:
+			inTodo = true
+			current = nil
+		case !inTodo:
+		case prop.name == "BEGIN":
+			depth++
+		case prop.name == "END" && depth > 0:
+			depth--
+		case prop.name == "END" && strings.EqualFold(prop.value, "VTODO"):
+			t, err := fromICal(current)
+			if err != nil {
+				return nil, fmt.Errorf("tarefa %d: %w", len(tasks)+1, err)
+			}
+			tasks = append(tasks, t)
+			inTodo = false
+		case depth == 0:
+			current = append(current, prop)
+		}
+	}
+	if inTodo {
+		return nil, fmt.Errorf("VTODO sem END:VTODO")
+	}
+	return tasks, nil
+}
+
+// icalProperty é uma linha de conteúdo: NOME;PARAM=valor:VALOR.
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func fromICal(props []icalProperty) (schema.Task, error) {
+	var t schema.Task
+	status := ""
+	for _, p := range props {
+		var err error
+		switch p.name {
+		case "UID":
+			t.UUID = unescapeICalText(p.value)
+		case "SUMMARY":
+			t.Description = unescapeICalText(p.value)
+		case "STATUS":
+			status = strings.ToUpper(p.value)
+		case "CREATED":
+			t.CreatedAt, err = parseICalTime(p)
+		case "LAST-MODIFIED":
+			t.UpdatedAt, err = parseICalTime(p)
+		case "COMPLETED":
+			var done time.Time
+			if done, err = parseICalTime(p); err == nil {
+				t.DoneAt = &done
+			}
+		case "DUE":
+			var due time.Time
+			if due, err = parseICalTime(p); err == nil {
+				t.Due = &due
+			}
+		case "PRIORITY":
+			var n int
+			if n, err = strconv.Atoi(p.value); err != nil || n < 0 || n > 9 {
+				err = fmt.Errorf("prioridade inválida '%s'", p.value)
+			}
+			t.Priority = togoICalPriority(n)
+		case "CATEGORIES":
+			for _, tag := range splitICalList(p.value) {
+				if tag != "" {
+					t.Tags = append(t.Tags, tag)
+				}
+			}
+		case "RRULE":
+			t.Recurrence = p.value
+		}
+		if err != nil {
+			return t, err
+		}
+	}
+
+	if strings.TrimSpace(t.Description) == "" {
+		return t, fmt.Errorf("a descrição (SUMMARY) não pode estar vazia")
+	}
+	switch status {
+	case "", "NEEDS-ACTION", "IN-PROCESS":
+		// Alguns clientes marcam a conclusão só com COMPLETED.
+		t.Done = status == "" && t.DoneAt != nil
+	case "COMPLETED":
+		t.Done = true
+	case "CANCELLED":
+		deleted := t.UpdatedAt
+		if deleted.IsZero() {
+			deleted = time.Now()
+		}
+		t.DeletedAt = gorm.DeletedAt{Time: deleted, Valid: true}
+	default:
+		return t, fmt.Errorf("status desconhecido '%s'", status)
+	}
+	if !t.Done {
+		t.DoneAt = nil
+	}
+	return t, nil
+}
+
+// unfoldICal lê as linhas do arquivo juntando as continuações (linhas que
+// começam com espaço ou tab).
+func unfoldICal(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseICalLine(line string) (icalProperty, error) {
+	p := icalProperty{}
+	// O nome e os parâmetros terminam no primeiro ':' fora de aspas.
+	inQuote := false
+	sep := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return p, fmt.Errorf("linha sem ':' '%s'", line)
+	}
+	p.value = line[sep+1:]
+
+	parts := strings.Split(line[:sep], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		if p.params == nil {
+			p.params = map[string]string{}
+		}
+		p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// parseICalTime aceita DATE-TIME em UTC, com TZID ou flutuante (hora local)
+// e DATE (meia-noite local).
+func parseICalTime(p icalProperty) (time.Time, error) {
+	v := p.value
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	var t time.Time
+	var err error
+	switch {
+	case strings.HasSuffix(v, "Z"):
+		t, err = time.Parse(icalDateTime, v)
+	case len(v) == len(icalDate):
+		t, err = time.ParseInLocation(icalDate, v, time.Local)
+	default:
+		t, err = time.ParseInLocation(icalLocal, v, loc)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("data inválida em %s '%s'", p.name, v)
+	}
+	return t.Local(), nil
+}
+
+func formatICalDateTime(t time.Time) string {
+	return t.UTC().Format(icalDateTime)
+}
+
+// formatICalDue usa DATE para prazos à meia-noite local, que é como o togo
+// guarda datas sem hora, e DATE-TIME nos demais casos.
+func formatICalDue(due time.Time) string {
+	local := due.Local()
+	if local.Hour() == 0 && local.Minute() == 0 && local.Second() == 0 && local.Nanosecond() == 0 {
+		return "DUE;VALUE=DATE:" + local.Format(icalDate)
+	}
+	return "DUE:" + formatICalDateTime(due)
+}
+
+// writeICalLine escreve uma linha terminada em CRLF, dobrando-a em
+// continuações de até 75 octetos sem partir caracteres UTF-8.
+func writeICalLine(w *bufio.Writer, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// A continuação começa com um espaço, que conta no limite.
+		limit = icalLineLimit - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escapeICalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+func unescapeICalText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// splitICalList separa um valor com vírgulas, ignorando as escapadas.
+func splitICalList(s string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, unescapeICalText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, unescapeICalText(s[start:]))
+}
+
+// icalPriority converte a prioridade do togo (A-Z) para 1-9; 0 é indefinida.
+func icalPriority(p string) int {
+	if len(p) != 1 || p[0] < 'A' || p[0] > 'Z' {
+		return 0
+	}
+	return min(int(p[0]-'A')+1, 9)
+}
+
+func togoICalPriority(n int) string {
+	if n < 1 || n > 9 {
+		return ""
+	}
+	return string(rune('A' + n - 1))
+}
@@ -0,0 +1,127 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+)
+
+// TestICalExport tests the VTODO properties, escaping and line folding
+func TestICalExport(t *testing.T) {
+	created := utc("20250101T120000Z")
+	done := utc("20250103T180000Z")
+	due := time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)
+	long := strings.Repeat("ação ", 20)
+	tasks := []schema.Task{
+		{Model: gormModel(created), Description: "Pagar conta; luz, água", Priority: "A", Due: &due, Tags: []string{"casa"}, Recurrence: "FREQ=MONTHLY", UUID: "5b0e7c1a-7f7d-4b7e-9a43-2c9d1e6f0a11"},
+		{Model: gormModel(created), Description: long, Done: true, DoneAt: &done, UUID: "abc@exemplo"},
+	}
+
+	var buf bytes.Buffer
+	if err := (ICal{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:5b0e7c1a-7f7d-4b7e-9a43-2c9d1e6f0a11\r\n",
+		`SUMMARY:Pagar conta\; luz\, água` + "\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		"DUE;VALUE=DATE:20250201\r\n",
+		"PRIORITY:1\r\n",
+		"CATEGORIES:casa\r\n",
+		"RRULE:FREQ=MONTHLY\r\n",
+		"CREATED:20250101T120000Z\r\n",
+		"UID:abc@exemplo\r\n",
+		"STATUS:COMPLETED\r\n",
+		"COMPLETED:20250103T180000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Export() output missing %q:\n%s", want, out)
+		}
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > icalLineLimit {
+			t.Errorf("Export() line has %d octets, want at most %d: %q", len(line), icalLineLimit, line)
+		}
+	}
+}
+
+// TestICalRoundTrip tests that importing an export gives back the same fields
+func TestICalRoundTrip(t *testing.T) {
+	created := utc("20250101T120000Z")
+	done := utc("20250103T180000Z")
+	due := utc("20250110T153000Z")
+	tasks := []schema.Task{
+		{Model: gormModel(created), Description: "Linha 1\nlinha 2 \\ com barra", Priority: "C", Due: &due, Tags: []string{"a,b", "c"}, UUID: "u1"},
+		{Model: gormModel(created), Description: strings.Repeat("longa ", 30), Done: true, DoneAt: &done, Recurrence: "FREQ=WEEKLY;BYDAY=MO", UUID: "u2"},
+	}
+
+	var buf bytes.Buffer
+	if err := (ICal{}).Export(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	again, err := (ICal{}).Import(&buf)
+	if err != nil {
+		t.Fatalf("Import() of exported data unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, tasks) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", again, tasks)
+	}
+}
+
+// TestICalImport tests parsing calendars written by other applications
+func TestICalImport(t *testing.T) {
+	input := "BEGIN:VCALENDAR\nVERSION:2.0\n" +
+		"BEGIN:VEVENT\nUID:evento\nSUMMARY:Reunião\nEND:VEVENT\n" +
+		"BEGIN:VTODO\nUID:t1\nSUMMARY:Com alarme\nDUE;TZID=America/Sao_Paulo:20250105T090000\n" +
+		"BEGIN:VALARM\nACTION:DISPLAY\nDESCRIPTION:lembrete\nEND:VALARM\nEND:VTODO\n" +
+		"BEGIN:VTODO\nUID:t2\nSUMMARY:Cancelada\nSTATUS:CANCELLED\nEND:VTODO\n" +
+		"BEGIN:VTODO\nUID:t3\nSUMMARY:Feita sem stat\n us\nCOMPLETED:20250102T100000Z\nEND:VTODO\n" +
+		"END:VCALENDAR\n"
+
+	tasks, err := (ICal{}).Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("Import() returned %d tasks, want 3 (VEVENT ignored)", len(tasks))
+	}
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err == nil {
+		want := time.Date(2025, 1, 5, 9, 0, 0, 0, saoPaulo)
+		if tasks[0].Due == nil || !tasks[0].Due.Equal(want) {
+			t.Errorf("DUE with TZID = %v, want %v", tasks[0].Due, want)
+		}
+	}
+	if tasks[0].UUID != "t1" {
+		t.Errorf("UUID = %q, want the UID t1", tasks[0].UUID)
+	}
+	if tasks[0].Description != "Com alarme" {
+		t.Errorf("VALARM properties leaked into the task: description %q", tasks[0].Description)
+	}
+	if !tasks[1].DeletedAt.Valid {
+		t.Errorf("CANCELLED task should be soft-deleted")
+	}
+	if tasks[2].Description != "Feita sem status" || !tasks[2].Done {
+		t.Errorf("task = %q done=%v, want unfolded summary and done", tasks[2].Description, tasks[2].Done)
+	}
+
+	for _, bad := range []string{
+		"BEGIN:VTODO\nUID:x\nEND:VTODO\n",
+		"BEGIN:VTODO\nSUMMARY:x\nSTATUS:ZUMBI\nEND:VTODO\n",
+		"BEGIN:VTODO\nSUMMARY:x\nDUE:amanhã\nEND:VTODO\n",
+		"BEGIN:VTODO\nSUMMARY:x\n",
+	} {
+		if _, err := (ICal{}).Import(strings.NewReader(bad)); err == nil {
+			t.Errorf("Import(%q) expected error, got nil", bad)
+		}
+	}
+}
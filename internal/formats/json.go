@@ -0,0 +1,238 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JSONVersion é a versão do formato JSON gravada na exportação. A versão 2
+// acrescentou o uuid; arquivos da versão 1 continuam sendo lidos, e as
+// tarefas ganham um UUID novo ao serem importadas. A versão 3 acrescentou o
+// owner_id.
+const JSONVersion = 3
+
+// JSON é o formato nativo do togo: guarda todos os campos de schema.Task,
+// inclusive ID, timestamps e tarefas apagadas (soft delete), para mover uma
+// lista entre máquinas sem perder nada.
+type JSON struct{}
+
+func init() {
+	Register(JSON{})
+}
+
+func (JSON) Name() string { return "json" }
+
+func (JSON) Extensions() []string { return []string{".json"} }
+
+// Complete indica que o formato carrega o banco inteiro; ver Archive.
+func (JSON) Complete() {}
+
+// jsonFile é o documento exportado.
+type jsonFile struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Tasks      []Record  `json:"tasks"`
+}
+
+// Record espelha schema.Task com nomes estáveis em snake_case. É o registro
+// do formato json e dos arquivos do togo sync.
+type Record struct {
+	ID          uint                `json:"id"`
+	UUID        string              `json:"uuid,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	DeletedAt   *time.Time          `json:"deleted_at"`
+	Description string              `json:"description"`
+	Done        bool                `json:"done"`
+	DoneAt      *time.Time          `json:"done_at"`
+	Priority    string              `json:"priority,omitempty"`
+	Projects    []string            `json:"projects,omitempty"`
+	Contexts    []string            `json:"contexts,omitempty"`
+	Extras      map[string]string   `json:"extras,omitempty"`
+	Due         *time.Time          `json:"due,omitempty"`
+	Scheduled   *time.Time          `json:"scheduled,omitempty"`
+	Recurrence  string              `json:"recurrence,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Annotations []schema.Annotation `json:"annotations,omitempty"`
+	Fingerprint string              `json:"fingerprint,omitempty"`
+	OwnerID     *uint               `json:"owner_id,omitempty"`
+}
+
+func (JSON) Export(w io.Writer, tasks []schema.Task) error {
+	out := jsonFile{Format: "togo", Version: JSONVersion, ExportedAt: time.Now(), Tasks: make([]Record, 0, len(tasks))}
+	for _, t := range tasks {
+		out.Tasks = append(out.Tasks, NewRecord(t))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Import lê um documento exportado (ou só o array de tarefas) e valida
+// todos os registros, retornando todos os problemas encontrados de uma vez.
+func (JSON) Import(r io.Reader) ([]schema.Task, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc jsonFile
+	trimmed := bytes.TrimSpace(raw)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		err = decodeStrict(trimmed, &doc.Tasks)
+	} else {
+		err = decodeStrict(trimmed, &doc)
+		if err == nil && doc.Format != "togo" {
+			err = fmt.Errorf("não é um arquivo JSON do togo (format = %q)", doc.Format)
+		}
+		if err == nil && doc.Version > JSONVersion {
+			err = fmt.Errorf("versão %d do arquivo é mais nova que a suportada (%d)", doc.Version, JSONVersion)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []error
+	seen := map[uint]int{}
+	seenUUID := map[string]int{}
+	tasks := make([]schema.Task, 0, len(doc.Tasks))
+	for i, jt := range doc.Tasks {
+		for _, problem := range jt.Validate() {
+			problems = append(problems, fmt.Errorf("tarefa %d (id %d): %s", i+1, jt.ID, problem))
+		}
+		if first, ok := seen[jt.ID]; ok {
+			problems = append(problems, fmt.Errorf("tarefa %d (id %d): ID repetido, igual ao da tarefa %d", i+1, jt.ID, first))
+		}
+		seen[jt.ID] = i + 1
+		if first, ok := seenUUID[jt.UUID]; ok && jt.UUID != "" {
+			problems = append(problems, fmt.Errorf("tarefa %d (id %d): UUID repetido, igual ao da tarefa %d", i+1, jt.ID, first))
+		}
+		seenUUID[jt.UUID] = i + 1
+		tasks = append(tasks, jt.Task())
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return tasks, nil
+}
+
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+	return nil
+}
+
+// Validate retorna os problemas do registro; vazio se ele for válido.
+func (r Record) Validate() []string {
+	var problems []string
+	if r.ID == 0 {
+		problems = append(problems, "id ausente")
+	}
+	if strings.TrimSpace(r.Description) == "" {
+		problems = append(problems, "a descrição não pode estar vazia")
+	}
+	if r.CreatedAt.IsZero() {
+		problems = append(problems, "created_at ausente")
+	}
+	if r.DoneAt != nil && !r.Done {
+		problems = append(problems, "done_at preenchido em tarefa não concluída")
+	}
+	if p := r.Priority; p != "" && (len(p) != 1 || p[0] < 'A' || p[0] > 'Z') {
+		problems = append(problems, fmt.Sprintf("prioridade inválida '%s' (use uma letra de A a Z)", p))
+	}
+	return problems
+}
+
+// NewRecord converte uma tarefa em Record.
+func NewRecord(t schema.Task) Record {
+	r := Record{
+		ID:          t.ID,
+		UUID:        t.UUID,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		Description: t.Description,
+		Done:        t.Done,
+		DoneAt:      t.DoneAt,
+		Priority:    t.Priority,
+		Projects:    t.Projects,
+		Contexts:    t.Contexts,
+		Extras:      t.Extras,
+		Due:         t.Due,
+		Scheduled:   t.Scheduled,
+		Recurrence:  t.Recurrence,
+		Tags:        t.Tags,
+		Annotations: t.Annotations,
+		Fingerprint: t.Fingerprint,
+		OwnerID:     t.OwnerID,
+	}
+	if t.DeletedAt.Valid {
+		deleted := t.DeletedAt.Time
+		r.DeletedAt = &deleted
+	}
+	return r
+}
+
+// UTC retorna o registro com todas as datas em UTC, para que o mesmo
+// instante gere sempre o mesmo JSON, em qualquer máquina.
+func (r Record) UTC() Record {
+	r.CreatedAt = r.CreatedAt.UTC()
+	r.UpdatedAt = r.UpdatedAt.UTC()
+	r.DeletedAt = inUTC(r.DeletedAt)
+	r.DoneAt = inUTC(r.DoneAt)
+	r.Due = inUTC(r.Due)
+	r.Scheduled = inUTC(r.Scheduled)
+	if len(r.Annotations) > 0 {
+		r.Annotations = append(r.Annotations[:0:0], r.Annotations...)
+		for i := range r.Annotations {
+			r.Annotations[i].Entry = r.Annotations[i].Entry.UTC()
+		}
+	}
+	return r
+}
+
+func inUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// Task converte o registro de volta em tarefa.
+func (r Record) Task() schema.Task {
+	t := schema.Task{
+		Model:       gorm.Model{ID: r.ID, CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt},
+		UUID:        r.UUID,
+		Description: r.Description,
+		Done:        r.Done,
+		DoneAt:      r.DoneAt,
+		Priority:    r.Priority,
+		Projects:    r.Projects,
+		Contexts:    r.Contexts,
+		Extras:      r.Extras,
+		Due:         r.Due,
+		Scheduled:   r.Scheduled,
+		Recurrence:  r.Recurrence,
+		Tags:        r.Tags,
+		Annotations: r.Annotations,
+		Fingerprint: r.Fingerprint,
+		OwnerID:     r.OwnerID,
+	}
+	if r.DeletedAt != nil {
+		t.DeletedAt = gorm.DeletedAt{Time: *r.DeletedAt, Valid: true}
+	}
+	return t
+}
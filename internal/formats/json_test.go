@@ -0,0 +1,90 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+
+	"gorm.io/gorm"
+)
+
+// TestJSONRoundTrip tests that every task field, including soft delete, survives export and import
+func TestJSONRoundTrip(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := created.Add(48 * time.Hour)
+	owner := uint(2)
+	tasks := []schema.Task{
+		{
+			Model:       gorm.Model{ID: 3, CreatedAt: created, UpdatedAt: later},
+			Description: "Completa",
+			Done:        true,
+			DoneAt:      &later,
+			Priority:    "B",
+			Projects:    []string{"togo"},
+			Contexts:    []string{"casa"},
+			Extras:      map[string]string{"uuid": "u1"},
+			Due:         &later,
+			Scheduled:   &created,
+			Recurrence:  "FREQ=DAILY",
+			Tags:        []string{"a"},
+			Annotations: []schema.Annotation{{Entry: created, Description: "nota"}},
+			Fingerprint: "abc123",
+			OwnerID:     &owner,
+		},
+		{
+			Model:       gorm.Model{ID: 7, CreatedAt: created, UpdatedAt: later, DeletedAt: gorm.DeletedAt{Time: later, Valid: true}},
+			Description: "Apagada",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (JSON{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	again, err := (JSON{}).Import(&buf)
+	if err != nil {
+		t.Fatalf("Import() of exported data unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, tasks) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", again, tasks)
+	}
+}
+
+// TestJSONImportValidation tests that all invalid records are reported together
+func TestJSONImportValidation(t *testing.T) {
+	input := `[
+{"id": 1, "created_at": "2025-01-01T12:00:00Z", "description": "ok"},
+{"id": 0, "created_at": "2025-01-01T12:00:00Z", "description": "sem id"},
+{"id": 2, "created_at": "2025-01-01T12:00:00Z", "description": " ", "priority": "AA"},
+{"id": 1, "created_at": "2025-01-01T12:00:00Z", "description": "repetida", "done_at": "2025-01-02T12:00:00Z"}
+]`
+	_, err := (JSON{}).Import(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("Import() with invalid records expected error, got nil")
+	}
+	for _, want := range []string{
+		"tarefa 2 (id 0): id ausente",
+		"tarefa 3 (id 2): a descrição não pode estar vazia",
+		"prioridade inválida 'AA'",
+		"tarefa 4 (id 1): done_at preenchido",
+		"ID repetido, igual ao da tarefa 1",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Import() error missing %q:\n%v", want, err)
+		}
+	}
+
+	for _, bad := range []string{
+		`{"format": "outro", "tasks": []}`,
+		`{"format": "togo", "version": 99, "tasks": []}`,
+		`[{"id": 1, "created_at": "2025-01-01T12:00:00Z", "description": "x", "campo": 1}]`,
+	} {
+		if _, err := (JSON{}).Import(strings.NewReader(bad)); err == nil {
+			t.Errorf("Import(%s) expected error, got nil", bad)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NoProject é o título da seção de tarefas sem projeto na exportação.
+const NoProject = "Sem projeto"
+
+// Markdown implementa listas de tarefas do Markdown (- [ ] item).
+//
+// A exportação agrupa as tarefas por projeto (o primeiro de Projects), em
+// seções "## projeto", e marca cada item com <!-- togo:UUID -->. Import lê
+// uma tarefa de cada item não marcado ([ ]) que ainda não tem marcador; o
+// togo import não o usa, e sim o sync-md, que grava os marcadores de volta
+// no arquivo e reconcilia os itens que já os têm.
+type Markdown struct{}
+
+func init() {
+	Register(Markdown{})
+}
+
+func (Markdown) Name() string { return "markdown" }
+
+func (Markdown) Extensions() []string { return []string{".md", ".markdown"} }
+
+func (Markdown) Export(w io.Writer, tasks []schema.Task) error {
+	groups := map[string][]schema.Task{}
+	for _, t := range tasks {
+		project := NoProject
+		if len(t.Projects) > 0 {
+			project = t.Projects[0]
+		}
+		groups[project] = append(groups[project], t)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != NoProject {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[NoProject]; ok {
+		names = append([]string{NoProject}, names...)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Tarefas")
+	for _, name := range names {
+		fmt.Fprintf(bw, "\n## %s\n\n", name)
+		for _, t := range groups[name] {
+			item := ChecklistItem{Bullet: "-", Checked: t.Done, Text: t.Description, Ref: t.UUID}
+			fmt.Fprintln(bw, item.String())
+		}
+	}
+	return bw.Flush()
+}
+
+func (Markdown) Import(r io.Reader) ([]schema.Task, error) {
+	var tasks []schema.Task
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		item, ok := ParseChecklistItem(scanner.Text())
+		if !ok || item.Checked || item.Ref != "" {
+			continue
+		}
+		tasks = append(tasks, schema.Task{Description: item.Text})
+	}
+	return tasks, scanner.Err()
+}
+
+// ChecklistItem é uma linha "- [ ] texto" de uma lista de tarefas, com a
+// referência à tarefa do togo quando a linha tem o marcador
+// <!-- togo:UUID -->. Arquivos de versões anteriores usam o ID numérico no
+// lugar do UUID.
+type ChecklistItem struct {
+	Indent  string
+	Bullet  string
+	Checked bool
+	Text    string
+	Ref     string
+}
+
+var (
+	checklistPattern = regexp.MustCompile(`^(\s*)([-*+]) \[([ xX])\] (.*)$`)
+	markerPattern    = regexp.MustCompile(`\s*<!--\s*togo:([^\s>]+?)\s*-->\s*$`)
+)
+
+// ParseChecklistItem reconhece um item de lista de tarefas. ok é falso para
+// qualquer outra linha, inclusive itens com texto vazio.
+func ParseChecklistItem(line string) (item ChecklistItem, ok bool) {
+	m := checklistPattern.FindStringSubmatch(line)
+	if m == nil {
+		return item, false
+	}
+	item = ChecklistItem{Indent: m[1], Bullet: m[2], Checked: m[3] != " ", Text: m[4]}
+	if marker := markerPattern.FindStringSubmatch(item.Text); marker != nil {
+		item.Ref = marker[1]
+		item.Text = strings.TrimSuffix(item.Text, marker[0])
+	}
+	item.Text = strings.TrimSpace(item.Text)
+	return item, item.Text != ""
+}
+
+// String monta a linha do item, com o marcador se Ref não for vazio.
+func (i ChecklistItem) String() string {
+	box := "[ ]"
+	if i.Checked {
+		box = "[x]"
+	}
+	line := fmt.Sprintf("%s%s %s %s", i.Indent, i.Bullet, box, i.Text)
+	if i.Ref != "" {
+		line += " <!-- togo:" + i.Ref + " -->"
+	}
+	return line
+}
@@ -0,0 +1,96 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"levyvix/togo/schema"
+)
+
+// TestMarkdownExport tests grouping tasks by project with UUID markers
+func TestMarkdownExport(t *testing.T) {
+	tasks := []schema.Task{
+		{Description: "Revisar PR", Projects: []string{"togo"}},
+		{Description: "Comprar pão", Done: true},
+		{Description: "Pagar conta", Projects: []string{"casa", "financas"}},
+	}
+	for i := range tasks {
+		tasks[i].UUID = fmt.Sprintf("0000000%d-aaaa-4bbb-8ccc-dddddddddddd", i+1)
+	}
+
+	var buf bytes.Buffer
+	if err := (Markdown{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	want := `# Tarefas
+
+## Sem projeto
+
+- [x] Comprar pão <!-- togo:00000002-aaaa-4bbb-8ccc-dddddddddddd -->
+
+## casa
+
+- [ ] Pagar conta <!-- togo:00000003-aaaa-4bbb-8ccc-dddddddddddd -->
+
+## togo
+
+- [ ] Revisar PR <!-- togo:00000001-aaaa-4bbb-8ccc-dddddddddddd -->
+`
+	if buf.String() != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// TestParseChecklistItem tests recognizing checklist lines and markers
+func TestParseChecklistItem(t *testing.T) {
+	tests := []struct {
+		line string
+		want ChecklistItem
+		ok   bool
+	}{
+		{line: "- [ ] Comprar pão", want: ChecklistItem{Bullet: "-", Text: "Comprar pão"}, ok: true},
+		{line: "  * [X] Feito <!-- togo:5b0e7c1a-7f7d-4b7e-9a43-2c9d1e6f0a11 -->", want: ChecklistItem{Indent: "  ", Bullet: "*", Checked: true, Text: "Feito", Ref: "5b0e7c1a-7f7d-4b7e-9a43-2c9d1e6f0a11"}, ok: true},
+		{line: "+ [x] Sem espaço<!--togo:3-->", want: ChecklistItem{Bullet: "+", Checked: true, Text: "Sem espaço", Ref: "3"}, ok: true},
+		{line: "- item comum"},
+		{line: "- [ ] "},
+		{line: "[ ] sem marcador de lista"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := ParseChecklistItem(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ParseChecklistItem(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			}
+			if ok && got != tt.want {
+				t.Errorf("ParseChecklistItem(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMarkdownImport tests that only new unchecked items become tasks
+func TestMarkdownImport(t *testing.T) {
+	input := `# Reunião
+
+Decidimos:
+
+- [ ] Enviar ata
+- [x] Reservar sala
+- [ ] Já no togo <!-- togo:4 -->
+  - [ ] Subitem
+- ponto sem checkbox
+`
+	tasks, err := (Markdown{}).Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	var got []string
+	for _, task := range tasks {
+		got = append(got, task.Description)
+	}
+	if strings.Join(got, "|") != "Enviar ata|Subitem" {
+		t.Errorf("Import() = %v, want [Enviar ata Subitem]", got)
+	}
+}
@@ -0,0 +1,291 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	orgDate     = "2006-01-02 Mon"
+	orgDateTime = "2006-01-02 Mon 15:04"
+	// orgIDProperty é a propriedade do org-id, que identifica uma headline
+	// por um UUID; guarda o UUID da tarefa.
+	orgIDProperty = "ID"
+)
+
+// Org implementa headlines TODO/DONE do org-mode do Emacs.
+//
+// Mapeamento:
+//   - TODO/DONE ↔ Done, [#A] ↔ Priority
+//   - CLOSED ↔ DoneAt, SCHEDULED ↔ Scheduled, DEADLINE ↔ Due
+//   - tags ↔ Tags; tags começando com "@" ↔ Contexts
+//   - propriedades ID ↔ UUID, CREATED ↔ CreatedAt e PROJECTS ↔ Projects
+//     (separados por espaço); as demais propriedades ↔ Extras
+//
+// Headlines sem TODO/DONE (seções, notas) são ignoradas na importação. Como
+// ID identifica a tarefa, importar um arquivo exportado atualiza as tarefas
+// em vez de duplicá-las.
+type Org struct{}
+
+func init() {
+	Register(Org{})
+}
+
+func (Org) Name() string { return "org" }
+
+func (Org) Extensions() []string { return []string{".org"} }
+
+// Key retorna o UUID da tarefa, que vai para a propriedade ID.
+func (Org) Key(t schema.Task) string {
+	return t.UUID
+}
+
+func (Org) Fields() []string {
+	return []string{"Description", "Done", "DoneAt", "Due", "Scheduled", "Priority", "Tags", "Contexts", "Projects", "Extras"}
+}
+
+func (o Org) Export(w io.Writer, tasks []schema.Task) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tasks {
+		fmt.Fprintln(bw, orgHeadline(t))
+
+		var planning []string
+		if t.Done && t.DoneAt != nil {
+			planning = append(planning, "CLOSED: "+formatOrgTime(*t.DoneAt, '[', ']'))
+		}
+		if t.Scheduled != nil {
+			planning = append(planning, "SCHEDULED: "+formatOrgTime(*t.Scheduled, '<', '>'))
+		}
+		if t.Due != nil {
+			planning = append(planning, "DEADLINE: "+formatOrgTime(*t.Due, '<', '>'))
+		}
+		if len(planning) > 0 {
+			fmt.Fprintln(bw, "  "+strings.Join(planning, " "))
+		}
+
+		fmt.Fprintln(bw, "  :PROPERTIES:")
+		if key := o.Key(t); key != "" {
+			fmt.Fprintf(bw, "  :%s: %s\n", orgIDProperty, key)
+		}
+		if !t.CreatedAt.IsZero() {
+			fmt.Fprintf(bw, "  :CREATED: %s\n", formatOrgTime(t.CreatedAt, '[', ']'))
+		}
+		if len(t.Projects) > 0 {
+			fmt.Fprintf(bw, "  :PROJECTS: %s\n", strings.Join(t.Projects, " "))
+		}
+		keys := make([]string, 0, len(t.Extras))
+		for k := range t.Extras {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(bw, "  :%s: %s\n", k, t.Extras[k])
+		}
+		fmt.Fprintln(bw, "  :END:")
+	}
+	return bw.Flush()
+}
+
+func orgHeadline(t schema.Task) string {
+	parts := []string{"*", "TODO"}
+	if t.Done {
+		parts[1] = "DONE"
+	}
+	if t.Priority != "" {
+		parts = append(parts, "[#"+t.Priority+"]")
+	}
+	parts = append(parts, t.Description)
+
+	var tags []string
+	tags = append(tags, t.Tags...)
+	for _, c := range t.Contexts {
+		tags = append(tags, "@"+c)
+	}
+	if len(tags) > 0 {
+		parts = append(parts, ":"+strings.Join(tags, ":")+":")
+	}
+	return strings.Join(parts, " ")
+}
+
+var (
+	orgHeadlinePattern = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgKeywordPattern  = regexp.MustCompile(`^(TODO|DONE)(?:\s+(.*))?$`)
+	orgPriorityPattern = regexp.MustCompile(`^\[#([A-Z])\]\s*`)
+	orgTagsPattern     = regexp.MustCompile(`\s+(:[\w@#%:]+:)\s*$`)
+	orgPlanningPattern = regexp.MustCompile(`(CLOSED|SCHEDULED|DEADLINE):\s*([<\[][^>\]]*[>\]])`)
+	orgPropertyPattern = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+	orgTimePattern     = regexp.MustCompile(`^[<\[](\d{4}-\d{2}-\d{2})(?:\s+[^\s\d>\]]+)?(?:\s+(\d{1,2}:\d{2}))?[^>\]]*[>\]]$`)
+)
+
+func (Org) Import(r io.Reader) ([]schema.Task, error) {
+	var tasks []schema.Task
+	var current *schema.Task
+	inDrawer := false
+	// planning indica se a próxima linha ainda pode ser a de CLOSED/SCHEDULED/DEADLINE.
+	planning := false
+
+	finish := func() {
+		if current != nil {
+			tasks = append(tasks, *current)
+			current = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if m := orgHeadlinePattern.FindStringSubmatch(line); m != nil {
+			finish()
+			inDrawer, planning = false, false
+			t, ok, err := parseOrgHeadline(m[2])
+			if err != nil {
+				return nil, fmt.Errorf("linha %d: %w", lineNo, err)
+			}
+			if ok {
+				current = &t
+				planning = true
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		var err error
+		switch {
+		case inDrawer && strings.EqualFold(trimmed, ":END:"):
+			inDrawer = false
+		case inDrawer:
+			err = setOrgProperty(current, trimmed)
+		case strings.EqualFold(trimmed, ":PROPERTIES:"):
+			inDrawer, planning = true, false
+		case planning && orgPlanningPattern.MatchString(trimmed):
+			err = setOrgPlanning(current, trimmed)
+			planning = false
+		default:
+			planning = false
+		}
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", lineNo, err)
+		}
+	}
+	finish()
+	return tasks, scanner.Err()
+}
+
+// parseOrgHeadline interpreta o texto depois dos asteriscos. ok é falso
+// para headlines sem TODO/DONE.
+func parseOrgHeadline(text string) (t schema.Task, ok bool, err error) {
+	m := orgKeywordPattern.FindStringSubmatch(text)
+	if m == nil {
+		return t, false, nil
+	}
+	t.Done = m[1] == "DONE"
+	rest := m[2]
+
+	if p := orgPriorityPattern.FindStringSubmatch(rest); p != nil {
+		t.Priority = p[1]
+		rest = rest[len(p[0]):]
+	}
+	if tags := orgTagsPattern.FindStringSubmatch(rest); tags != nil {
+		for _, tag := range strings.Split(strings.Trim(tags[1], ":"), ":") {
+			switch {
+			case tag == "":
+			case len(tag) > 1 && tag[0] == '@':
+				t.Contexts = append(t.Contexts, tag[1:])
+			default:
+				t.Tags = append(t.Tags, tag)
+			}
+		}
+		rest = rest[:len(rest)-len(tags[0])]
+	}
+
+	t.Description = strings.TrimSpace(rest)
+	if t.Description == "" {
+		return t, false, fmt.Errorf("a descrição não pode estar vazia")
+	}
+	return t, true, nil
+}
+
+func setOrgPlanning(t *schema.Task, line string) error {
+	for _, m := range orgPlanningPattern.FindAllStringSubmatch(line, -1) {
+		when, err := parseOrgTime(m[2])
+		if err != nil {
+			return err
+		}
+		switch m[1] {
+		case "CLOSED":
+			t.DoneAt = &when
+		case "SCHEDULED":
+			t.Scheduled = &when
+		case "DEADLINE":
+			t.Due = &when
+		}
+	}
+	if !t.Done {
+		t.DoneAt = nil
+	}
+	return nil
+}
+
+func setOrgProperty(t *schema.Task, line string) error {
+	m := orgPropertyPattern.FindStringSubmatch(line)
+	if m == nil {
+		return fmt.Errorf("propriedade inválida '%s'", line)
+	}
+	key, value := m[1], strings.TrimSpace(m[2])
+	switch strings.ToUpper(key) {
+	case orgIDProperty:
+		t.UUID = value
+	case "CREATED":
+		created, err := parseOrgTime(value)
+		if err != nil {
+			return err
+		}
+		t.CreatedAt = created
+	case "PROJECTS":
+		t.Projects = strings.Fields(value)
+	default:
+		setExtra(t, key, value)
+	}
+	return nil
+}
+
+// formatOrgTime usa só a data quando a hora é meia-noite, que é como o
+// togo guarda datas sem hora.
+func formatOrgTime(t time.Time, open, close byte) string {
+	local := t.Local()
+	layout := orgDateTime
+	if local.Hour() == 0 && local.Minute() == 0 {
+		layout = orgDate
+	}
+	return string(open) + local.Format(layout) + string(close)
+}
+
+// parseOrgTime aceita <2025-01-05 Sun>, [2025-01-05 Sun 09:30] e variações
+// sem o dia da semana ou com repetidor (+1w), que é ignorado.
+func parseOrgTime(s string) (time.Time, error) {
+	m := orgTimePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return time.Time{}, fmt.Errorf("data inválida '%s'", s)
+	}
+	value, layout := m[1], "2006-01-02"
+	if m[2] != "" {
+		value, layout = m[1]+" "+m[2], "2006-01-02 15:04"
+	}
+	t, err := time.ParseInLocation(layout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("data inválida '%s'", s)
+	}
+	return t, nil
+}
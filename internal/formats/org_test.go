@@ -0,0 +1,119 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+)
+
+func localTime(year int, month time.Month, day, hour, min int) *time.Time {
+	t := time.Date(year, month, day, hour, min, 0, 0, time.Local)
+	return &t
+}
+
+// TestOrgImport tests parsing headlines, planning lines and property drawers
+func TestOrgImport(t *testing.T) {
+	input := `#+TITLE: Tarefas
+
+* Trabalho
+** TODO [#A] Revisar PR :review:@escritorio:
+   SCHEDULED: <2025-01-05 Sun> DEADLINE: <2025-01-10 Fri 17:00 +1w>
+   :PROPERTIES:
+   :ID: 9e1f2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b
+   :CREATED: [2025-01-01 Wed 09:30]
+   :PROJECTS: togo site
+   :Effort: 2h
+   :END:
+   Corpo livre da headline, ignorado.
+** DONE Fazer compras
+   CLOSED: [2025-01-03 Fri 18:00]
+** Nota sem palavra-chave
+* TODO Sem nada
+`
+	tasks, err := (Org{}).Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("Import() returned %d tasks, want 3", len(tasks))
+	}
+
+	want := schema.Task{
+		Description: "Revisar PR",
+		Priority:    "A",
+		Tags:        []string{"review"},
+		Contexts:    []string{"escritorio"},
+		Projects:    []string{"togo", "site"},
+		Scheduled:   localTime(2025, 1, 5, 0, 0),
+		Due:         localTime(2025, 1, 10, 17, 0),
+		Extras:      map[string]string{"Effort": "2h"},
+	}
+	want.UUID = "9e1f2a3b-4c5d-4e6f-8a9b-0c1d2e3f4a5b"
+	want.CreatedAt = *localTime(2025, 1, 1, 9, 30)
+	if !reflect.DeepEqual(tasks[0], want) {
+		t.Errorf("first task =\n%+v\nwant\n%+v", tasks[0], want)
+	}
+
+	done := tasks[1]
+	if !done.Done || done.DoneAt == nil || !done.DoneAt.Equal(*localTime(2025, 1, 3, 18, 0)) {
+		t.Errorf("DONE task Done/DoneAt = %v/%v, want true/2025-01-03 18:00", done.Done, done.DoneAt)
+	}
+	if tasks[2].Description != "Sem nada" || tasks[2].Done {
+		t.Errorf("third task = %+v, want pending 'Sem nada'", tasks[2])
+	}
+
+	for _, bad := range []string{
+		"* TODO\n",
+		"* TODO x\n  DEADLINE: <amanhã>\n",
+		"* TODO x\n  :PROPERTIES:\n  sem dois-pontos\n  :END:\n",
+	} {
+		if _, err := (Org{}).Import(strings.NewReader(bad)); err == nil {
+			t.Errorf("Import(%q) expected error, got nil", bad)
+		}
+	}
+}
+
+// TestOrgRoundTrip tests that importing an export gives back the same tasks
+func TestOrgRoundTrip(t *testing.T) {
+	tasks := []schema.Task{
+		{
+			Model:       gormModel(*localTime(2025, 1, 1, 9, 30)),
+			Description: "Revisar PR",
+			Priority:    "B",
+			Tags:        []string{"review"},
+			Contexts:    []string{"casa"},
+			Projects:    []string{"togo"},
+			Scheduled:   localTime(2025, 1, 5, 0, 0),
+			Due:         localTime(2025, 1, 10, 17, 0),
+			Extras:      map[string]string{"Effort": "2h"},
+			UUID:        "u1",
+		},
+		{
+			Model:       gormModel(*localTime(2024, 12, 1, 0, 0)),
+			Description: "Fazer compras",
+			Done:        true,
+			DoneAt:      localTime(2025, 1, 3, 18, 0),
+			UUID:        "u2",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (Org{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "* TODO [#B] Revisar PR :review:@casa:\n  SCHEDULED: <2025-01-05 Sun> DEADLINE: <2025-01-10 Fri 17:00>\n") {
+		t.Errorf("Export() =\n%s", buf.String())
+	}
+
+	again, err := (Org{}).Import(&buf)
+	if err != nil {
+		t.Fatalf("Import() of exported data unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, tasks) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", again, tasks)
+	}
+}
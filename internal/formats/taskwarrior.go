@@ -0,0 +1,360 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// taskwarriorDate é o formato de data do Taskwarrior (ISO 8601 básico, UTC).
+const taskwarriorDate = "20060102T150405Z"
+
+// Taskwarrior implementa o formato JSON de "task export" / "task import".
+//
+// Mapeamento:
+//   - uuid ↔ UUID
+//   - status pending/waiting ↔ pendente, completed ↔ Done, deleted ↔ soft delete
+//   - entry ↔ CreatedAt, modified ↔ UpdatedAt, end ↔ DoneAt (ou DeletedAt)
+//   - due ↔ Due, project ↔ primeiro item de Projects
+//   - tags ↔ Tags; tags começando com "@" ↔ Contexts
+//   - priority H/M/L ↔ A/B/C
+//   - annotations ↔ Annotations
+//
+// Demais atributos (UDAs, wait, recur...) vão para Extras como texto e são
+// exportados de volta como atributos de primeiro nível.
+//
+// Como o uuid identifica a tarefa, importar de novo um export atualiza as
+// tarefas em vez de duplicá-las.
+type Taskwarrior struct{}
+
+func init() {
+	Register(Taskwarrior{})
+}
+
+func (Taskwarrior) Name() string { return "taskwarrior" }
+
+// Extensions é vazio: o .json é do formato nativo do togo, então o
+// Taskwarrior precisa ser escolhido com --format.
+func (Taskwarrior) Extensions() []string { return nil }
+
+// Key retorna o uuid da tarefa.
+func (Taskwarrior) Key(t schema.Task) string {
+	return t.UUID
+}
+
+// Fields são os campos carregados por uma tarefa do Taskwarrior.
+func (Taskwarrior) Fields() []string {
+	return []string{"Description", "Done", "DoneAt", "Due", "Priority", "Projects", "Tags", "Contexts", "Annotations", "Extras"}
+}
+
+// twTask é uma tarefa do Taskwarrior. Atributos desconhecidos ficam em Extra.
+type twTask struct {
+	UUID        string            `json:"uuid,omitempty"`
+	Status      string            `json:"status"`
+	Description string            `json:"description"`
+	Entry       string            `json:"entry,omitempty"`
+	Modified    string            `json:"modified,omitempty"`
+	End         string            `json:"end,omitempty"`
+	Due         string            `json:"due,omitempty"`
+	Project     string            `json:"project,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+	Annotations []twAnnotation    `json:"annotations,omitempty"`
+	Extra       map[string]string `json:"-"`
+}
+
+type twAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// twKnown são os atributos com campo próprio em twTask, ou calculados pelo
+// Taskwarrior (id, urgency) e que não devem ir para Extras.
+var twKnown = map[string]bool{
+	"uuid": true, "status": true, "description": true, "entry": true,
+	"modified": true, "end": true, "due": true, "project": true, "tags": true,
+	"priority": true, "annotations": true, "id": true, "urgency": true,
+}
+
+func (Taskwarrior) Export(w io.Writer, tasks []schema.Task) error {
+	out := make([]map[string]any, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, toTaskwarrior(t).toMap())
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func (Taskwarrior) Import(r io.Reader) ([]schema.Task, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := decodeTaskwarrior(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]schema.Task, 0, len(objects))
+	for i, obj := range objects {
+		tw, err := twFromMap(obj)
+		if err != nil {
+			return nil, fmt.Errorf("tarefa %d: %w", i+1, err)
+		}
+		t, err := fromTaskwarrior(tw)
+		if err != nil {
+			return nil, fmt.Errorf("tarefa %d (%s): %w", i+1, tw.UUID, err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+// decodeTaskwarrior aceita tanto um array JSON (task export atual) quanto um
+// objeto por linha (versões antigas).
+func decodeTaskwarrior(raw []byte) ([]map[string]any, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "[") {
+		var objects []map[string]any
+		if err := json.Unmarshal([]byte(trimmed), &objects); err != nil {
+			return nil, fmt.Errorf("JSON inválido: %w", err)
+		}
+		return objects, nil
+	}
+
+	var objects []map[string]any
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	for dec.More() {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("JSON inválido: %w", err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+func twFromMap(obj map[string]any) (twTask, error) {
+	var tw twTask
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return tw, err
+	}
+	if err := json.Unmarshal(data, &tw); err != nil {
+		return tw, fmt.Errorf("JSON inválido: %w", err)
+	}
+	for k, v := range obj {
+		if twKnown[k] {
+			continue
+		}
+		if tw.Extra == nil {
+			tw.Extra = map[string]string{}
+		}
+		switch v := v.(type) {
+		case string:
+			tw.Extra[k] = v
+		default:
+			encoded, _ := json.Marshal(v)
+			tw.Extra[k] = string(encoded)
+		}
+	}
+	return tw, nil
+}
+
+func (tw twTask) toMap() map[string]any {
+	m := map[string]any{}
+	for k, v := range tw.Extra {
+		m[k] = v
+	}
+	data, _ := json.Marshal(tw)
+	var known map[string]any
+	_ = json.Unmarshal(data, &known)
+	for k, v := range known {
+		m[k] = v
+	}
+	return m
+}
+
+func toTaskwarrior(t schema.Task) twTask {
+	tw := twTask{
+		Description: t.Description,
+		Status:      "pending",
+		Entry:       formatTWDate(t.CreatedAt),
+		Modified:    formatTWDate(t.UpdatedAt),
+		Priority:    twPriority(t.Priority),
+		UUID:        t.UUID,
+	}
+	if t.Done {
+		tw.Status = "completed"
+		if t.DoneAt != nil {
+			tw.End = formatTWDate(*t.DoneAt)
+		}
+	}
+	if t.DeletedAt.Valid {
+		tw.Status = "deleted"
+		tw.End = formatTWDate(t.DeletedAt.Time)
+	}
+	if s := t.Extras["status"]; tw.Status == "pending" && (s == "waiting" || s == "recurring") {
+		tw.Status = s
+	}
+	if t.Due != nil {
+		tw.Due = formatTWDate(*t.Due)
+	}
+	if len(t.Projects) > 0 {
+		tw.Project = t.Projects[0]
+	}
+	tw.Tags = append(tw.Tags, t.Tags...)
+	for _, c := range t.Contexts {
+		tw.Tags = append(tw.Tags, "@"+c)
+	}
+	for _, a := range t.Annotations {
+		tw.Annotations = append(tw.Annotations, twAnnotation{Entry: formatTWDate(a.Entry), Description: a.Description})
+	}
+
+	for k, v := range t.Extras {
+		if twKnown[k] {
+			continue
+		}
+		if tw.Extra == nil {
+			tw.Extra = map[string]string{}
+		}
+		tw.Extra[k] = v
+	}
+	return tw
+}
+
+func fromTaskwarrior(tw twTask) (schema.Task, error) {
+	t := schema.Task{Description: tw.Description, UUID: tw.UUID}
+	if strings.TrimSpace(t.Description) == "" {
+		return t, fmt.Errorf("a descrição não pode estar vazia")
+	}
+
+	var err error
+	if t.CreatedAt, err = parseTWDate(tw.Entry); err != nil {
+		return t, err
+	}
+	if t.UpdatedAt, err = parseTWDate(tw.Modified); err != nil {
+		return t, err
+	}
+	end, err := parseTWDate(tw.End)
+	if err != nil {
+		return t, err
+	}
+
+	switch tw.Status {
+	case "", "pending", "waiting", "recurring":
+	case "completed":
+		t.Done = true
+		if !end.IsZero() {
+			t.DoneAt = &end
+		}
+	case "deleted":
+		if end.IsZero() {
+			end = time.Now()
+		}
+		t.DeletedAt = gorm.DeletedAt{Time: end, Valid: true}
+	default:
+		return t, fmt.Errorf("status desconhecido '%s'", tw.Status)
+	}
+	if tw.Status == "waiting" || tw.Status == "recurring" {
+		setExtra(&t, "status", tw.Status)
+	}
+
+	if tw.Due != "" {
+		due, err := parseTWDate(tw.Due)
+		if err != nil {
+			return t, err
+		}
+		t.Due = &due
+	}
+	if tw.Project != "" {
+		t.Projects = []string{tw.Project}
+	}
+	for _, tag := range tw.Tags {
+		if len(tag) > 1 && tag[0] == '@' {
+			t.Contexts = append(t.Contexts, tag[1:])
+		} else {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	t.Priority = togoPriority(tw.Priority)
+	for _, a := range tw.Annotations {
+		entry, err := parseTWDate(a.Entry)
+		if err != nil {
+			return t, err
+		}
+		t.Annotations = append(t.Annotations, schema.Annotation{Entry: entry, Description: a.Description})
+	}
+
+	keys := make([]string, 0, len(tw.Extra))
+	for k := range tw.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		setExtra(&t, k, tw.Extra[k])
+	}
+	return t, nil
+}
+
+func setExtra(t *schema.Task, key, value string) {
+	if t.Extras == nil {
+		t.Extras = map[string]string{}
+	}
+	t.Extras[key] = value
+}
+
+func formatTWDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(taskwarriorDate)
+}
+
+func parseTWDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(taskwarriorDate, s)
+	if err != nil {
+		// Algumas versões exportam datas em RFC 3339.
+		if t, err2 := time.Parse(time.RFC3339, s); err2 == nil {
+			return t.Local(), nil
+		}
+		return time.Time{}, fmt.Errorf("data inválida '%s'", s)
+	}
+	return t.Local(), nil
+}
+
+// twPriority converte a prioridade do togo (A-Z) para H/M/L.
+func twPriority(p string) string {
+	switch {
+	case p == "":
+		return ""
+	case p == "A":
+		return "H"
+	case p == "B":
+		return "M"
+	default:
+		return "L"
+	}
+}
+
+func togoPriority(p string) string {
+	switch p {
+	case "H":
+		return "A"
+	case "M":
+		return "B"
+	case "L":
+		return "C"
+	}
+	return ""
+}
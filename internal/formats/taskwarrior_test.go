@@ -0,0 +1,106 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+)
+
+const taskwarriorExport = `[
+{"id":1,"description":"Revisar PR","entry":"20250101T120000Z","modified":"20250102T080000Z","due":"20250110T000000Z","project":"togo","priority":"H","status":"pending","tags":["review","@trabalho"],"uuid":"5f2c5a8e-8d8f-4a6b-9c61-2a8f0b7d8e11","urgency":12.3,"annotations":[{"entry":"20250101T130000Z","description":"pedir ajuda ao time"}],"estimate":"2h"},
+{"id":0,"description":"Fazer compras","end":"20250103T180000Z","entry":"20241230T090000Z","modified":"20250103T180000Z","status":"completed","uuid":"a3b0a8f4-1d3e-4c45-8f7a-0e6c1d2b3c44"},
+{"id":0,"description":"Tarefa apagada","end":"20250104T100000Z","entry":"20250101T100000Z","modified":"20250104T100000Z","status":"deleted","uuid":"0d4c2f7b-9a3e-4b6f-8c1d-5e7a9b0c1d22"}
+]`
+
+func utc(s string) time.Time {
+	t, err := time.Parse(taskwarriorDate, s)
+	if err != nil {
+		panic(err)
+	}
+	return t.Local()
+}
+
+// TestTaskwarriorImport tests mapping Taskwarrior attributes onto togo fields
+func TestTaskwarriorImport(t *testing.T) {
+	tasks, err := (Taskwarrior{}).Import(strings.NewReader(taskwarriorExport))
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("Import() returned %d tasks, want 3", len(tasks))
+	}
+
+	pending := tasks[0]
+	due := utc("20250110T000000Z")
+	want := schema.Task{
+		Description: "Revisar PR",
+		Priority:    "A",
+		Projects:    []string{"togo"},
+		Tags:        []string{"review"},
+		Contexts:    []string{"trabalho"},
+		Due:         &due,
+		Annotations: []schema.Annotation{{Entry: utc("20250101T130000Z"), Description: "pedir ajuda ao time"}},
+		Extras:      map[string]string{"estimate": "2h"},
+		UUID:        "5f2c5a8e-8d8f-4a6b-9c61-2a8f0b7d8e11",
+	}
+	want.CreatedAt = utc("20250101T120000Z")
+	want.UpdatedAt = utc("20250102T080000Z")
+	if !reflect.DeepEqual(pending, want) {
+		t.Errorf("pending task =\n%+v\nwant\n%+v", pending, want)
+	}
+
+	completed := tasks[1]
+	if !completed.Done || completed.DoneAt == nil || !completed.DoneAt.Equal(utc("20250103T180000Z")) {
+		t.Errorf("completed task Done/DoneAt = %v/%v, want true/2025-01-03 18:00 UTC", completed.Done, completed.DoneAt)
+	}
+
+	deleted := tasks[2]
+	if !deleted.DeletedAt.Valid || !deleted.DeletedAt.Time.Equal(utc("20250104T100000Z")) {
+		t.Errorf("deleted task DeletedAt = %+v, want 2025-01-04 10:00 UTC", deleted.DeletedAt)
+	}
+}
+
+// TestTaskwarriorRoundTrip tests that importing an export gives back the same tasks
+func TestTaskwarriorRoundTrip(t *testing.T) {
+	tasks, err := (Taskwarrior{}).Import(strings.NewReader(taskwarriorExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := (Taskwarrior{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	again, err := (Taskwarrior{}).Import(&buf)
+	if err != nil {
+		t.Fatalf("Import() of exported data unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, tasks) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", again, tasks)
+	}
+}
+
+// TestTaskwarriorImportLines tests the one-object-per-line format of older versions
+func TestTaskwarriorImportLines(t *testing.T) {
+	input := `{"description":"Primeira","entry":"20250101T120000Z","status":"pending","uuid":"u1"}
+{"description":"Segunda","entry":"20250101T120000Z","status":"waiting","wait":"20250201T000000Z","uuid":"u2"}
+`
+	tasks, err := (Taskwarrior{}).Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("Import() returned %d tasks, want 2", len(tasks))
+	}
+	if tasks[1].Extras["status"] != "waiting" || tasks[1].Extras["wait"] != "20250201T000000Z" {
+		t.Errorf("waiting task extras = %v, want status and wait", tasks[1].Extras)
+	}
+
+	if _, err := (Taskwarrior{}).Import(strings.NewReader(`[{"description":"x","status":"zumbi"}]`)); err == nil {
+		t.Errorf("Import() with unknown status expected error, got nil")
+	}
+}
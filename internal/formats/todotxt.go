@@ -0,0 +1,267 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"levyvix/togo/schema"
+	"sort"
+	"strings"
+	"time"
+)
+
+// todoTxtDate é o formato de data do todo.txt.
+const todoTxtDate = "2006-01-02"
+
+// TodoTxt implementa o formato todo.txt
+// (https://github.com/todotxt/todo.txt).
+//
+// Os tokens +projeto, @contexto e chave:valor são guardados em Projects,
+// Contexts e Extras. Os que ficam no meio do texto continuam também na
+// descrição, no mesmo lugar, e os do final da linha saem dela; na exportação
+// só voltam ao final da linha os tokens que a descrição ainda não tem, então
+// "Ler cap:3 do livro" não vira "Ler do livro cap:3". A prioridade de tarefas concluídas é exportada como
+// pri:X, como sugere a especificação, o prazo (Due) como due:AAAA-MM-DD e o
+// UUID como uuid:..., que identifica a tarefa ao importar o arquivo de novo.
+type TodoTxt struct{}
+
+func init() {
+	Register(TodoTxt{})
+}
+
+func (TodoTxt) Name() string { return "todotxt" }
+
+func (TodoTxt) Extensions() []string { return []string{".txt", ".todo"} }
+
+// Key retorna o UUID da tarefa (o token uuid:).
+func (TodoTxt) Key(t schema.Task) string {
+	return t.UUID
+}
+
+// Fields são os campos carregados por uma linha todo.txt.
+func (TodoTxt) Fields() []string {
+	return []string{"Description", "Done", "DoneAt", "Priority", "Projects", "Contexts", "Extras", "Due"}
+}
+
+func (TodoTxt) Export(w io.Writer, tasks []schema.Task) error {
+	for _, t := range tasks {
+		if _, err := fmt.Fprintln(w, FormatTodoTxt(t)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (TodoTxt) Import(r io.Reader) ([]schema.Task, error) {
+	var tasks []schema.Task
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		t, err := ParseTodoTxt(line)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", lineNo, err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, scanner.Err()
+}
+
+// FormatTodoTxt converte uma tarefa em uma linha todo.txt.
+func FormatTodoTxt(t schema.Task) string {
+	var parts []string
+	extras := make(map[string]string, len(t.Extras)+1)
+	for k, v := range t.Extras {
+		extras[k] = v
+	}
+
+	if t.Done {
+		parts = append(parts, "x")
+		// A data de criação só pode aparecer depois da data de conclusão.
+		if t.DoneAt != nil {
+			parts = append(parts, t.DoneAt.Format(todoTxtDate))
+			if !t.CreatedAt.IsZero() {
+				parts = append(parts, t.CreatedAt.Format(todoTxtDate))
+			}
+		}
+		if t.Priority != "" {
+			extras["pri"] = t.Priority
+		}
+	} else {
+		if t.Priority != "" {
+			parts = append(parts, "("+t.Priority+")")
+		}
+		if !t.CreatedAt.IsZero() {
+			parts = append(parts, t.CreatedAt.Format(todoTxtDate))
+		}
+	}
+
+	if t.Due != nil {
+		extras["due"] = t.Due.Format(todoTxtDate)
+	}
+	if t.UUID != "" {
+		extras["uuid"] = t.UUID
+	}
+
+	// Tokens que já aparecem na descrição não são repetidos.
+	inline := map[string]bool{}
+	if t.Description != "" {
+		parts = append(parts, t.Description)
+		for _, w := range strings.Fields(t.Description) {
+			inline[w] = true
+		}
+	}
+	add := func(tok string) {
+		if !inline[tok] {
+			parts = append(parts, tok)
+		}
+	}
+	for _, p := range t.Projects {
+		add("+" + p)
+	}
+	for _, c := range t.Contexts {
+		add("@" + c)
+	}
+	keys := make([]string, 0, len(extras))
+	for k := range extras {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		add(k + ":" + extras[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseTodoTxt converte uma linha todo.txt em uma tarefa. As datas são
+// interpretadas no fuso horário local, à meia-noite.
+func ParseTodoTxt(line string) (schema.Task, error) {
+	var t schema.Task
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return t, fmt.Errorf("linha vazia")
+	}
+
+	if tokens[0] == "x" {
+		t.Done = true
+		tokens = tokens[1:]
+		if d, ok := parseTodoTxtDate(tokens); ok {
+			t.DoneAt = &d
+			tokens = tokens[1:]
+			if d, ok := parseTodoTxtDate(tokens); ok {
+				t.CreatedAt = d
+				tokens = tokens[1:]
+			}
+		}
+	} else {
+		if p, ok := parsePriority(tokens[0]); ok {
+			t.Priority = p
+			tokens = tokens[1:]
+		}
+		if d, ok := parseTodoTxtDate(tokens); ok {
+			t.CreatedAt = d
+			tokens = tokens[1:]
+		}
+	}
+
+	// A descrição vai até a última palavra comum; os tokens depois dela
+	// são só campos.
+	end := len(tokens)
+	for end > 0 && isTag(tokens[end-1]) {
+		end--
+	}
+	t.Description = strings.Join(tokens[:end], " ")
+	for _, tok := range tokens {
+		switch {
+		case len(tok) > 1 && tok[0] == '+':
+			t.Projects = appendNew(t.Projects, tok[1:])
+		case len(tok) > 1 && tok[0] == '@':
+			t.Contexts = appendNew(t.Contexts, tok[1:])
+		case isExtra(tok):
+			k, v, _ := strings.Cut(tok, ":")
+			if t.Extras == nil {
+				t.Extras = map[string]string{}
+			}
+			t.Extras[k] = v
+		}
+	}
+
+	if p, ok := t.Extras["pri"]; ok && t.Done {
+		if p, ok := parsePriority("(" + p + ")"); ok {
+			t.Priority = p
+			delete(t.Extras, "pri")
+			if len(t.Extras) == 0 {
+				t.Extras = nil
+			}
+		}
+	}
+
+	if d, ok := parseTodoTxtDate([]string{t.Extras["due"]}); ok {
+		t.Due = &d
+		delete(t.Extras, "due")
+		if len(t.Extras) == 0 {
+			t.Extras = nil
+		}
+	}
+
+	if id, ok := t.Extras["uuid"]; ok {
+		t.UUID = id
+		delete(t.Extras, "uuid")
+		if len(t.Extras) == 0 {
+			t.Extras = nil
+		}
+	}
+
+	if t.Description == "" {
+		return t, fmt.Errorf("a descrição não pode estar vazia")
+	}
+	return t, nil
+}
+
+func parseTodoTxtDate(tokens []string) (time.Time, bool) {
+	if len(tokens) == 0 {
+		return time.Time{}, false
+	}
+	d, err := time.ParseInLocation(todoTxtDate, tokens[0], time.Local)
+	return d, err == nil
+}
+
+func parsePriority(tok string) (string, bool) {
+	if len(tok) == 3 && tok[0] == '(' && tok[2] == ')' && tok[1] >= 'A' && tok[1] <= 'Z' {
+		return tok[1:2], true
+	}
+	return "", false
+}
+
+// isTag informa se o token é um +projeto, um @contexto ou um chave:valor.
+func isTag(tok string) bool {
+	return len(tok) > 1 && (tok[0] == '+' || tok[0] == '@') || isExtra(tok)
+}
+
+// appendNew acrescenta s a list se ele ainda não estiver lá.
+func appendNew(list []string, s string) []string {
+	for _, x := range list {
+		if x == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// isExtra reconhece tokens chave:valor. URLs (http://...) e tokens de chave
+// numérica, como horários (10:30) e proporções (3:2), não contam: são parte
+// da descrição.
+func isExtra(tok string) bool {
+	k, v, ok := strings.Cut(tok, ":")
+	if !ok || k == "" || v == "" || strings.HasPrefix(v, "//") {
+		return false
+	}
+	if strings.Trim(k, "0123456789") == "" {
+		return false
+	}
+	return !strings.ContainsAny(k, "+@") && !strings.Contains(v, ":")
+}
@@ -0,0 +1,209 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func datePtr(y int, m time.Month, d int) *time.Time {
+	t := date(y, m, d)
+	return &t
+}
+
+// TestParseTodoTxt tests parsing single todo.txt lines
+func TestParseTodoTxt(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		want      schema.Task
+		wantError bool
+	}{
+		{
+			name: "Plain description",
+			line: "Estudar Go",
+			want: schema.Task{Description: "Estudar Go"},
+		},
+		{
+			name: "Priority and creation date",
+			line: "(A) 2025-01-01 Ligar para a mãe",
+			want: schema.Task{
+				Model:       gormModel(date(2025, 1, 1)),
+				Description: "Ligar para a mãe",
+				Priority:    "A",
+			},
+		},
+		{
+			name: "Projects, contexts and extras",
+			line: "Revisar PR +togo @trabalho due:2025-02-01 http://exemplo.com",
+			want: schema.Task{
+				Description: "Revisar PR +togo @trabalho due:2025-02-01 http://exemplo.com",
+				Projects:    []string{"togo"},
+				Contexts:    []string{"trabalho"},
+				Due:         datePtr(2025, 2, 1),
+			},
+		},
+		{
+			name: "Completed with dates and priority extra",
+			line: "x 2025-01-03 2025-01-01 Fazer compras pri:B",
+			want: schema.Task{
+				Model:       gormModel(date(2025, 1, 1)),
+				Description: "Fazer compras",
+				Done:        true,
+				DoneAt:      datePtr(2025, 1, 3),
+				Priority:    "B",
+			},
+		},
+		{
+			name: "UUID token",
+			line: "Pagar conta uuid:0b6f3c1e-2d4a-4f8e-9c7b-1a2b3c4d5e6f",
+			want: schema.Task{Description: "Pagar conta", UUID: "0b6f3c1e-2d4a-4f8e-9c7b-1a2b3c4d5e6f"},
+		},
+		{
+			name: "Lowercase priority is part of the description",
+			line: "(a) minúscula",
+			want: schema.Task{Description: "(a) minúscula"},
+		},
+		{
+			name:      "Only tokens",
+			line:      "+projeto @contexto",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTodoTxt(tt.line)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("ParseTodoTxt(%q) expected error, got nil", tt.line)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTodoTxt(%q) unexpected error: %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTodoTxt(%q) =\n%+v\nwant\n%+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTodoTxtRoundTrip tests that export followed by import keeps every field
+func TestTodoTxtRoundTrip(t *testing.T) {
+	tasks := []schema.Task{
+		{
+			Model:       gormModel(date(2025, 1, 1)),
+			Description: "Estudar Go",
+		},
+		{
+			Model:       gormModel(date(2025, 1, 2)),
+			Description: "Revisar PR do togo",
+			Priority:    "A",
+			Projects:    []string{"togo", "go"},
+			Contexts:    []string{"trabalho"},
+			Due:         datePtr(2025, 2, 1),
+			Extras:      map[string]string{"ticket": "TOGO-12"},
+			UUID:        "0b6f3c1e-2d4a-4f8e-9c7b-1a2b3c4d5e6f",
+		},
+		{
+			Model:       gormModel(date(2024, 12, 30)),
+			Description: "Fazer compras",
+			Done:        true,
+			DoneAt:      datePtr(2025, 1, 3),
+			Priority:    "C",
+			Contexts:    []string{"mercado"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (TodoTxt{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	got, err := (TodoTxt{}).Import(&buf)
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, tasks) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, tasks)
+	}
+}
+
+// TestTodoTxtLineRoundTrip tests that normalized todo.txt lines survive import and export unchanged
+func TestTodoTxtLineRoundTrip(t *testing.T) {
+	lines := []string{
+		"(A) 2025-01-01 Ligar para a mãe +família @telefone",
+		"x 2025-01-03 2025-01-01 Fazer compras @mercado pri:B",
+		"2025-01-05 Ler o manual do SQLite +togo due:2025-02-01 ticket:TOGO-7",
+	}
+	input := strings.Join(lines, "\n") + "\n"
+
+	tasks, err := (TodoTxt{}).Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := (TodoTxt{}).Export(&buf, tasks); err != nil {
+		t.Fatalf("Export() unexpected error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("line round trip mismatch:\ngot\n%s\nwant\n%s", buf.String(), input)
+	}
+}
+
+// TestTodoTxtMidLineTokens tests lines with projects and contexts in the
+// middle of the text and words that contain a colon
+func TestTodoTxtMidLineTokens(t *testing.T) {
+	line := "(B) Reunião +togo às 10:30 na sala 3:2 @escritório ver https://exemplo.com ticket:TOGO-9"
+	task, err := ParseTodoTxt(line)
+	if err != nil {
+		t.Fatalf("ParseTodoTxt() unexpected error: %v", err)
+	}
+	want := "Reunião +togo às 10:30 na sala 3:2 @escritório ver https://exemplo.com"
+	if task.Description != want {
+		t.Errorf("Description = %q, want %q", task.Description, want)
+	}
+	if !reflect.DeepEqual(task.Projects, []string{"togo"}) || !reflect.DeepEqual(task.Contexts, []string{"escritório"}) ||
+		!reflect.DeepEqual(task.Extras, map[string]string{"ticket": "TOGO-9"}) {
+		t.Errorf("tokens = %v %v %v, want +togo @escritório ticket:TOGO-9", task.Projects, task.Contexts, task.Extras)
+	}
+
+	// The tokens stay where they were, so the line comes back unchanged.
+	if got := FormatTodoTxt(task); got != line {
+		t.Errorf("FormatTodoTxt() = %q, want %q", got, line)
+	}
+}
+
+// TestTodoTxtDescriptionRoundTrip tests that descriptions containing words
+// that look like todo.txt tokens survive export and import unchanged
+func TestTodoTxtDescriptionRoundTrip(t *testing.T) {
+	descriptions := []string{
+		"Ler cap:3 do livro",
+		"Ver https://go.dev/doc/effective_go antes da reunião",
+		"Responder @maria sobre o +togo amanhã",
+		"Mandar e-mail para ana@exemplo.com às 10:30",
+	}
+	for _, d := range descriptions {
+		task := schema.Task{Description: d, Projects: []string{"casa"}, UUID: "0b6f3c1e-2d4a-4f8e-9c7b-1a2b3c4d5e6f"}
+		line := FormatTodoTxt(task)
+		got, err := ParseTodoTxt(line)
+		if err != nil {
+			t.Fatalf("ParseTodoTxt(%q) unexpected error: %v", line, err)
+		}
+		if got.Description != d || got.UUID != task.UUID || !reflect.DeepEqual(got.Projects[len(got.Projects)-1:], []string{"casa"}) {
+			t.Errorf("ParseTodoTxt(%q) = %+v, want description %q", line, got, d)
+		}
+		if again := FormatTodoTxt(got); again != line {
+			t.Errorf("FormatTodoTxt() = %q, want %q", again, line)
+		}
+	}
+}
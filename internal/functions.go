@@ -2,10 +2,14 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/internal/webhook"
 	"levyvix/togo/schema"
-	"strconv"
 	"strings"
 	"time"
 
@@ -28,14 +32,13 @@ func CreateFuncDB(args []string) error {
 		return fmt.Errorf("a descrição não pode estar vazia")
 	}
 
-	descricao := args[0]
-	novaTask := schema.Task{
-		Description: descricao,
-		Done:        false,
-		DoneAt:      nil,
+	if err := useHooks(); err != nil {
+		return err
+	}
+	_, err := (service.Tasks{}).Create(context.Background(), service.Patch{Description: service.Some(args[0])})
+	if err != nil {
+		return err
 	}
-
-	database.DB.Create(&novaTask)
 	fmt.Println(MsgTaskCreated)
 	return nil
 }
@@ -45,28 +48,11 @@ func DoneFuncDB(args []string) error {
 		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d argumentos", len(args))
 	}
 
-	id, err := strconv.Atoi(args[0])
-	if err != nil {
-		return fmt.Errorf("nao foi possivel convertar '%v' para inteiro", args[0])
+	if err := useHooks(); err != nil {
+		return err
 	}
-
-	var t schema.Task
-	result := database.DB.First(&t, id)
-	if result.Error != nil {
-		return fmt.Errorf("tarefa com ID %d não existe: %w", id, result.Error)
-	}
-
-	// tarefa já está feita?
-	if t.Done {
-		return fmt.Errorf("tarefa %d já está concluída", id)
-	}
-
-	t.Done = true
-	now := time.Now()
-	t.DoneAt = &now
-	result = database.DB.Save(&t)
-	if result.Error != nil {
-		return fmt.Errorf("erro ao salvar a tarefa no banco de dados: %w", result.Error)
+	if _, err := (service.Tasks{}).Complete(context.Background(), args[0]); err != nil {
+		return err
 	}
 	fmt.Println(MsgTaskDone)
 	return nil
@@ -77,19 +63,27 @@ func DeleteFuncDB(args []string) error {
 		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d", len(args))
 	}
 
-	taskID, err := strconv.Atoi(args[0])
-	if err != nil {
-		return fmt.Errorf("o ID deve ser um número inteiro, você passou '%v'", args[0])
+	if err := useHooks(); err != nil {
+		return err
 	}
-
-	result := database.DB.Delete(&schema.Task{}, taskID)
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("tarefa com ID %d não existe", taskID)
+	if _, err := (service.Tasks{}).Delete(context.Background(), args[0]); err != nil {
+		return err
 	}
 	fmt.Println(MsgTaskDeleted)
 	return nil
 }
 
+// useHooks liga os hooks de ~/.togo/hooks nas alterações do serviço; as
+// mensagens deles e os avisos vão para a saída padrão.
+func useHooks() error {
+	runner, err := hooks.Default()
+	if err != nil {
+		return err
+	}
+	service.Hooks = runner
+	return nil
+}
+
 // formatDate formata um time.Time para um formato legível.
 //
 // Formato: "02 Jan 2006 15:04"
@@ -117,6 +111,25 @@ func formatDate(t time.Time) string {
 	return portugues
 }
 
+// describeTask monta a descrição exibida na listagem, com a prioridade na
+// frente e os projetos e contextos no final.
+//
+// Exemplo: "(A) Revisar PR +togo @trabalho"
+func describeTask(t schema.Task) string {
+	parts := []string{}
+	if t.Priority != "" {
+		parts = append(parts, "("+t.Priority+")")
+	}
+	parts = append(parts, t.Description)
+	for _, p := range t.Projects {
+		parts = append(parts, "+"+p)
+	}
+	for _, c := range t.Contexts {
+		parts = append(parts, "@"+c)
+	}
+	return strings.Join(parts, " ")
+}
+
 func ListFuncDB() error {
 	var tasks []schema.Task
 
@@ -126,7 +139,18 @@ func ListFuncDB() error {
 		return nil
 	}
 
-	fmt.Println("\n📋 Lista de Tarefas:")
+	links, err := commitLinks(tasks)
+	if err != nil {
+		return err
+	}
+
+	if database.ProjectRoot != "" {
+		fmt.Printf("\n📋 Lista de Tarefas [projeto %s: %s]:\n", database.List, database.ProjectRoot)
+	} else if database.List != "" {
+		fmt.Printf("\n📋 Lista de Tarefas [%s]:\n", database.List)
+	} else {
+		fmt.Println("\n📋 Lista de Tarefas:")
+	}
 	fmt.Println("==================================================")
 	for _, t := range tasks {
 		status := "⏳"
@@ -134,11 +158,30 @@ func ListFuncDB() error {
 			status = "✅"
 		}
 
-		fmt.Printf("[%d] %s %s\n", t.ID, status, t.Description)
+		fmt.Printf("[%d] %s %s\n", t.ID, status, describeTask(t))
+		fmt.Printf("    UUID: %s\n", t.UUID)
 		fmt.Printf("    Criada em: %s\n", formatDate(t.CreatedAt))
 		if t.DoneAt != nil {
 			fmt.Printf("    Concluída em: %s\n", formatDate(*t.DoneAt))
 		}
+		if t.Due != nil {
+			fmt.Printf("    Prazo: %s\n", formatDate(*t.Due))
+		}
+		if t.Scheduled != nil {
+			fmt.Printf("    Agendada para: %s\n", formatDate(*t.Scheduled))
+		}
+		if t.Recurrence != "" {
+			fmt.Printf("    Repete: %s\n", t.Recurrence)
+		}
+		if len(t.Tags) > 0 {
+			fmt.Printf("    Tags: %s\n", strings.Join(t.Tags, ", "))
+		}
+		for _, a := range t.Annotations {
+			fmt.Printf("    Nota (%s): %s\n", formatDate(a.Entry), a.Description)
+		}
+		for _, l := range links[t.ID] {
+			fmt.Printf("    Commit: %s (%s) %s\n", shortSHA(l.SHA), l.Branch, l.Subject)
+		}
 		fmt.Println("--------------------------------------------------")
 	}
 	return nil
@@ -149,26 +192,17 @@ func EditFuncDB(args []string) error {
 		return fmt.Errorf("este comando aceita dois argumentos (ID e descrição), você passou %d", len(args))
 	}
 
-	taskID, err := strconv.Atoi(args[0])
-	if err != nil {
-		return fmt.Errorf("o ID deve ser um número inteiro, você passou '%v'", args[0])
-	}
-
 	novaDescricao := args[1]
 	if novaDescricao == "" || strings.TrimSpace(novaDescricao) == "" {
 		return fmt.Errorf("a descrição não pode estar vazia")
 	}
 
-	var t schema.Task
-	result := database.DB.First(&t, taskID)
-	if result.Error != nil {
-		return fmt.Errorf("tarefa com ID %d não existe: %w", taskID, result.Error)
+	if err := useHooks(); err != nil {
+		return err
 	}
-
-	t.Description = novaDescricao
-	result = database.DB.Save(&t)
-	if result.Error != nil {
-		return fmt.Errorf("erro ao salvar a tarefa no banco de dados: %w", result.Error)
+	patch := service.Patch{Description: service.Some(novaDescricao)}
+	if _, err := (service.Tasks{}).Update(context.Background(), args[0], patch); err != nil {
+		return err
 	}
 
 	fmt.Println(MsgTaskUpdated)
@@ -180,11 +214,26 @@ func ClearDB(args []string) error {
 		return fmt.Errorf("este comando nao aceita argumentos. voce passou %v argumentos", args)
 	}
 
+	// Guarda uma cópia do banco antes de apagar tudo (se TOGO_SNAPSHOTS estiver ativo)
+	snapshot, err := database.Snapshot("clear")
+	if err != nil {
+		return fmt.Errorf("erro ao criar snapshot antes de limpar: %w", err)
+	}
+	if snapshot != "" {
+		fmt.Printf("Snapshot salvo em %s\n", snapshot)
+	}
+
 	// Usa o modelo Task para pegar automaticamente o nome da tabela
 	// AllowGlobalUpdate permite deletar sem WHERE clause
-	result := database.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&schema.Task{})
-	if result.Error != nil {
-		return fmt.Errorf("erro ao tentar limpar a tabela: %w", result.Error)
+	err = database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&schema.Task{}).Error; err != nil {
+			return err
+		}
+		_, err := webhook.Enqueue(tx, events.Cleared, nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao tentar limpar a tabela: %w", err)
 	}
 
 	fmt.Println("Tabela limpa com sucesso!")
@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/internal/webhook"
 	"levyvix/togo/schema"
 
 	"gorm.io/driver/sqlite"
@@ -30,7 +33,7 @@ func init() {
 	}
 
 	// Migrate the schema
-	if err := testDB.AutoMigrate(&schema.Task{}); err != nil {
+	if err := testDB.AutoMigrate(&schema.Task{}, &schema.CommitLink{}, &schema.Webhook{}, &schema.WebhookDelivery{}); err != nil {
 		panic("failed to migrate test database")
 	}
 
@@ -43,6 +46,9 @@ func clearDB(t *testing.T) {
 	if err := testDB.Exec("DELETE FROM tasks").Error; err != nil {
 		t.Fatalf("failed to clear database: %v", err)
 	}
+	if err := testDB.Exec("DELETE FROM commit_links").Error; err != nil {
+		t.Fatalf("failed to clear database: %v", err)
+	}
 }
 
 // TestFormatDate tests the formatDate function with various inputs
@@ -89,6 +95,7 @@ func TestFormatDate(t *testing.T) {
 
 // TestCreateFuncDB tests creating a new task
 func TestCreateFuncDB(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
 	tests := []struct {
 		name      string
 		args      []string
@@ -180,6 +187,7 @@ func TestCreateFuncDB(t *testing.T) {
 
 // TestDoneFuncDB tests marking a task as done
 func TestDoneFuncDB(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
 	tests := []struct {
 		name      string
 		setup     func() uint
@@ -298,6 +306,7 @@ func TestDoneFuncDB(t *testing.T) {
 
 // TestDeleteFuncDB tests deleting a task
 func TestDeleteFuncDB(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
 	tests := []struct {
 		name      string
 		setup     func() uint
@@ -401,6 +410,7 @@ func TestDeleteFuncDB(t *testing.T) {
 
 // TestEditFuncDB tests editing a task
 func TestEditFuncDB(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
 	tests := []struct {
 		name         string
 		setup        func() uint
@@ -544,6 +554,137 @@ func TestEditFuncDB(t *testing.T) {
 	}
 }
 
+// TestResolveTask tests finding tasks by ID or unique UUID prefix
+func TestResolveTask(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	tasks := []schema.Task{
+		{Description: "Primeira", UUID: "3f0c2a9e-1111-4d6e-9a55-0b1c2d3e4f50"},
+		{Description: "Segunda", UUID: "3f0c7b00-2222-4d6e-9a55-0b1c2d3e4f50"},
+		{Description: "Terceira", UUID: "a1b2c3d4-3333-4d6e-9a55-0b1c2d3e4f50"},
+	}
+	for i := range tasks {
+		testDB.Create(&tasks[i])
+	}
+
+	tests := []struct {
+		ref       string
+		want      string
+		wantError bool
+	}{
+		{ref: itoa(tasks[1].ID), want: "Segunda"},
+		{ref: "3f0c2a", want: "Primeira"},
+		{ref: "A1B2", want: "Terceira"},
+		{ref: tasks[1].UUID, want: "Segunda"},
+		{ref: "3f0c", wantError: true},
+		{ref: "ffff", wantError: true},
+		{ref: "xyz", wantError: true},
+		{ref: "999", wantError: true},
+	}
+	for _, tt := range tests {
+		got, err := service.Resolve(testDB, tt.ref)
+		if tt.wantError {
+			if err == nil {
+				t.Errorf("Resolve(%q) = %q, want error", tt.ref, got.Description)
+			}
+			continue
+		}
+		if err != nil || got.Description != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.ref, got.Description, err, tt.want)
+		}
+	}
+
+	// Os comandos aceitam o prefixo no lugar do ID.
+	if _, err := captureOutput(t, func() error { return DoneFuncDB([]string{"a1b2c3"}) }); err != nil {
+		t.Fatalf("DoneFuncDB with UUID prefix unexpected error: %v", err)
+	}
+	var done schema.Task
+	testDB.First(&done, tasks[2].ID)
+	if !done.Done {
+		t.Errorf("DoneFuncDB with UUID prefix did not complete the task")
+	}
+}
+
+// TestMutationHooks tests that pre hooks can reject or change the mutations
+func TestMutationHooks(t *testing.T) {
+	clearDB(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(hooks.DisableEnv, "1")
+	dir := home + "/.togo/hooks"
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(dir+"/on-add.ticket", []byte("#!/bin/sh\n"+
+		`grep -q '"description":"PROJ-' || { echo "falta o ticket" >&2; exit 1; }`+"\n"), 0755)
+	os.WriteFile(dir+"/on-modify.tag", []byte("#!/bin/sh\n"+
+		`[ "$1" = pre ] && sed 's/"done":false/"done":false,"tags":["editada"]/'`+"\n"), 0755)
+	os.WriteFile(dir+"/on-delete.keep", []byte("#!/bin/sh\nexit 1\n"), 0755)
+
+	if _, err := captureOutput(t, func() error { return CreateFuncDB([]string{"Corrigir login"}) }); err == nil {
+		t.Error("CreateFuncDB() without a ticket expected error, got nil")
+	}
+	var count int64
+	testDB.Model(&schema.Task{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("rejected task was saved")
+	}
+
+	if _, err := captureOutput(t, func() error { return CreateFuncDB([]string{"PROJ-1 Corrigir login"}) }); err != nil {
+		t.Fatalf("CreateFuncDB() unexpected error: %v", err)
+	}
+	var task schema.Task
+	testDB.First(&task)
+	if _, err := captureOutput(t, func() error { return EditFuncDB([]string{itoa(task.ID), "PROJ-1 Corrigir o login"}) }); err != nil {
+		t.Fatalf("EditFuncDB() unexpected error: %v", err)
+	}
+	testDB.First(&task, task.ID)
+	if task.Description != "PROJ-1 Corrigir o login" || len(task.Tags) != 1 || task.Tags[0] != "editada" {
+		t.Errorf("edited task = %q %v, want the hook's tag", task.Description, task.Tags)
+	}
+
+	if _, err := captureOutput(t, func() error { return DeleteFuncDB([]string{itoa(task.ID)}) }); err == nil {
+		t.Error("DeleteFuncDB() rejected by the hook expected error, got nil")
+	}
+	testDB.Model(&schema.Task{}).Count(&count)
+	if count != 1 {
+		t.Errorf("task deleted despite the on-delete hook")
+	}
+}
+
+// TestMutationsQueueWebhooks tests that command line changes queue the webhook deliveries
+func TestMutationsQueueWebhooks(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	t.Setenv("TOGO_SNAPSHOTS", "0")
+	if _, err := webhook.Add("https://exemplo.com/hook", nil, "segredo"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		testDB.Exec("DELETE FROM webhooks")
+		testDB.Exec("DELETE FROM webhook_deliveries")
+	})
+
+	captureOutput(t, func() error { return CreateFuncDB([]string{"Estudar Go"}) })
+	var task schema.Task
+	testDB.First(&task)
+	for _, fn := range []func() error{
+		func() error { return EditFuncDB([]string{itoa(task.ID), "Estudar Go hoje"}) },
+		func() error { return DoneFuncDB([]string{itoa(task.ID)}) },
+		func() error { return DeleteFuncDB([]string{itoa(task.ID)}) },
+		func() error { return ClearDB(nil) },
+	} {
+		if _, err := captureOutput(t, fn); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var got []string
+	testDB.Model(&schema.WebhookDelivery{}).Order("id asc").Pluck("event", &got)
+	want := []string{"task.created", "task.updated", "task.done", "task.deleted", "tasks.cleared"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("queued events = %v, want %v", got, want)
+	}
+}
+
 // TestListFuncDB tests listing tasks
 func TestListFuncDB(t *testing.T) {
 	tests := []struct {
@@ -702,6 +843,7 @@ func BenchmarkFormatDate(b *testing.B) {
 
 // BenchmarkCreateFuncDB benchmarks creating tasks
 func BenchmarkCreateFuncDB(b *testing.B) {
+	b.Setenv(hooks.DisableEnv, "0")
 	clearDB(&testing.T{})
 
 	// Silence output
@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// hookMarker identifica os hooks instalados pelo togo, que podem ser
+// sobrescritos sem --force.
+const hookMarker = "# instalado por togo git hook install"
+
+// gitHooks são os hooks instalados, cada um chamando "togo git <hook>":
+// commit-msg recusa commits que citam tarefas inexistentes e post-commit,
+// que já conhece o SHA do commit, grava o vínculo e conclui as tarefas
+// fechadas.
+var gitHooks = []string{"commit-msg", "post-commit"}
+
+// CommitRef é uma menção a uma tarefa na mensagem de um commit.
+type CommitRef struct {
+	TaskID int
+	// Closes é verdadeiro para "closes/fixes/resolves togo #N".
+	Closes bool
+}
+
+var commitRefPattern = regexp.MustCompile(`(?i)\b(?:(close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s+)?togo\s+#(\d+)\b`)
+
+// ParseCommitRefs encontra as menções "togo #N" e "closes togo #N" na
+// mensagem, ignorando as linhas de comentário do git (#). Uma tarefa
+// mencionada mais de uma vez aparece uma vez só, fechada se alguma das
+// menções a fechar.
+func ParseCommitRefs(message string) []CommitRef {
+	var refs []CommitRef
+	index := map[int]int{}
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, m := range commitRefPattern.FindAllStringSubmatch(line, -1) {
+			id, err := strconv.Atoi(m[2])
+			if err != nil {
+				continue
+			}
+			closes := m[1] != ""
+			if i, ok := index[id]; ok {
+				refs[i].Closes = refs[i].Closes || closes
+				continue
+			}
+			index[id] = len(refs)
+			refs = append(refs, CommitRef{TaskID: id, Closes: closes})
+		}
+	}
+	return refs
+}
+
+// GitHookInstallFunc instala os hooks commit-msg e post-commit no
+// repositório de dir. Hooks de terceiros só são substituídos com force, e
+// nesse caso o original é guardado com a extensão .bak. togoArgs são
+// repassados ao togo pelos hooks (por exemplo --list trabalho).
+func GitHookInstallFunc(dir string, force bool, togoArgs []string) error {
+	hooksDir, err := gitOutput(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar %s: %w", hooksDir, err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("não foi possível descobrir o caminho do togo: %w", err)
+	}
+
+	for _, name := range gitHooks {
+		path := filepath.Join(hooksDir, name)
+		if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookMarker) {
+			if !force {
+				return fmt.Errorf("já existe um hook %s que não é do togo; use --force para substituí-lo (o original é guardado em %s.bak)", path, name)
+			}
+			if err := os.Rename(path, path+".bak"); err != nil {
+				return fmt.Errorf("erro ao guardar o hook original: %w", err)
+			}
+		}
+
+		args := append([]string{shellQuote(exe)}, quoteAll(togoArgs)...)
+		args = append(args, "git", name)
+		if name == "commit-msg" {
+			args = append(args, `"$1"`)
+		}
+		script := "#!/bin/sh\n" + hookMarker + "\nexec " + strings.Join(args, " ") + "\n"
+		if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+			return fmt.Errorf("erro ao gravar %s: %w", path, err)
+		}
+		fmt.Printf("Hook %s instalado em %s\n", name, path)
+	}
+	return nil
+}
+
+// GitCommitMsgFunc é chamado pelo hook commit-msg com o arquivo da
+// mensagem. Retorna erro (e o git recusa o commit) se a mensagem citar uma
+// tarefa que não existe. O vínculo e a conclusão ficam para o post-commit,
+// porque aqui o commit ainda não tem SHA.
+func GitCommitMsgFunc(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d", len(args))
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("erro ao ler a mensagem do commit: %w", err)
+	}
+
+	var problems []string
+	for _, ref := range ParseCommitRefs(string(data)) {
+		var t schema.Task
+		if err := database.DB.First(&t, ref.TaskID).Error; err != nil {
+			problems = append(problems, fmt.Sprintf("a tarefa #%d não existe", ref.TaskID))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("commit recusado: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// GitPostCommitFunc é chamado pelo hook post-commit no repositório de dir:
+// vincula o commit HEAD às tarefas citadas e conclui as que ele fecha.
+func GitPostCommitFunc(dir string) error {
+	sha, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	message, err := gitOutput(dir, "log", "-1", "--format=%B", "HEAD")
+	if err != nil {
+		return err
+	}
+	branch, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+
+	refs := ParseCommitRefs(message)
+	var report []string
+	// closes guarda, pela linha do relatório, as tarefas que o commit fecha.
+	var closes map[int]uint
+	err = database.Transaction(func(tx *gorm.DB) error {
+		report, closes = nil, map[int]uint{}
+		for _, ref := range refs {
+			var t schema.Task
+			if err := tx.First(&t, ref.TaskID).Error; err != nil {
+				report = append(report, fmt.Sprintf("tarefa #%d não existe, ignorada", ref.TaskID))
+				continue
+			}
+
+			link := schema.CommitLink{TaskID: t.ID, SHA: sha, Branch: branch, Subject: subject}
+			if err := tx.Where(schema.CommitLink{TaskID: t.ID, SHA: sha}).FirstOrCreate(&link).Error; err != nil {
+				return fmt.Errorf("erro ao vincular o commit à tarefa #%d: %w", t.ID, err)
+			}
+
+			if ref.Closes {
+				closes[len(report)] = t.ID
+			}
+			report = append(report, fmt.Sprintf("commit %s vinculado à tarefa #%d", shortSHA(sha), t.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// As conclusões passam pelo serviço, com os hooks do togo, depois que os
+	// vínculos estão gravados; o commit já foi feito, então uma recusa só
+	// aparece no relatório.
+	if err := useHooks(); err != nil {
+		return err
+	}
+	for i, line := range report {
+		if id, ok := closes[i]; ok {
+			_, err := service.Tasks{}.Complete(context.Background(), strconv.Itoa(int(id)))
+			switch {
+			case err == nil:
+				line += " (concluída)"
+			case errors.Is(err, service.ErrAlreadyDone):
+			default:
+				line += fmt.Sprintf(" (não concluída: %v)", err)
+			}
+		}
+		fmt.Println("togo:", line)
+	}
+	return nil
+}
+
+// commitLinks carrega os commits vinculados às tarefas, por ID da tarefa.
+func commitLinks(tasks []schema.Task) (map[uint][]schema.CommitLink, error) {
+	ids := make([]uint, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+	var links []schema.CommitLink
+	if err := database.DB.Where("task_id IN ?", ids).Order("created_at asc").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler os commits vinculados: %w", err)
+	}
+	byTask := map[uint][]schema.CommitLink{}
+	for _, l := range links {
+		byTask[l.TaskID] = append(byTask[l.TaskID], l)
+	}
+	return byTask, nil
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// shellQuote coloca s entre aspas simples para o sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteAll(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = shellQuote(a)
+	}
+	return out
+}
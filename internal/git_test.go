@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/schema"
+)
+
+// newGitRepo creates a temporary repository with hooks disabled
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "togo@example.com"},
+		{"config", "user.name", "togo"},
+		{"config", "core.hooksPath", filepath.Join(dir, "no-hooks")},
+	} {
+		gitRun(t, dir, args...)
+	}
+	return dir
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// TestParseCommitRefs tests finding task mentions in commit messages
+func TestParseCommitRefs(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []CommitRef
+	}{
+		{message: "Ajusta o parser (togo #12)", want: []CommitRef{{TaskID: 12}}},
+		{message: "Closes togo #3 e fixes togo #4", want: []CommitRef{{TaskID: 3, Closes: true}, {TaskID: 4, Closes: true}}},
+		{message: "togo #5\n\nresolved togo #5", want: []CommitRef{{TaskID: 5, Closes: true}}},
+		{message: "Sem referência #7\n# togo #8 é comentário", want: nil},
+		{message: "togo #12abc", want: nil},
+	}
+	for _, tt := range tests {
+		if got := ParseCommitRefs(tt.message); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCommitRefs(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+	}
+}
+
+// TestGitHookInstallFunc tests installing hooks and refusing to clobber foreign ones
+func TestGitHookInstallFunc(t *testing.T) {
+	dir := newGitRepo(t)
+	gitRun(t, dir, "config", "--unset", "core.hooksPath")
+	hooks := filepath.Join(dir, ".git", "hooks")
+	foreign := filepath.Join(hooks, "commit-msg")
+	if err := os.WriteFile(foreign, []byte("#!/bin/sh\necho outro\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureOutput(t, func() error { return GitHookInstallFunc(dir, false, nil) }); err == nil {
+		t.Fatalf("GitHookInstallFunc over a foreign hook expected error, got nil")
+	}
+	if _, err := captureOutput(t, func() error { return GitHookInstallFunc(dir, true, []string{"--list", "trabalho"}) }); err != nil {
+		t.Fatalf("GitHookInstallFunc --force unexpected error: %v", err)
+	}
+	if _, err := os.Stat(foreign + ".bak"); err != nil {
+		t.Errorf("foreign hook should be kept as .bak: %v", err)
+	}
+
+	for _, name := range []string{"commit-msg", "post-commit"} {
+		path := filepath.Join(hooks, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("hook %s not installed: %v", name, err)
+		}
+		if info.Mode().Perm()&0100 == 0 {
+			t.Errorf("hook %s is not executable", name)
+		}
+		script, _ := os.ReadFile(path)
+		if !strings.Contains(string(script), "'--list' 'trabalho' git "+name) {
+			t.Errorf("hook %s = %q, want togo called with the list flag", name, script)
+		}
+	}
+
+	// Reinstalar por cima dos próprios hooks não precisa de --force.
+	if _, err := captureOutput(t, func() error { return GitHookInstallFunc(dir, false, nil) }); err != nil {
+		t.Errorf("reinstalling togo hooks unexpected error: %v", err)
+	}
+}
+
+// TestGitCommitHooks tests validating, linking and closing tasks from commits
+func TestGitCommitHooks(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	dir := newGitRepo(t)
+	fix := schema.Task{Description: "Corrigir parser"}
+	other := schema.Task{Description: "Documentar"}
+	testDB.Create(&fix)
+	testDB.Create(&other)
+
+	msgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	os.WriteFile(msgFile, []byte("Teste togo #9999\n"), 0644)
+	if err := GitCommitMsgFunc([]string{msgFile}); err == nil {
+		t.Errorf("GitCommitMsgFunc with unknown task expected error, got nil")
+	}
+
+	message := "Corrige o parser\n\ncloses togo #" + itoa(fix.ID) + ", ver togo #" + itoa(other.ID)
+	os.WriteFile(msgFile, []byte(message), 0644)
+	if err := GitCommitMsgFunc([]string{msgFile}); err != nil {
+		t.Fatalf("GitCommitMsgFunc unexpected error: %v", err)
+	}
+
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", message)
+	output, err := captureOutput(t, func() error { return GitPostCommitFunc(dir) })
+	if err != nil {
+		t.Fatalf("GitPostCommitFunc unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "(concluída)") {
+		t.Errorf("GitPostCommitFunc output = %q, want the closed task reported", output)
+	}
+
+	testDB.First(&fix, fix.ID)
+	testDB.First(&other, other.ID)
+	if !fix.Done || other.Done {
+		t.Errorf("done = %v/%v, want only the closed task done", fix.Done, other.Done)
+	}
+
+	var links []schema.CommitLink
+	testDB.Order("task_id asc").Find(&links)
+	if len(links) != 2 || links[0].Branch != "main" || links[0].Subject != "Corrige o parser" || len(links[0].SHA) != 40 {
+		t.Fatalf("links = %+v, want two links on main with the commit subject", links)
+	}
+
+	// Rodar o hook de novo para o mesmo commit não duplica os vínculos.
+	if _, err := captureOutput(t, func() error { return GitPostCommitFunc(dir) }); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	testDB.Model(&schema.CommitLink{}).Count(&count)
+	if count != 2 {
+		t.Errorf("links after running the hook twice = %d, want 2", count)
+	}
+
+	listOutput, err := captureOutput(t, ListFuncDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(listOutput, "Commit: "+links[0].SHA[:7]+" (main) Corrige o parser") {
+		t.Errorf("ListFuncDB output should show the linked commit:\n%s", listOutput)
+	}
+}
+
+// TestGitPostCommitRunsHooks tests that closing a task from a commit runs the on-done hooks
+func TestGitPostCommitRunsHooks(t *testing.T) {
+	clearDB(t)
+	dir := newGitRepo(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(hooks.DisableEnv, "1")
+	hookDir := home + "/.togo/hooks"
+	os.MkdirAll(hookDir, 0755)
+	os.WriteFile(hookDir+"/on-done.check", []byte("#!/bin/sh\n"+
+		`[ "$1" = post ] && { echo "hook post rodou"; exit 0; }`+"\n"+
+		`grep -q Bloqueada && { echo "ainda em revisão" >&2; exit 1; }`+"\n"+
+		"exit 0\n"), 0755)
+
+	fix := schema.Task{Description: "Corrigir parser"}
+	blocked := schema.Task{Description: "Bloqueada pela revisão"}
+	testDB.Create(&fix)
+	testDB.Create(&blocked)
+
+	gitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "closes togo #"+itoa(fix.ID)+", closes togo #"+itoa(blocked.ID))
+	output, err := captureOutput(t, func() error { return GitPostCommitFunc(dir) })
+	if err != nil {
+		t.Fatalf("GitPostCommitFunc unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "hook post rodou") || !strings.Contains(output, "não concluída: hook on-done.check recusou a alteração: ainda em revisão") {
+		t.Errorf("GitPostCommitFunc output = %q, want the post hook feedback and the refusal", output)
+	}
+
+	testDB.First(&fix, fix.ID)
+	testDB.First(&blocked, blocked.ID)
+	if !fix.Done || blocked.Done {
+		t.Errorf("done = %v/%v, want the refused task still pending", fix.Done, blocked.Done)
+	}
+	var count int64
+	testDB.Model(&schema.CommitLink{}).Count(&count)
+	if count != 2 {
+		t.Errorf("links = %d, want both tasks linked", count)
+	}
+}
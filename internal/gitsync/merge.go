@@ -0,0 +1,177 @@
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"levyvix/togo/internal/formats"
+	"sort"
+	"time"
+)
+
+// fields é uma tarefa como gravada no arquivo do sync: os campos de
+// formats.Record, sem o ID e o dono (que são locais a cada banco), por nome.
+type fields map[string]json.RawMessage
+
+// toFields converte um registro em campos, com as datas em UTC para que o
+// mesmo instante gere sempre o mesmo arquivo, em qualquer máquina.
+func toFields(r formats.Record) (fields, error) {
+	r = r.UTC()
+	r.ID = 0
+	r.OwnerID = nil
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseFields(data)
+}
+
+// parseFields lê o conteúdo de um arquivo de tarefa.
+func parseFields(data []byte) (fields, error) {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	delete(f, "id")
+	delete(f, "owner_id")
+	for k, v := range f {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, v); err != nil {
+			return nil, err
+		}
+		if buf.String() == "null" {
+			delete(f, k)
+			continue
+		}
+		f[k] = buf.Bytes()
+	}
+	return f, nil
+}
+
+// record converte os campos de volta em registro.
+func (f fields) record() (formats.Record, error) {
+	var r formats.Record
+	data, err := json.Marshal(f)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+// marshal gera o conteúdo do arquivo: JSON indentado com as chaves em
+// ordem, para diffs legíveis e estáveis.
+func (f fields) marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(map[string]json.RawMessage(f), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (f fields) equal(other fields) bool {
+	if len(f) != len(other) {
+		return false
+	}
+	for k, v := range f {
+		if w, ok := other[k]; !ok || !bytes.Equal(v, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// updatedAt lê o campo updated_at; zero se ausente ou inválido.
+func (f fields) updatedAt() time.Time {
+	var t time.Time
+	if raw, ok := f["updated_at"]; ok {
+		_ = json.Unmarshal(raw, &t)
+	}
+	return t
+}
+
+func (f fields) description() string {
+	var s string
+	_ = json.Unmarshal(f["description"], &s)
+	return s
+}
+
+// mergeFields combina campo a campo as versões local e remota de uma tarefa
+// a partir da base, o estado da última sincronização (nil para uma tarefa
+// criada dos dois lados). Um campo mudado de um lado só fica com essa
+// mudança; mudado dos dois lados com valores diferentes, fica com o lado de
+// updated_at mais recente e conta como conflito.
+func mergeFields(base, local, remote fields) (fields, int) {
+	localWins := !remote.updatedAt().After(local.updatedAt())
+
+	keys := map[string]bool{}
+	for _, f := range []fields{base, local, remote} {
+		for k := range f {
+			keys[k] = true
+		}
+	}
+
+	merged := fields{}
+	conflicts := 0
+	for k := range keys {
+		b, l, r := base[k], local[k], remote[k]
+		var v json.RawMessage
+		switch {
+		case bytes.Equal(l, r), bytes.Equal(r, b):
+			v = l
+		case bytes.Equal(l, b):
+			v = r
+		default:
+			if k != "updated_at" {
+				conflicts++
+			}
+			if localWins {
+				v = l
+			} else {
+				v = r
+			}
+		}
+		if v != nil {
+			merged[k] = v
+		}
+	}
+	return merged, conflicts
+}
+
+// merge combina as tarefas locais e remotas, por UUID, a partir da base.
+// Uma tarefa que só existe de um lado é mantida, a menos que tenha sido
+// removida do outro sem ter mudado deste lado desde a base.
+func merge(base, local, remote map[string]fields) (map[string]fields, []string) {
+	ids := map[string]bool{}
+	for _, m := range []map[string]fields{local, remote} {
+		for id := range m {
+			ids[id] = true
+		}
+	}
+
+	merged := map[string]fields{}
+	var conflicts []string
+	for id := range ids {
+		b, inBase := base[id]
+		l, inLocal := local[id]
+		r, inRemote := remote[id]
+		switch {
+		case inLocal && inRemote:
+			m, n := mergeFields(b, l, r)
+			merged[id] = m
+			if n > 0 {
+				conflicts = append(conflicts, fmt.Sprintf("%q (%d campo(s))", m.description(), n))
+			}
+		case inLocal:
+			if !inBase || !l.equal(b) {
+				merged[id] = l
+			}
+		case inRemote:
+			if !inBase || !r.equal(b) {
+				merged[id] = r
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return merged, conflicts
+}
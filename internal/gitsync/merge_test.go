@@ -0,0 +1,81 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func parse(t *testing.T, s string) fields {
+	t.Helper()
+	f, err := parseFields([]byte(s))
+	if err != nil {
+		t.Fatalf("parseFields(%q) unexpected error: %v", s, err)
+	}
+	return f
+}
+
+// TestMergeFields tests the three-way merge of a single task
+func TestMergeFields(t *testing.T) {
+	base := `{"description":"a","priority":"B","updated_at":"2026-01-01T00:00:00Z"}`
+	tests := []struct {
+		name          string
+		local, remote string
+		want          string
+		conflicts     int
+	}{
+		{
+			name:   "different fields",
+			local:  `{"description":"a2","priority":"B","updated_at":"2026-01-02T00:00:00Z"}`,
+			remote: `{"description":"a","priority":"A","updated_at":"2026-01-03T00:00:00Z"}`,
+			want:   `{"description":"a2","priority":"A","updated_at":"2026-01-03T00:00:00Z"}`,
+		},
+		{
+			name:      "same field, remote newer",
+			local:     `{"description":"local","priority":"B","updated_at":"2026-01-02T00:00:00Z"}`,
+			remote:    `{"description":"remote","priority":"B","updated_at":"2026-01-03T00:00:00Z"}`,
+			want:      `{"description":"remote","priority":"B","updated_at":"2026-01-03T00:00:00Z"}`,
+			conflicts: 1,
+		},
+		{
+			name:   "field removed on one side",
+			local:  `{"description":"a","updated_at":"2026-01-02T00:00:00Z"}`,
+			remote: base,
+			want:   `{"description":"a","updated_at":"2026-01-02T00:00:00Z"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n := mergeFields(parse(t, base), parse(t, tt.local), parse(t, tt.remote))
+			if !got.equal(parse(t, tt.want)) {
+				data, _ := json.Marshal(got)
+				t.Errorf("mergeFields() = %s, want %s", data, tt.want)
+			}
+			if n != tt.conflicts {
+				t.Errorf("conflicts = %d, want %d", n, tt.conflicts)
+			}
+		})
+	}
+}
+
+// TestMergeAddRemove tests tasks added or removed on one side
+func TestMergeAddRemove(t *testing.T) {
+	a := `{"description":"a"}`
+	changed := `{"description":"a2"}`
+	base := map[string]fields{"kept": parse(t, a), "removed": parse(t, a), "edited": parse(t, a)}
+	local := map[string]fields{"kept": parse(t, a), "edited": parse(t, changed), "new": parse(t, a)}
+	remote := map[string]fields{"kept": parse(t, a), "removed": parse(t, a)}
+
+	merged, _ := merge(base, local, remote)
+	var got []string
+	for _, id := range []string{"kept", "removed", "edited", "new"} {
+		if _, ok := merged[id]; ok {
+			got = append(got, id)
+		}
+	}
+	// "edited" sumiu do remoto mas mudou localmente, então fica.
+	want := []string{"kept", "edited", "new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged tasks = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package gitsync
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// branch é o branch usado no repositório do sync e no remoto.
+const branch = "main"
+
+// remoteRef é a cópia local do branch do remoto, atualizada pelo fetch.
+const remoteRef = "refs/remotes/origin/" + branch
+
+// repo é o repositório git do diretório de sync.
+type repo struct {
+	dir string
+	// env completa a identidade do autor dos commits quando o git do
+	// usuário não tem user.name/user.email configurados.
+	env []string
+}
+
+// openRepo abre o repositório em dir, criando-o se preciso.
+func openRepo(dir string) (*repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("o togo sync precisa do git instalado")
+	}
+	r := &repo{dir: dir}
+	if _, err := os.Stat(dir + "/.git"); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("erro ao criar %s: %w", dir, err)
+		}
+		if _, err := r.git(nil, "init", "-q"); err != nil {
+			return nil, err
+		}
+		if _, err := r.git(nil, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+			return nil, err
+		}
+	}
+	if name, _ := r.git(nil, "config", "user.name"); name == "" {
+		r.env = append(r.env, "GIT_AUTHOR_NAME=togo", "GIT_COMMITTER_NAME=togo")
+	}
+	if email, _ := r.git(nil, "config", "user.email"); email == "" {
+		r.env = append(r.env, "GIT_AUTHOR_EMAIL=togo@localhost", "GIT_COMMITTER_EMAIL=togo@localhost")
+	}
+	return r, nil
+}
+
+// git roda um comando do git no repositório, com stdin opcional, e retorna
+// a saída sem espaços nas pontas.
+func (r *repo) git(stdin io.Reader, args ...string) (string, error) {
+	out, err := r.gitBytes(stdin, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+func (r *repo) gitBytes(stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(), r.env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// setRemote configura o remoto origin; url vazia mantém o atual. Retorna
+// erro se nenhum remoto estiver configurado.
+func (r *repo) setRemote(url string) error {
+	current, _ := r.git(nil, "remote", "get-url", "origin")
+	switch {
+	case url == "" && current == "":
+		return errors.New("nenhum remoto configurado; use togo sync --remote <url> na primeira vez")
+	case url == "" || url == current:
+		return nil
+	case current == "":
+		_, err := r.git(nil, "remote", "add", "origin", url)
+		return err
+	default:
+		_, err := r.git(nil, "remote", "set-url", "origin", url)
+		return err
+	}
+}
+
+// resolve retorna o SHA do commit de ref, ou "" se ref não existir.
+func (r *repo) resolve(ref string) string {
+	sha, err := r.git(nil, "rev-parse", "-q", "--verify", ref+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return sha
+}
+
+// isAncestor diz se o commit a é ancestral de b (ou igual a ele).
+func (r *repo) isAncestor(a, b string) bool {
+	_, err := r.git(nil, "merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// readTasks lê os arquivos tasks/<uuid>.json do commit, por UUID. Um commit
+// vazio ("") não tem tarefas.
+func (r *repo) readTasks(commit string) (map[string]fields, error) {
+	tasks := map[string]fields{}
+	if commit == "" {
+		return tasks, nil
+	}
+	list, err := r.git(nil, "ls-tree", "-r", "--name-only", commit, "--", tasksDir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range strings.Split(list, "\n") {
+		if strings.HasSuffix(name, ".json") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return tasks, nil
+	}
+
+	var req strings.Builder
+	for _, name := range names {
+		req.WriteString(commit + ":" + name + "\n")
+	}
+	out, err := r.gitBytes(strings.NewReader(req.String()), "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+
+	// Cada objeto vem como "<sha> blob <tamanho>\n<conteúdo>\n".
+	reader := bufio.NewReader(bytes.NewReader(out))
+	for _, name := range names {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", name, err)
+		}
+		parts := strings.Fields(header)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("erro ao ler %s: %s", name, strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", name, err)
+		}
+		data := make([]byte, size+1)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, fmt.Errorf("erro ao ler %s: %w", name, err)
+		}
+		f, err := parseFields(data[:size])
+		if err != nil {
+			return nil, fmt.Errorf("%s inválido: %w", name, err)
+		}
+		tasks[idFromFileName(path.Base(name))] = f
+	}
+	return tasks, nil
+}
+
+// commit grava o índice como um commit com os pais informados e move o
+// branch para ele. Se houver um pai só e a árvore for a mesma dele, nenhum
+// commit é criado e o pai é retornado.
+func (r *repo) commit(message string, parents ...string) (string, error) {
+	tree, err := r.git(nil, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	if len(parents) == 1 {
+		if parentTree, _ := r.git(nil, "rev-parse", parents[0]+"^{tree}"); parentTree == tree {
+			_, err := r.git(nil, "update-ref", "refs/heads/"+branch, parents[0])
+			return parents[0], err
+		}
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	for _, p := range parents {
+		args = append(args, "-p", p)
+	}
+	sha, err := r.git(nil, args...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := r.git(nil, "update-ref", "refs/heads/"+branch, sha); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
@@ -0,0 +1,226 @@
+// Package gitsync sincroniza o banco de tarefas entre máquinas por um
+// repositório git: cada tarefa vira um arquivo JSON, as mudanças locais são
+// commitadas e combinadas com as do remoto campo a campo, e o resultado
+// volta para o banco e para o remoto.
+package gitsync
+
+import (
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/schema"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// tasksDir é o diretório do repositório com os arquivos das tarefas.
+const tasksDir = "tasks"
+
+// Report resume uma sincronização.
+type Report struct {
+	// Created, Updated e Removed contam as mudanças aplicadas ao banco local.
+	Created, Updated, Removed int
+	// Conflicts lista as tarefas com campos mudados dos dois lados.
+	Conflicts []string
+	Commit    string
+	Pushed    bool
+}
+
+// Dir retorna o repositório de sync do banco aberto: o diretório sync/
+// ao lado dele, com um subdiretório por banco (tasks, trabalho, ...).
+func Dir() string {
+	name := strings.TrimSuffix(filepath.Base(database.Path), filepath.Ext(database.Path))
+	return filepath.Join(filepath.Dir(database.Path), "sync", name)
+}
+
+// Sync sincroniza o banco aberto com o remoto. remoteURL configura o
+// remoto na primeira vez (ou o troca); vazio usa o já configurado.
+//
+// A base do merge é o último commit sincronizado (HEAD). Para cada tarefa,
+// um campo mudado só de um lado desde a base fica com essa mudança; mudado
+// dos dois lados, vence o lado com updated_at mais recente.
+func Sync(remoteURL string) (Report, error) {
+	var report Report
+	if database.IsEncrypted() {
+		return report, errors.New("o togo sync grava as tarefas em texto puro e não está disponível para bancos criptografados")
+	}
+
+	r, err := openRepo(Dir())
+	if err != nil {
+		return report, err
+	}
+	if err := r.setRemote(remoteURL); err != nil {
+		return report, err
+	}
+	if _, err := r.git(nil, "fetch", "-q", "origin"); err != nil {
+		return report, err
+	}
+
+	head := r.resolve("HEAD")
+	remote := r.resolve(remoteRef)
+	base, err := r.readTasks(head)
+	if err != nil {
+		return report, err
+	}
+	// Sem o branch no remoto (primeira sincronização, ou remoto novo), as
+	// tarefas locais são enviadas como estão.
+	theirs := base
+	if remote != "" {
+		if theirs, err = r.readTasks(remote); err != nil {
+			return report, err
+		}
+	}
+
+	var merged map[string]fields
+	err = database.Transaction(func(tx *gorm.DB) error {
+		report = Report{}
+		local, ids, err := loadLocal(tx)
+		if err != nil {
+			return err
+		}
+		merged, report.Conflicts = merge(base, local, theirs)
+		return apply(tx, local, ids, merged, &report)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// O repositório só é atualizado depois do banco: se algo falhar aqui, a
+	// próxima sincronização encontra o banco já combinado e refaz o commit.
+	if err := writeTasks(r, merged); err != nil {
+		return report, err
+	}
+	var parents []string
+	switch {
+	case head == "" && remote == "":
+	case head == "":
+		parents = []string{remote}
+	case remote == "" || r.isAncestor(remote, head):
+		parents = []string{head}
+	case r.isAncestor(head, remote):
+		parents = []string{remote}
+	default:
+		parents = []string{head, remote}
+	}
+	host, _ := os.Hostname()
+	report.Commit, err = r.commit(fmt.Sprintf("togo sync em %s: %d tarefas", host, len(merged)), parents...)
+	if err != nil {
+		return report, err
+	}
+
+	if report.Commit != remote {
+		if _, err := r.git(nil, "push", "-q", "origin", report.Commit+":refs/heads/"+branch); err != nil {
+			return report, fmt.Errorf("%w (o remoto mudou durante a sincronização? rode togo sync de novo)", err)
+		}
+		if _, err := r.git(nil, "update-ref", remoteRef, report.Commit); err != nil {
+			return report, err
+		}
+		report.Pushed = true
+	}
+	return report, nil
+}
+
+// loadLocal lê todas as tarefas do banco, inclusive as apagadas, por UUID,
+// junto com o ID local de cada uma.
+func loadLocal(tx *gorm.DB) (map[string]fields, map[string]uint, error) {
+	var tasks []schema.Task
+	if err := tx.Unscoped().Order("id asc").Find(&tasks).Error; err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler as tarefas: %w", err)
+	}
+
+	local := make(map[string]fields, len(tasks))
+	ids := make(map[string]uint, len(tasks))
+	for _, t := range tasks {
+		f, err := toFields(formats.NewRecord(t))
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao converter a tarefa %d: %w", t.ID, err)
+		}
+		local[t.UUID] = f
+		ids[t.UUID] = t.ID
+	}
+	return local, ids, nil
+}
+
+// apply grava no banco as tarefas combinadas que diferem das locais e
+// remove as que saíram do repositório.
+func apply(tx *gorm.DB, local map[string]fields, ids map[string]uint, merged map[string]fields, report *Report) error {
+	for id, m := range merged {
+		if l, ok := local[id]; ok && l.equal(m) {
+			continue
+		}
+		rec, err := m.record()
+		if err != nil {
+			return fmt.Errorf("tarefa %s inválida: %w", id, err)
+		}
+		t := rec.Task()
+		if taskID, ok := ids[id]; ok {
+			t.ID = taskID
+			if err := tx.Unscoped().Model(&schema.Task{}).Where("id = ?", taskID).Select("*").Omit("OwnerID").UpdateColumns(&t).Error; err != nil {
+				return fmt.Errorf("erro ao atualizar a tarefa %d: %w", taskID, err)
+			}
+			report.Updated++
+			continue
+		}
+		t.ID = 0
+		if err := tx.Create(&t).Error; err != nil {
+			return fmt.Errorf("erro ao criar '%s': %w", t.Description, err)
+		}
+		report.Created++
+	}
+
+	// Apagar uma tarefa a mantém no repositório com deleted_at; ela só some
+	// do repositório se o arquivo for removido lá, e então some daqui também.
+	for id, taskID := range ids {
+		if _, ok := merged[id]; ok {
+			continue
+		}
+		if err := tx.Unscoped().Delete(&schema.Task{}, taskID).Error; err != nil {
+			return fmt.Errorf("erro ao remover a tarefa %d: %w", taskID, err)
+		}
+		report.Removed++
+	}
+	return nil
+}
+
+// writeTasks reescreve o diretório tasks/ com as tarefas combinadas e
+// atualiza o índice.
+func writeTasks(r *repo, merged map[string]fields) error {
+	dir := filepath.Join(r.dir, tasksDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for id, f := range merged {
+		data, err := f.marshal()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, fileName(id)), data, 0644); err != nil {
+			return err
+		}
+	}
+	_, err := r.git(nil, "add", "-A", ".")
+	return err
+}
+
+// fileName é o nome do arquivo da tarefa. UUIDs vindos de outros formatos
+// (como o UID de um iCalendar) podem ter "/", então o nome é escapado.
+func fileName(id string) string {
+	return url.PathEscape(id) + ".json"
+}
+
+// idFromFileName desfaz fileName.
+func idFromFileName(name string) string {
+	name = strings.TrimSuffix(name, ".json")
+	if id, err := url.PathUnescape(name); err == nil {
+		return id
+	}
+	return name
+}
@@ -0,0 +1,157 @@
+package gitsync
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"levyvix/togo/internal/database"
+	"levyvix/togo/schema"
+)
+
+// newRemote creates an empty bare repository to act as the sync remote
+func newRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v\n%s", err, out)
+	}
+	return dir
+}
+
+// use makes the database at path the global one, closing the previous
+func use(t *testing.T, path string) {
+	t.Helper()
+	closeDB()
+	if err := database.Open(path); err != nil {
+		t.Fatalf("Open(%q) unexpected error: %v", path, err)
+	}
+	t.Cleanup(closeDB)
+}
+
+func closeDB() {
+	if database.DB == nil {
+		return
+	}
+	if sqlDB, err := database.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+	database.DB = nil
+}
+
+func mustSync(t *testing.T, remote string) Report {
+	t.Helper()
+	report, err := Sync(remote)
+	if err != nil {
+		t.Fatalf("Sync() unexpected error: %v", err)
+	}
+	return report
+}
+
+func findTask(t *testing.T, description string) schema.Task {
+	t.Helper()
+	var task schema.Task
+	if err := database.DB.Unscoped().Where("description = ?", description).First(&task).Error; err != nil {
+		t.Fatalf("task %q not found: %v", description, err)
+	}
+	return task
+}
+
+// TestSyncMergesConcurrentEdits tests two databases syncing through a bare remote
+func TestSyncMergesConcurrentEdits(t *testing.T) {
+	remote := newRemote(t)
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a", "tasks.db")
+	pathB := filepath.Join(dir, "b", "tasks.db")
+
+	use(t, pathA)
+	database.DB.Create(&schema.Task{Description: "Comprar pão"})
+	database.DB.Create(&schema.Task{Description: "Pagar conta"})
+	if r := mustSync(t, remote); !r.Pushed || r.Created != 0 {
+		t.Fatalf("first sync = %+v, want pushed with nothing created", r)
+	}
+
+	use(t, pathB)
+	database.DB.Create(&schema.Task{Description: "Ligar para o banco"})
+	if r := mustSync(t, "file://"+remote); r.Created != 2 || !r.Pushed {
+		t.Fatalf("sync on B = %+v, want 2 created and pushed", r)
+	}
+	// Sem mudanças, nada é commitado nem enviado.
+	if r := mustSync(t, ""); r.Pushed || r.Created+r.Updated+r.Removed != 0 {
+		t.Fatalf("idempotent sync = %+v, want no changes", r)
+	}
+
+	// A muda a descrição, B a prioridade da mesma tarefa; A apaga outra.
+	use(t, pathA)
+	mustSync(t, "")
+	pao := findTask(t, "Comprar pão")
+	database.DB.Model(&pao).Update("description", "Comprar pão integral")
+	conta := findTask(t, "Pagar conta")
+	database.DB.Delete(&conta)
+
+	use(t, pathB)
+	paoB := findTask(t, "Comprar pão")
+	database.DB.Model(&paoB).Update("priority", "A")
+	mustSync(t, "")
+
+	use(t, pathA)
+	if r := mustSync(t, ""); len(r.Conflicts) != 0 {
+		t.Errorf("sync on A reported conflicts %v, want none", r.Conflicts)
+	}
+	use(t, pathB)
+	mustSync(t, "")
+
+	for _, path := range []string{pathA, pathB} {
+		use(t, path)
+		got := findTask(t, "Comprar pão integral")
+		if got.Priority != "A" {
+			t.Errorf("%s: priority = %q, want A", path, got.Priority)
+		}
+		if !findTask(t, "Pagar conta").DeletedAt.Valid {
+			t.Errorf("%s: deleted task should be soft-deleted", path)
+		}
+		var count int64
+		database.DB.Unscoped().Model(&schema.Task{}).Count(&count)
+		if count != 3 {
+			t.Errorf("%s: %d tasks, want 3", path, count)
+		}
+	}
+}
+
+// TestSyncConflictNewestWins tests that the most recent edit wins a field edited on both sides
+func TestSyncConflictNewestWins(t *testing.T) {
+	remote := newRemote(t)
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a", "tasks.db")
+	pathB := filepath.Join(dir, "b", "tasks.db")
+
+	use(t, pathA)
+	database.DB.Create(&schema.Task{Description: "Relatório"})
+	mustSync(t, remote)
+	use(t, pathB)
+	mustSync(t, remote)
+
+	use(t, pathA)
+	task := findTask(t, "Relatório")
+	database.DB.Model(&task).Update("description", "Relatório (A)")
+	use(t, pathB)
+	task = findTask(t, "Relatório")
+	database.DB.Model(&task).Update("description", "Relatório (B)")
+
+	use(t, pathA)
+	mustSync(t, "")
+	use(t, pathB)
+	if r := mustSync(t, ""); len(r.Conflicts) != 1 {
+		t.Errorf("Conflicts = %v, want one", r.Conflicts)
+	}
+	use(t, pathA)
+	mustSync(t, "")
+
+	for _, path := range []string{pathA, pathB} {
+		use(t, path)
+		findTask(t, "Relatório (B)")
+	}
+}
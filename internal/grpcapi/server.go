@@ -0,0 +1,377 @@
+// Package grpcapi implementa o TaskService (api/togo/v1) do togo serve
+// --grpc sobre o serviço de tarefas, como a API REST: mesmas validações,
+// mesmos usuários e os mesmos eventos no Watch.
+//
+// Com usuários cadastrados, toda chamada precisa de um token nos metadados
+// "authorization: Bearer <token>".
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	togov1 "levyvix/togo/api/togo/v1"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// taskService é o TaskService sobre o banco global.
+type taskService struct {
+	togov1.UnimplementedTaskServiceServer
+	tasks service.Tasks
+	users service.Users
+}
+
+// New cria o servidor gRPC com o TaskService e a autenticação.
+func New() *grpc.Server {
+	s := &taskService{}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryAuth),
+		grpc.ChainStreamInterceptor(s.streamAuth),
+	)
+	togov1.RegisterTaskServiceServer(srv, s)
+	return srv
+}
+
+func (s *taskService) unaryAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return handler(ctx, req)
+}
+
+func (s *taskService) streamAuth(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return toStatus(err)
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// authStream troca o contexto do fluxo pelo que tem o usuário.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authStream) Context() context.Context { return a.ctx }
+
+// authenticate lê o token Bearer dos metadados e põe o usuário no contexto.
+// Sem token, a chamada só é aceita se não houver usuários cadastrados.
+func (s *taskService) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		exists, err := s.users.Any(ctx)
+		if err != nil {
+			return ctx, err
+		}
+		if exists {
+			return ctx, fmt.Errorf("envie o token nos metadados authorization: Bearer <token>: %w", service.ErrUnauthorized)
+		}
+		return ctx, nil
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return ctx, fmt.Errorf("use os metadados authorization: Bearer <token>: %w", service.ErrUnauthorized)
+	}
+	user, err := s.users.Authenticate(ctx, strings.TrimSpace(token))
+	if err != nil {
+		return ctx, err
+	}
+	return service.WithUser(ctx, user), nil
+}
+
+func (s *taskService) Create(ctx context.Context, req *togov1.CreateTaskRequest) (*togov1.Task, error) {
+	task := req.GetTask()
+	if task == nil {
+		task = &togov1.Task{}
+	}
+	p, err := patch(task, filled(task, true))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	t, err := s.tasks.Create(ctx, p)
+	return s.reply(ctx, t, err)
+}
+
+func (s *taskService) Get(ctx context.Context, req *togov1.GetTaskRequest) (*togov1.Task, error) {
+	t, err := s.tasks.Get(ctx, req.GetId())
+	return s.reply(ctx, t, err)
+}
+
+func (s *taskService) List(ctx context.Context, req *togov1.ListTasksRequest) (*togov1.ListTasksResponse, error) {
+	page, err := s.tasks.List(ctx, service.Filter{
+		Status:  req.GetStatus(),
+		Query:   req.GetQuery(),
+		Page:    int(req.GetPage()),
+		PerPage: int(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	tasks, err := s.messages(ctx, page.Tasks)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &togov1.ListTasksResponse{Tasks: tasks, Page: int32(page.Page), PageSize: int32(page.PerPage), Total: page.Total}, nil
+}
+
+func (s *taskService) Update(ctx context.Context, req *togov1.UpdateTaskRequest) (*togov1.Task, error) {
+	task := req.GetTask()
+	if task == nil {
+		task = &togov1.Task{}
+	}
+	paths := req.GetUpdateMask().GetPaths()
+	if req.GetUpdateMask() == nil {
+		paths = filled(task, false)
+	}
+	p, err := patch(task, paths)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	t, err := s.tasks.Update(ctx, req.GetId(), p)
+	return s.reply(ctx, t, err)
+}
+
+func (s *taskService) Complete(ctx context.Context, req *togov1.CompleteTaskRequest) (*togov1.Task, error) {
+	t, err := s.tasks.Complete(ctx, req.GetId())
+	return s.reply(ctx, t, err)
+}
+
+func (s *taskService) Delete(ctx context.Context, req *togov1.DeleteTaskRequest) (*togov1.Task, error) {
+	t, err := s.tasks.Delete(ctx, req.GetId())
+	return s.reply(ctx, t, err)
+}
+
+// Watch envia os eventos do barramento que o usuário pode ver: primeiro os
+// perdidos depois de last_event_id, depois os novos, até o cliente cancelar.
+func (s *taskService) Watch(req *togov1.WatchRequest, stream grpc.ServerStreamingServer[togov1.TaskEvent]) error {
+	ctx := stream.Context()
+	// A inscrição vem antes dos cabeçalhos: o que for publicado depois que
+	// o cliente os recebe chega a ele.
+	sub := service.Bus.Subscribe(req.GetLastEventId())
+	defer sub.Close()
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	send := func(e events.Event) error {
+		out, ok, err := s.event(ctx, e)
+		if err != nil {
+			return toStatus(err)
+		}
+		if !ok {
+			return nil
+		}
+		return stream.Send(out)
+	}
+	for _, e := range sub.Missed {
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case e, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Aborted, "o cliente ficou para trás na fila de eventos; chame Watch de novo com last_event_id")
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventTypes traduz os tipos do barramento para o enum da API.
+var eventTypes = map[events.Type]togov1.TaskEvent_Type{
+	events.Created: togov1.TaskEvent_CREATED,
+	events.Updated: togov1.TaskEvent_UPDATED,
+	events.Done:    togov1.TaskEvent_DONE,
+	events.Deleted: togov1.TaskEvent_DELETED,
+	events.Cleared: togov1.TaskEvent_CLEARED,
+	events.Reset:   togov1.TaskEvent_RESET,
+}
+
+// event converte e para a API, se o usuário do contexto puder ver a tarefa.
+func (s *taskService) event(ctx context.Context, e events.Event) (*togov1.TaskEvent, bool, error) {
+	out := &togov1.TaskEvent{Id: e.ID, Type: eventTypes[e.Type], Time: timestamppb.New(e.Time)}
+	if e.Task == nil {
+		return out, true, nil
+	}
+	visible, err := s.tasks.Visible(ctx, *e.Task)
+	if err != nil || !visible {
+		return nil, false, err
+	}
+	tasks, err := s.messages(ctx, []schema.Task{*e.Task})
+	if err != nil {
+		return nil, false, err
+	}
+	out.Task = tasks[0]
+	return out, true, nil
+}
+
+// reply converte o resultado de uma operação sobre uma tarefa.
+func (s *taskService) reply(ctx context.Context, t schema.Task, err error) (*togov1.Task, error) {
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	tasks, err := s.messages(ctx, []schema.Task{t})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return tasks[0], nil
+}
+
+// messages converte as tarefas para a API, com o dono e os
+// compartilhamentos.
+func (s *taskService) messages(ctx context.Context, tasks []schema.Task) ([]*togov1.Task, error) {
+	access, err := s.tasks.Access(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*togov1.Task, 0, len(tasks))
+	for _, t := range tasks {
+		a := access[t.ID]
+		out = append(out, &togov1.Task{
+			Id:          uint64(t.ID),
+			Uuid:        t.UUID,
+			Description: t.Description,
+			Done:        t.Done,
+			Priority:    t.Priority,
+			Projects:    t.Projects,
+			Contexts:    t.Contexts,
+			Tags:        t.Tags,
+			Due:         timestamp(t.Due),
+			Scheduled:   timestamp(t.Scheduled),
+			Recurrence:  t.Recurrence,
+			CreatedAt:   timestamppb.New(t.CreatedAt),
+			UpdatedAt:   timestamppb.New(t.UpdatedAt),
+			DoneAt:      timestamp(t.DoneAt),
+			DeletedAt:   deletedAt(t),
+			Owner:       a.Owner,
+			SharedWith:  a.SharedWith,
+		})
+	}
+	return out, nil
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func deletedAt(t schema.Task) *timestamppb.Timestamp {
+	if !t.DeletedAt.Valid {
+		return nil
+	}
+	return timestamppb.New(t.DeletedAt.Time)
+}
+
+// editable são os campos de Task que Create e Update alteram.
+var editable = []string{"description", "done", "priority", "projects", "contexts", "tags", "due", "scheduled", "recurrence"}
+
+// filled retorna os campos editáveis preenchidos de task; com description,
+// a descrição entra mesmo vazia, para que a validação a recuse.
+func filled(task *togov1.Task, description bool) []string {
+	var paths []string
+	if description || task.GetDescription() != "" {
+		paths = append(paths, "description")
+	}
+	if task.GetDone() {
+		paths = append(paths, "done")
+	}
+	if task.GetPriority() != "" {
+		paths = append(paths, "priority")
+	}
+	if len(task.GetProjects()) > 0 {
+		paths = append(paths, "projects")
+	}
+	if len(task.GetContexts()) > 0 {
+		paths = append(paths, "contexts")
+	}
+	if len(task.GetTags()) > 0 {
+		paths = append(paths, "tags")
+	}
+	if task.GetDue() != nil {
+		paths = append(paths, "due")
+	}
+	if task.GetScheduled() != nil {
+		paths = append(paths, "scheduled")
+	}
+	if task.GetRecurrence() != "" {
+		paths = append(paths, "recurrence")
+	}
+	return paths
+}
+
+// patch monta o service.Patch com os campos paths de task.
+func patch(task *togov1.Task, paths []string) (service.Patch, error) {
+	var p service.Patch
+	for _, path := range paths {
+		switch path {
+		case "description":
+			p.Description = service.Some(task.GetDescription())
+		case "done":
+			p.Done = service.Some(task.GetDone())
+		case "priority":
+			p.Priority = service.Some(task.GetPriority())
+		case "projects":
+			p.Projects = service.Some(task.GetProjects())
+		case "contexts":
+			p.Contexts = service.Some(task.GetContexts())
+		case "tags":
+			p.Tags = service.Some(task.GetTags())
+		case "due":
+			p.Due = service.Some(optionalTime(task.GetDue()))
+		case "scheduled":
+			p.Scheduled = service.Some(optionalTime(task.GetScheduled()))
+		case "recurrence":
+			p.Recurrence = service.Some(task.GetRecurrence())
+		default:
+			return p, fmt.Errorf("campo '%s' não pode ser alterado (use %s): %w", path, strings.Join(editable, ", "), service.ErrInvalid)
+		}
+	}
+	return p, nil
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+// toStatus traduz a categoria do erro do serviço em código gRPC.
+func toStatus(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, service.ErrInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, service.ErrConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, service.ErrUnauthorized):
+		code = codes.Unauthenticated
+	case errors.Is(err, service.ErrForbidden):
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,204 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	togov1 "levyvix/togo/api/togo/v1"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestClient opens an empty database and serves the TaskService over it in memory
+func newTestClient(t *testing.T) togov1.TaskServiceClient {
+	t.Helper()
+	t.Setenv("TOGO_SNAPSHOTS", "0")
+	if err := database.Open(filepath.Join(t.TempDir(), "tasks.db")); err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := New()
+	go srv.Serve(lis)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+		database.DB = nil
+	})
+	return togov1.NewTaskServiceClient(conn)
+}
+
+// withToken returns a context that sends the bearer token
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// wantCode checks the gRPC status code of err
+func wantCode(t *testing.T, what string, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Errorf("%s error = %v, want %s", what, err, code)
+	}
+}
+
+// TestTasks tests the unary methods
+func TestTasks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	created, err := client.Create(ctx, &togov1.CreateTaskRequest{Task: &togov1.Task{
+		Description: "Estudar Go",
+		Tags:        []string{"go"},
+		Due:         timestamppb.New(due),
+		Id:          99,
+	}})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if created.Id == 99 || created.Uuid == "" || !created.Due.AsTime().Equal(due) || len(created.Tags) != 1 {
+		t.Errorf("Create() = %v", created)
+	}
+	client.Create(ctx, &togov1.CreateTaskRequest{Task: &togov1.Task{Description: "Revisar PR"}})
+
+	got, err := client.Get(ctx, &togov1.GetTaskRequest{Id: created.Uuid[:8]})
+	if err != nil || got.Id != created.Id {
+		t.Errorf("Get(uuid prefix) = %v, %v", got, err)
+	}
+
+	// The mask clears the due date and leaves the tags alone.
+	updated, err := client.Update(ctx, &togov1.UpdateTaskRequest{
+		Id:         "1",
+		Task:       &togov1.Task{Priority: "A"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"priority", "due"}},
+	})
+	if err != nil || updated.Priority != "A" || updated.Due != nil || len(updated.Tags) != 1 {
+		t.Errorf("Update(mask) = %v, %v, want priority A, no due date and the tag kept", updated, err)
+	}
+	updated, err = client.Update(ctx, &togov1.UpdateTaskRequest{Id: "1", Task: &togov1.Task{Description: "Estudar gRPC"}})
+	if err != nil || updated.Description != "Estudar gRPC" || updated.Priority != "A" {
+		t.Errorf("Update(no mask) = %v, %v, want only the description changed", updated, err)
+	}
+
+	done, err := client.Complete(ctx, &togov1.CompleteTaskRequest{Id: "1"})
+	if err != nil || !done.Done || done.DoneAt == nil {
+		t.Errorf("Complete() = %v, %v", done, err)
+	}
+	list, err := client.List(ctx, &togov1.ListTasksRequest{Status: "pending"})
+	if err != nil || list.Total != 1 || len(list.Tasks) != 1 || list.Tasks[0].Description != "Revisar PR" || list.PageSize != 50 {
+		t.Errorf("List(pending) = %v, %v", list, err)
+	}
+	deleted, err := client.Delete(ctx, &togov1.DeleteTaskRequest{Id: "2"})
+	if err != nil || deleted.DeletedAt == nil {
+		t.Errorf("Delete() = %v, %v, want deleted_at set", deleted, err)
+	}
+
+	_, err = client.Create(ctx, &togov1.CreateTaskRequest{})
+	wantCode(t, "Create(empty)", err, codes.InvalidArgument)
+	_, err = client.Get(ctx, &togov1.GetTaskRequest{Id: "2"})
+	wantCode(t, "Get(deleted)", err, codes.NotFound)
+	_, err = client.Complete(ctx, &togov1.CompleteTaskRequest{Id: "1"})
+	wantCode(t, "Complete(done)", err, codes.FailedPrecondition)
+	_, err = client.Update(ctx, &togov1.UpdateTaskRequest{Id: "1", UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"id"}}})
+	wantCode(t, "Update(id)", err, codes.InvalidArgument)
+	_, err = client.List(ctx, &togov1.ListTasksRequest{Status: "late"})
+	wantCode(t, "List(late)", err, codes.InvalidArgument)
+}
+
+// TestAuth tests tokens and task ownership
+func TestAuth(t *testing.T) {
+	client := newTestClient(t)
+	users := service.Users{}
+	bg := context.Background()
+	users.Add(bg, "ana", false)
+	users.Add(bg, "bruno", false)
+	ana, _, _ := users.CreateToken(bg, "ana", "teste")
+	bruno, _, _ := users.CreateToken(bg, "bruno", "teste")
+
+	_, err := client.List(bg, &togov1.ListTasksRequest{})
+	wantCode(t, "List(no token)", err, codes.Unauthenticated)
+	_, err = client.List(withToken("togo_invalido"), &togov1.ListTasksRequest{})
+	wantCode(t, "List(bad token)", err, codes.Unauthenticated)
+
+	task, err := client.Create(withToken(ana), &togov1.CreateTaskRequest{Task: &togov1.Task{Description: "Da Ana"}})
+	if err != nil || task.Owner != "ana" {
+		t.Fatalf("Create() = %v, %v, want owner ana", task, err)
+	}
+	_, err = client.Get(withToken(bruno), &togov1.GetTaskRequest{Id: "1"})
+	wantCode(t, "Get(other user)", err, codes.NotFound)
+
+	stream, err := client.Watch(bg, &togov1.WatchRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	wantCode(t, "Watch(no token)", err, codes.Unauthenticated)
+
+	ctx, cancel := context.WithCancel(withToken(bruno))
+	defer cancel()
+	stream, err = client.Watch(ctx, &togov1.WatchRequest{})
+	if err == nil {
+		_, err = stream.Header()
+	}
+	if err != nil {
+		t.Errorf("Watch() with a valid token error = %v", err)
+	}
+}
+
+// TestWatch tests the event stream and resuming after last_event_id
+func TestWatch(t *testing.T) {
+	client := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &togov1.WatchRequest{})
+	if err != nil {
+		t.Fatalf("Watch() unexpected error: %v", err)
+	}
+	// The headers arrive after the subscription, so nothing is missed from here on.
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("Header() unexpected error: %v", err)
+	}
+
+	client.Create(ctx, &togov1.CreateTaskRequest{Task: &togov1.Task{Description: "Estudar Go"}})
+	client.Complete(ctx, &togov1.CompleteTaskRequest{Id: "1"})
+
+	first, err := stream.Recv()
+	if err != nil || first.Type != togov1.TaskEvent_CREATED || first.Task.GetDescription() != "Estudar Go" {
+		t.Fatalf("Recv() = %v, %v, want the created event", first, err)
+	}
+	second, err := stream.Recv()
+	if err != nil || second.Type != togov1.TaskEvent_DONE || !second.Task.GetDone() || second.Id == first.Id {
+		t.Fatalf("Recv() = %v, %v, want the done event", second, err)
+	}
+
+	resumed, err := client.Watch(ctx, &togov1.WatchRequest{LastEventId: first.Id})
+	if err != nil {
+		t.Fatalf("Watch(last_event_id) unexpected error: %v", err)
+	}
+	missed, err := resumed.Recv()
+	if err != nil || missed.Id != second.Id {
+		t.Errorf("Recv() after resuming = %v, %v, want %s", missed, err, second.Id)
+	}
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+	"os"
+	"strings"
+	"time"
+)
+
+func HooksListFuncDB(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	dir, err := hooks.Dir()
+	if err != nil {
+		return err
+	}
+	if os.Getenv(hooks.DisableEnv) == "0" {
+		fmt.Printf("Hooks desativados por %s=0.\n", hooks.DisableEnv)
+	}
+	list, err := hooks.List(dir)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Printf("Nenhum hook em %s.\n", dir)
+		return nil
+	}
+	fmt.Printf("Hooks em %s:\n", dir)
+	for _, h := range list {
+		note := ""
+		if !h.Executable {
+			note = "  (não executável, ignorado)"
+		}
+		fmt.Printf("  %-10s %s%s\n", h.Event, h.Name, note)
+	}
+	return nil
+}
+
+// HooksTestFuncDB roda os hooks de um evento com uma tarefa, sem gravar
+// nada: a tarefa de ref (obrigatória fora do on-add), com a descrição
+// trocada por description, se houver.
+func HooksTestFuncDB(args []string, description string, post bool) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("use: togo hooks test <evento> [id], você passou %d argumentos", len(args))
+	}
+	event, err := hooks.ParseEvent(args[0])
+	if err != nil {
+		return err
+	}
+
+	var t schema.Task
+	switch {
+	case len(args) == 2:
+		if t, err = service.Resolve(database.DB, args[1]); err != nil {
+			return err
+		}
+	case event != hooks.Add:
+		return fmt.Errorf("o evento %s precisa do ID de uma tarefa", event)
+	default:
+		t.CreatedAt = time.Now()
+	}
+	if strings.TrimSpace(description) != "" {
+		t.Description = strings.TrimSpace(description)
+	}
+	if strings.TrimSpace(t.Description) == "" {
+		return fmt.Errorf("informe a descrição da tarefa com --description")
+	}
+	if event == hooks.Done && !t.Done {
+		now := time.Now()
+		t.Done, t.DoneAt = true, &now
+	}
+
+	runner, err := hooks.Default()
+	if err != nil {
+		return err
+	}
+	if runner.Dir == "" {
+		return fmt.Errorf("hooks desativados por %s=0", hooks.DisableEnv)
+	}
+	t, err = runner.Pre(event, t)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(formats.NewRecord(t))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Hooks pre do %s aceitaram a tarefa (nada foi gravado):\n%s\n", event, data)
+	if post {
+		if err := runner.Post(event, t); err != nil {
+			fmt.Println("Aviso:", err)
+		}
+	}
+	return nil
+}
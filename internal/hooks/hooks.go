@@ -0,0 +1,340 @@
+// Package hooks roda os scripts de ~/.togo/hooks antes e depois das
+// alterações de tarefas, pela linha de comando, pelo togo serve ou pelo
+// togo rpc. Cada hook recebe o evento no nome (on-add, on-modify, on-done,
+// on-delete, com qualquer sufixo: on-add.ticket), a fase (pre ou post) como argumento e a tarefa em JSON na entrada padrão.
+//
+// Um hook pre pode recusar a alteração, saindo com código diferente de
+// zero (a saída de erro vira a mensagem), ou alterá-la, escrevendo a tarefa
+// modificada em JSON na primeira linha da saída. As demais linhas da saída
+// são mostradas ao usuário. Os hooks post só avisam; falhas viram avisos.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"levyvix/togo/internal/config"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/schema"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event é o evento de um hook, que também é o começo do nome do arquivo.
+type Event string
+
+const (
+	Add    Event = "on-add"
+	Modify Event = "on-modify"
+	Done   Event = "on-done"
+	Delete Event = "on-delete"
+)
+
+// Events são os eventos, na ordem da ajuda.
+var Events = []Event{Add, Modify, Done, Delete}
+
+// Fases em que os hooks rodam, passadas como argumento.
+const (
+	Pre  = "pre"
+	Post = "post"
+)
+
+// DisableEnv desativa os hooks quando vale 0; TimeoutEnv muda o tempo
+// limite de cada hook (uma duração do Go, como 30s).
+const (
+	DisableEnv = "TOGO_HOOKS"
+	TimeoutEnv = "TOGO_HOOK_TIMEOUT"
+)
+
+// DefaultTimeout é o tempo limite padrão de cada hook.
+const DefaultTimeout = 10 * time.Second
+
+// ErrRefused é a categoria do erro de Pre quando um hook recusa a
+// alteração, para errors.Is.
+var ErrRefused = errors.New("alteração recusada por um hook")
+
+// refusedError é a recusa de um hook, com a mensagem dele.
+type refusedError struct{ msg string }
+
+func (e *refusedError) Error() string { return e.msg }
+
+func (e *refusedError) Unwrap() error { return ErrRefused }
+
+// ParseEvent aceita o evento com ou sem o "on-" (add ou on-add).
+func ParseEvent(name string) (Event, error) {
+	for _, e := range Events {
+		if name == string(e) || "on-"+name == string(e) {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("evento desconhecido '%s' (use add, modify, done ou delete)", name)
+}
+
+// Hook é um arquivo do diretório de hooks.
+type Hook struct {
+	Name       string
+	Path       string
+	Event      Event
+	Executable bool
+}
+
+// Dir retorna o diretório dos hooks: ~/.togo/hooks.
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "hooks"), nil
+}
+
+// List retorna os hooks de dir em ordem de nome, que é a ordem em que rodam.
+// Arquivos cujo nome não começa com um evento são ignorados.
+func List(dir string) ([]Hook, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", dir, err)
+	}
+	var hooks []Hook
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, e := range Events {
+			if !strings.HasPrefix(entry.Name(), string(e)) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			hooks = append(hooks, Hook{
+				Name:       entry.Name(),
+				Path:       filepath.Join(dir, entry.Name()),
+				Event:      e,
+				Executable: info.Mode()&0111 != 0,
+			})
+		}
+	}
+	sort.Slice(hooks, func(i, j int) bool { return hooks[i].Name < hooks[j].Name })
+	return hooks, nil
+}
+
+// Runner roda os hooks de um diretório.
+type Runner struct {
+	// Dir é o diretório dos hooks; vazio desativa os hooks.
+	Dir     string
+	Timeout time.Duration
+	// Out recebe as mensagens dos hooks.
+	Out io.Writer
+}
+
+// Default retorna o Runner de ~/.togo/hooks, configurado pelas variáveis
+// TOGO_HOOKS e TOGO_HOOK_TIMEOUT.
+func Default() (*Runner, error) {
+	r := &Runner{Timeout: DefaultTimeout, Out: os.Stdout}
+	if os.Getenv(DisableEnv) == "0" {
+		return r, nil
+	}
+	if v := os.Getenv(TimeoutEnv); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("%s inválido '%s': use uma duração como 30s", TimeoutEnv, v)
+		}
+		r.Timeout = timeout
+	}
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	r.Dir = dir
+	return r, nil
+}
+
+// hooks retorna os hooks executáveis do evento.
+func (r *Runner) hooks(e Event) ([]Hook, error) {
+	if r.Dir == "" {
+		return nil, nil
+	}
+	all, err := List(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []Hook
+	for _, h := range all {
+		if h.Event == e && h.Executable {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks, nil
+}
+
+// Pre roda os hooks pre do evento, em ordem, cada um recebendo a tarefa
+// devolvida pelo anterior, e retorna a tarefa final. Um hook que recusa
+// interrompe a cadeia com erro. No on-delete, as alterações são ignoradas.
+func (r *Runner) Pre(e Event, t schema.Task) (schema.Task, error) {
+	hooks, err := r.hooks(e)
+	if err != nil {
+		return t, err
+	}
+	for _, h := range hooks {
+		out, err := r.run(h, Pre, t)
+		if err != nil {
+			return t, err
+		}
+		modified, feedback, err := parseOutput(out)
+		if err != nil {
+			return t, fmt.Errorf("hook %s: %w", h.Name, err)
+		}
+		r.show(feedback)
+		if modified != nil && e != Delete {
+			if err := validate(*modified); err != nil {
+				return t, fmt.Errorf("hook %s devolveu uma tarefa inválida: %w", h.Name, err)
+			}
+			CopyEdits(&t, modified.Task())
+		}
+	}
+	return t, nil
+}
+
+// Post roda os hooks post do evento. Todos rodam; as falhas são
+// retornadas juntas, como avisos.
+func (r *Runner) Post(e Event, t schema.Task) error {
+	hooks, err := r.hooks(e)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, h := range hooks {
+		out, err := r.run(h, Post, t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// A tarefa devolvida por um hook que não olha a fase é ignorada.
+		_, feedback := splitOutput(out)
+		r.show(feedback)
+	}
+	return errors.Join(errs...)
+}
+
+// run executa um hook com a tarefa na entrada e retorna a saída padrão.
+func (r *Runner) run(h Hook, phase string, t schema.Task) ([]byte, error) {
+	input, err := json.Marshal(formats.NewRecord(t))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.Path, phase)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "TOGO_HOOK_EVENT="+string(h.Event), "TOGO_HOOK_PHASE="+phase)
+	// Processos filhos que herdaram a saída não seguram o togo.
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("hook %s excedeu o tempo limite de %s", h.Name, r.Timeout)
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = fmt.Sprintf("saiu com código %d", exit.ExitCode())
+		}
+		if phase == Pre {
+			return nil, &refusedError{msg: fmt.Sprintf("hook %s recusou a alteração: %s", h.Name, msg)}
+		}
+		return nil, fmt.Errorf("hook %s falhou: %s", h.Name, msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar o hook %s: %w", h.Name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// splitOutput separa a primeira linha não vazia, se ela começar com "{",
+// das mensagens na saída de um hook.
+func splitOutput(out []byte) (task string, feedback []string) {
+	first := true
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		if first && strings.TrimSpace(line) == "" {
+			continue
+		}
+		if first && strings.HasPrefix(strings.TrimSpace(line), "{") {
+			task = line
+		} else {
+			feedback = append(feedback, line)
+		}
+		first = false
+	}
+	return task, feedback
+}
+
+// parseOutput lê a tarefa e as mensagens da saída de um hook pre.
+func parseOutput(out []byte) (*formats.Record, []string, error) {
+	task, feedback := splitOutput(out)
+	if task == "" {
+		return nil, feedback, nil
+	}
+	var rec formats.Record
+	dec := json.NewDecoder(strings.NewReader(task))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rec); err != nil {
+		return nil, nil, fmt.Errorf("JSON inválido na saída: %w", err)
+	}
+	return &rec, feedback, nil
+}
+
+// validate confere os campos que um hook pode alterar.
+func validate(r formats.Record) error {
+	if strings.TrimSpace(r.Description) == "" {
+		return errors.New("a descrição não pode estar vazia")
+	}
+	if p := r.Priority; p != "" && (len(p) != 1 || p[0] < 'A' || p[0] > 'Z') {
+		return fmt.Errorf("prioridade inválida '%s' (use uma letra de A a Z)", p)
+	}
+	return nil
+}
+
+// CopyEdits copia de src para dst os campos que os hooks podem alterar.
+// ID, UUID, datas de criação e o estado de conclusão ficam de fora.
+func CopyEdits(dst *schema.Task, src schema.Task) {
+	dst.Description = src.Description
+	dst.Priority = src.Priority
+	dst.Projects = src.Projects
+	dst.Contexts = src.Contexts
+	dst.Extras = src.Extras
+	dst.Due = src.Due
+	dst.Scheduled = src.Scheduled
+	dst.Recurrence = src.Recurrence
+	dst.Tags = src.Tags
+	dst.Annotations = src.Annotations
+}
+
+func (r *Runner) show(lines []string) {
+	if r.Out == nil {
+		return
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			fmt.Fprintln(r.Out, line)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/schema"
+)
+
+// writeHook writes an executable shell script into dir
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newRunner returns a runner over an empty hooks directory
+func newRunner(t *testing.T) (*Runner, *bytes.Buffer) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks de teste são scripts sh")
+	}
+	var out bytes.Buffer
+	return &Runner{Dir: t.TempDir(), Timeout: 5 * time.Second, Out: &out}, &out
+}
+
+// TestList tests discovering hooks by event prefix
+func TestList(t *testing.T) {
+	r, _ := newRunner(t)
+	writeHook(t, r.Dir, "on-done", "true")
+	writeHook(t, r.Dir, "on-add.ticket", "true")
+	os.WriteFile(filepath.Join(r.Dir, "on-add.rascunho"), []byte("true"), 0644)
+	os.WriteFile(filepath.Join(r.Dir, "README"), []byte("notas"), 0644)
+	os.Mkdir(filepath.Join(r.Dir, "on-modify.d"), 0755)
+
+	hooks, err := List(r.Dir)
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	want := []Hook{
+		{Name: "on-add.rascunho", Event: Add, Executable: false},
+		{Name: "on-add.ticket", Event: Add, Executable: true},
+		{Name: "on-done", Event: Done, Executable: true},
+	}
+	if len(hooks) != len(want) {
+		t.Fatalf("List() = %+v, want %+v", hooks, want)
+	}
+	for i, h := range hooks {
+		if h.Name != want[i].Name || h.Event != want[i].Event || h.Executable != want[i].Executable {
+			t.Errorf("List()[%d] = %+v, want %+v", i, h, want[i])
+		}
+	}
+
+	if hooks, err := List(filepath.Join(r.Dir, "nada")); err != nil || hooks != nil {
+		t.Errorf("List(missing dir) = %v, %v, want nil, nil", hooks, err)
+	}
+}
+
+// TestPreReject tests that a failing pre hook rejects the change with its message
+func TestPreReject(t *testing.T) {
+	r, _ := newRunner(t)
+	writeHook(t, r.Dir, "on-add.ticket", `grep -q '"description":"[A-Z]*-[0-9]' || { echo "a descrição precisa de um ticket" >&2; exit 1; }`)
+
+	_, err := r.Pre(Add, schema.Task{Description: "Corrigir login"})
+	if err == nil || !strings.Contains(err.Error(), "a descrição precisa de um ticket") {
+		t.Errorf("Pre() error = %v, want rejection with the hook's message", err)
+	}
+	if _, err := r.Pre(Add, schema.Task{Description: "PROJ-12 Corrigir login"}); err != nil {
+		t.Errorf("Pre() unexpected error: %v", err)
+	}
+	if _, err := r.Pre(Modify, schema.Task{Description: "Corrigir login"}); err != nil {
+		t.Errorf("Pre(on-modify) ran an on-add hook: %v", err)
+	}
+}
+
+// TestPreModify tests that pre hooks can change the task, in order
+func TestPreModify(t *testing.T) {
+	r, out := newRunner(t)
+	writeHook(t, r.Dir, "on-add.1-tag", `sed 's/"done":false/"done":false,"tags":["auto"]/'; echo "tag adicionada"`)
+	writeHook(t, r.Dir, "on-add.2-priority", `sed 's/"id":0/"id":99/; s/"done":false/"done":false,"priority":"A"/'`)
+
+	got, err := r.Pre(Add, schema.Task{Description: "Estudar Go"})
+	if err != nil {
+		t.Fatalf("Pre() unexpected error: %v", err)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "auto" || got.Priority != "A" {
+		t.Errorf("Pre() = tags %v, priority %q, want [auto] and A", got.Tags, got.Priority)
+	}
+	if got.ID != 0 {
+		t.Errorf("Pre() changed the ID to %d", got.ID)
+	}
+	if out.String() != "tag adicionada\n" {
+		t.Errorf("feedback = %q, want %q", out.String(), "tag adicionada\n")
+	}
+
+	got, err = r.Pre(Delete, schema.Task{Description: "Estudar Go"})
+	if err != nil || len(got.Tags) != 0 {
+		t.Errorf("Pre(on-delete) = %v, %v, want the task unchanged", got.Tags, err)
+	}
+}
+
+// TestPreInvalidOutput tests that malformed hook output is an error
+func TestPreInvalidOutput(t *testing.T) {
+	for name, script := range map[string]string{
+		"bad json":      `echo '{"description":'`,
+		"unknown field": `echo '{"description":"x","color":"red"}'`,
+		"empty desc":    `echo '{"description":" "}'`,
+		"bad priority":  `echo '{"description":"x","priority":"alta"}'`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, _ := newRunner(t)
+			writeHook(t, r.Dir, "on-modify", script)
+			if _, err := r.Pre(Modify, schema.Task{Description: "Estudar Go"}); err == nil {
+				t.Error("Pre() expected error, got nil")
+			}
+		})
+	}
+}
+
+// TestTimeout tests that a hook that runs too long is stopped
+func TestTimeout(t *testing.T) {
+	r, _ := newRunner(t)
+	r.Timeout = 100 * time.Millisecond
+	writeHook(t, r.Dir, "on-done", "sleep 5")
+
+	start := time.Now()
+	_, err := r.Pre(Done, schema.Task{Description: "Estudar Go"})
+	if err == nil || !strings.Contains(err.Error(), "tempo limite") {
+		t.Errorf("Pre() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Pre() took %v, want it stopped at the timeout", elapsed)
+	}
+}
+
+// TestPost tests the phase argument, environment and warnings of post hooks
+func TestPost(t *testing.T) {
+	r, out := newRunner(t)
+	log := filepath.Join(r.Dir, "log")
+	writeHook(t, r.Dir, "on-done.a", `echo "$1 $TOGO_HOOK_EVENT $TOGO_HOOK_PHASE" >> `+log+`; cat >> `+log)
+	writeHook(t, r.Dir, "on-done.b", `echo "falhou" >&2; exit 3`)
+	writeHook(t, r.Dir, "on-done.c", `cat; echo "avisado"`)
+
+	err := r.Post(Done, schema.Task{Description: "Estudar Go", Done: true})
+	if err == nil || !strings.Contains(err.Error(), "on-done.b falhou: falhou") {
+		t.Errorf("Post() error = %v, want the failure of on-done.b", err)
+	}
+	data, _ := os.ReadFile(log)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != "post on-done post" || !strings.Contains(lines[1], `"description":"Estudar Go"`) {
+		t.Errorf("hook saw %q, want the phase, the event and the task JSON", data)
+	}
+	if out.String() != "avisado\n" {
+		t.Errorf("feedback = %q, want the output of on-done.c", out.String())
+	}
+}
+
+// TestDefault tests the environment settings
+func TestDefault(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(TimeoutEnv, "30s")
+	r, err := Default()
+	if err != nil || r.Timeout != 30*time.Second || !strings.HasSuffix(r.Dir, filepath.Join(".togo", "hooks")) {
+		t.Errorf("Default() = %+v, %v", r, err)
+	}
+
+	t.Setenv(TimeoutEnv, "logo")
+	if _, err := Default(); err == nil {
+		t.Error("Default() with an invalid timeout expected error, got nil")
+	}
+
+	t.Setenv(DisableEnv, "0")
+	if r, err := Default(); err != nil || r.Dir != "" {
+		t.Errorf("Default() with %s=0 = %+v, %v, want hooks disabled", DisableEnv, r, err)
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"fmt"
+	"levyvix/togo/internal/config"
+	"levyvix/togo/internal/database"
+	"slices"
+)
+
+func ListsFunc(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando nao aceita argumentos. voce passou %d argumentos", len(args))
+	}
+
+	names, err := database.Lists()
+	if err != nil {
+		return fmt.Errorf("erro ao ler as listas: %w", err)
+	}
+	active, err := activeList()
+	if err != nil {
+		return err
+	}
+
+	project, err := config.FindProject(".")
+	if err != nil {
+		return err
+	}
+	if project != nil {
+		fmt.Printf("Escopo atual: projeto %s (%s)\n", project.Name, project.Root)
+		fmt.Println("Use --global para acessar as listas abaixo.")
+		fmt.Println()
+	}
+
+	fmt.Println("Listas de tarefas:")
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\n", marker, name)
+	}
+	return nil
+}
+
+func UseFunc(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d", len(args))
+	}
+
+	name := args[0]
+	if err := database.ValidateListName(name); err != nil {
+		return fmt.Errorf("nome de lista inválido '%s': use apenas letras, números, '-' e '_'", name)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.List = name
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("erro ao salvar a configuração: %w", err)
+	}
+
+	names, err := database.Lists()
+	if err != nil {
+		return fmt.Errorf("erro ao ler as listas: %w", err)
+	}
+	if slices.Contains(names, name) {
+		fmt.Printf("Lista padrão agora é '%s'.\n", name)
+	} else {
+		fmt.Printf("Lista padrão agora é '%s' (nova lista, criada no primeiro uso).\n", name)
+	}
+	return nil
+}
+
+func InitFunc(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando nao aceita argumentos. voce passou %d argumentos", len(args))
+	}
+
+	project, err := config.InitProject(".")
+	if err != nil {
+		return fmt.Errorf("erro ao criar a lista do projeto: %w", err)
+	}
+	if err := database.OpenProject(project); err != nil {
+		return fmt.Errorf("erro ao criar o banco do projeto: %w", err)
+	}
+	fmt.Printf("Lista do projeto '%s' criada em %s\n", project.Name, project.DBPath)
+	fmt.Println("Comandos executados neste diretório (e abaixo) passam a usar esta lista.")
+	return nil
+}
+
+// activeList retorna a lista padrão definida com 'togo use'.
+func activeList() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	if cfg.List == "" {
+		return database.DefaultList, nil
+	}
+	return cfg.List, nil
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"levyvix/togo/internal/config"
+	"levyvix/togo/internal/database"
+)
+
+// captureOutput runs fn and returns what it printed to stdout
+func captureOutput(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	oldStdout := os.Stdout
+	reader, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe: %v", err)
+	}
+	output, _ := io.ReadAll(reader)
+	os.Stdout = oldStdout
+	return string(output), err
+}
+
+// TestUseFunc tests setting the default list
+func TestUseFunc(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantList  string
+		wantError bool
+	}{
+		{name: "New list", args: []string{"trabalho"}, wantList: "trabalho"},
+		{name: "Back to default", args: []string{"default"}, wantList: "default"},
+		{name: "Invalid name", args: []string{"../fora"}, wantError: true},
+		{name: "No arguments", args: []string{}, wantError: true},
+		{name: "Too many arguments", args: []string{"a", "b"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := captureOutput(t, func() error { return UseFunc(tt.args) })
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("UseFunc(%v) expected error, got nil", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UseFunc(%v) unexpected error: %v", tt.args, err)
+			}
+			cfg, err := config.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.List != tt.wantList {
+				t.Errorf("UseFunc(%v) saved list = %q, want %q", tt.args, cfg.List, tt.wantList)
+			}
+		})
+	}
+}
+
+// TestListsFunc tests listing existing lists with the default one marked
+func TestListsFunc(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	listsDir := filepath.Join(home, ".togo", "lists")
+	if err := os.MkdirAll(listsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"trabalho.db", "pessoal.db.enc", "trabalho.db-wal", "notas.txt"} {
+		if err := os.WriteFile(filepath.Join(listsDir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.Save(config.Config{List: "trabalho"}); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := database.Lists()
+	if err != nil {
+		t.Fatalf("database.Lists() unexpected error: %v", err)
+	}
+	if got := strings.Join(names, ","); got != "default,pessoal,trabalho" {
+		t.Errorf("database.Lists() = %q, want %q", got, "default,pessoal,trabalho")
+	}
+
+	output, err := captureOutput(t, func() error { return ListsFunc(nil) })
+	if err != nil {
+		t.Fatalf("ListsFunc() unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "* trabalho") {
+		t.Errorf("ListsFunc() output should mark the default list, got:\n%s", output)
+	}
+	if strings.Contains(output, "notas") {
+		t.Errorf("ListsFunc() output should ignore non-database files, got:\n%s", output)
+	}
+
+	if err := ListsFunc([]string{"extra"}); err == nil {
+		t.Errorf("ListsFunc(extra) expected error, got nil")
+	}
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"fmt"
+	"levyvix/togo/internal/database"
+)
+
+func CheckFuncDB() error {
+	problems, err := database.Check()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar o banco: %w", err)
+	}
+	if len(problems) == 0 {
+		fmt.Println("Banco de dados íntegro.")
+		return nil
+	}
+
+	fmt.Printf("Foram encontrados %d problemas:\n", len(problems))
+	for _, p := range problems {
+		fmt.Println("  -", p)
+	}
+	return fmt.Errorf("o banco de dados está corrompido, tente 'togo db repair'")
+}
+
+func VacuumFuncDB() error {
+	before, after, err := database.Vacuum()
+	if err != nil {
+		return fmt.Errorf("erro ao compactar o banco: %w", err)
+	}
+	fmt.Printf("Banco compactado: %s -> %s\n", formatSize(before), formatSize(after))
+	return nil
+}
+
+func InfoFuncDB() error {
+	info, err := database.Stats()
+	if err != nil {
+		return fmt.Errorf("erro ao ler informações do banco: %w", err)
+	}
+
+	fmt.Printf("Arquivo:           %s\n", info.Path)
+	fmt.Printf("Tamanho:           %s\n", formatSize(info.Size))
+	fmt.Printf("Versão do esquema: %d\n", info.SchemaVersion)
+	fmt.Printf("Modo do journal:   %s\n", info.JournalMode)
+	if info.Encrypted {
+		fmt.Println("Criptografado:     sim")
+	}
+	for _, t := range info.Tables {
+		if t.SoftDelete {
+			fmt.Printf("Tabela %s: %d ativas, %d removidas\n", t.Name, t.Active, t.Deleted)
+		} else {
+			fmt.Printf("Tabela %s: %d linhas\n", t.Name, t.Active)
+		}
+	}
+	return nil
+}
+
+func RepairFuncDB() error {
+	recovered, lost, corrupt, err := database.Repair()
+	if err != nil {
+		return fmt.Errorf("erro ao reparar o banco: %w", err)
+	}
+	fmt.Printf("Banco reconstruído: %d linhas recuperadas, %d perdidas.\n", recovered, lost)
+	fmt.Printf("O arquivo original foi mantido em %s\n", corrupt)
+	return nil
+}
+
+func EncryptFuncDB() error {
+	if database.IsEncrypted() {
+		return fmt.Errorf("o banco já está criptografado")
+	}
+	passphrase, err := database.Passphrase(true)
+	if err != nil {
+		return fmt.Errorf("erro ao obter a senha: %w", err)
+	}
+	if err := database.Encrypt(passphrase); err != nil {
+		return fmt.Errorf("erro ao criptografar o banco: %w", err)
+	}
+	fmt.Printf("Banco criptografado em %s\n", database.EncryptedPath())
+	return nil
+}
+
+func DecryptFuncDB() error {
+	if !database.IsEncrypted() {
+		return fmt.Errorf("o banco não está criptografado")
+	}
+	if err := database.Decrypt(); err != nil {
+		return fmt.Errorf("erro ao descriptografar o banco: %w", err)
+	}
+	fmt.Printf("Banco descriptografado em %s\n", database.Path)
+	return nil
+}
+
+// formatSize formata um tamanho em bytes de forma legível.
+//
+// Exemplo: 1536 -> "1.5 KB"
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
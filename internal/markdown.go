@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/internal/service"
+	"os"
+	"strings"
+)
+
+// mdSyncReport conta o que SyncMarkdownFuncDB mudou de cada lado.
+type mdSyncReport struct {
+	created, done, reopened, updatedInFile int
+	warnings                               []string
+}
+
+// SyncMarkdownFuncDB reconcilia os itens "- [ ]" de um arquivo Markdown com
+// as tarefas do banco, nos dois sentidos:
+//
+//   - itens não marcados sem marcador viram tarefas e ganham
+//     <!-- togo:UUID --> (marcadores antigos, com o ID, passam a usar o UUID);
+//   - itens com marcador cujo estado difere da tarefa são acertados pelo lado
+//     mais recente: se a tarefa mudou depois da última modificação do
+//     arquivo, o checkbox é atualizado; senão a tarefa é concluída ou
+//     reaberta.
+//
+// As tarefas são criadas, concluídas e reabertas pelo serviço, com os hooks,
+// os eventos e os webhooks de qualquer alteração; um item recusado por um
+// hook só vira aviso. O resto do arquivo é mantido como está.
+func SyncMarkdownFuncDB(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d", len(args))
+	}
+
+	path := args[0]
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir '%s': %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler '%s': %w", path, err)
+	}
+	if err := useHooks(); err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	fileTime := info.ModTime()
+
+	out := make([]string, len(lines))
+	copy(out, lines)
+	changed := false
+	// save grava o arquivo com as linhas já acertadas.
+	save := func() error {
+		if !changed {
+			return nil
+		}
+		if err := database.WriteFileAtomic(path, []byte(strings.Join(out, "\n")), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("erro ao gravar '%s': %w", path, err)
+		}
+		changed = false
+		return nil
+	}
+
+	ctx := context.Background()
+	var report mdSyncReport
+	for i, line := range lines {
+		eol := ""
+		if strings.HasSuffix(line, "\r") {
+			eol = "\r"
+		}
+		item, ok := formats.ParseChecklistItem(strings.TrimSuffix(line, "\r"))
+		if !ok {
+			continue
+		}
+
+		if item.Ref == "" {
+			if item.Checked {
+				continue
+			}
+			t, err := (service.Tasks{}).Create(ctx, service.Patch{Description: service.Some(item.Text)})
+			if errors.Is(err, service.ErrInvalid) {
+				report.warnings = append(report.warnings, fmt.Sprintf("linha %d: %v", i+1, err))
+				continue
+			}
+			if err != nil {
+				return errors.Join(err, save())
+			}
+			item.Ref = t.UUID
+			out[i] = item.String() + eol
+			changed = true
+			report.created++
+			// O marcador é gravado logo, para que a tarefa não seja criada de
+			// novo se algo falhar adiante.
+			if err := save(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		t, err := (service.Tasks{}).Get(ctx, item.Ref)
+		if errors.Is(err, service.ErrNotFound) || errors.Is(err, service.ErrInvalid) {
+			report.warnings = append(report.warnings, fmt.Sprintf("linha %d: a tarefa %s não existe mais", i+1, item.Ref))
+			continue
+		}
+		if err != nil {
+			return errors.Join(err, save())
+		}
+		if item.Ref != t.UUID {
+			item.Ref = t.UUID
+			out[i] = item.String() + eol
+			changed = true
+		}
+		if t.Done == item.Checked {
+			continue
+		}
+
+		if t.UpdatedAt.After(fileTime) {
+			item.Checked = t.Done
+			out[i] = item.String() + eol
+			changed = true
+			report.updatedInFile++
+			continue
+		}
+
+		_, err = (service.Tasks{}).Update(ctx, t.UUID, service.Patch{Done: service.Some(item.Checked)})
+		switch {
+		case errors.Is(err, service.ErrInvalid):
+			report.warnings = append(report.warnings, fmt.Sprintf("linha %d: %v", i+1, err))
+		case err != nil:
+			return errors.Join(err, save())
+		case item.Checked:
+			report.done++
+		default:
+			report.reopened++
+		}
+	}
+	if err := save(); err != nil {
+		return err
+	}
+
+	for _, warning := range report.warnings {
+		fmt.Println("Aviso:", warning)
+	}
+	fmt.Printf("%s sincronizado: %d tarefas criadas, %d concluídas, %d reabertas, %d itens atualizados no arquivo\n",
+		path, report.created, report.done, report.reopened, report.updatedInFile)
+	return nil
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/schema"
+)
+
+// TestSyncMarkdownFuncDB tests reconciling checkbox state in both directions
+// and upgrading old numeric markers to UUIDs
+func TestSyncMarkdownFuncDB(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	checkedInFile := schema.Task{Description: "Marcada no arquivo"}
+	doneInTogo := schema.Task{Description: "Concluída no togo"}
+	testDB.Create(&checkedInFile)
+	testDB.Create(&doneInTogo)
+
+	path := filepath.Join(t.TempDir(), "notas.md")
+	notes := "# Notas\r\n\r\n" +
+		"- [ ] Nova tarefa\r\n" +
+		"- [x] Já feita, fora do togo\r\n" +
+		"- [x] Marcada no arquivo <!-- togo:" + itoa(checkedInFile.ID) + " -->\r\n" +
+		"- [ ] Concluída no togo <!-- togo:" + doneInTogo.UUID + " -->\r\n" +
+		"- [ ] Apagada <!-- togo:999 -->\r\n"
+	if err := os.WriteFile(path, []byte(notes), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// O arquivo foi editado depois da primeira tarefa e antes da segunda.
+	past := time.Now().Add(-time.Hour)
+	testDB.Model(&checkedInFile).UpdateColumn("updated_at", past.Add(-time.Hour))
+	if err := os.Chtimes(path, past, past); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	testDB.Model(&doneInTogo).Updates(schema.Task{Done: true, DoneAt: &now})
+
+	output, err := captureOutput(t, func() error { return SyncMarkdownFuncDB([]string{path}) })
+	if err != nil {
+		t.Fatalf("SyncMarkdownFuncDB unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "1 tarefas criadas, 1 concluídas, 0 reabertas, 1 itens atualizados") {
+		t.Errorf("SyncMarkdownFuncDB output = %q", output)
+	}
+	if !strings.Contains(output, "a tarefa 999 não existe mais") {
+		t.Errorf("SyncMarkdownFuncDB should warn about the missing task, output = %q", output)
+	}
+
+	var created schema.Task
+	if err := testDB.Where("description = ?", "Nova tarefa").First(&created).Error; err != nil {
+		t.Fatalf("unchecked item was not created: %v", err)
+	}
+	testDB.First(&checkedInFile, checkedInFile.ID)
+	if !checkedInFile.Done || checkedInFile.DoneAt == nil {
+		t.Errorf("task checked in the file should be done in togo")
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "# Notas\r\n\r\n" +
+		"- [ ] Nova tarefa <!-- togo:" + created.UUID + " -->\r\n" +
+		"- [x] Já feita, fora do togo\r\n" +
+		"- [x] Marcada no arquivo <!-- togo:" + checkedInFile.UUID + " -->\r\n" +
+		"- [x] Concluída no togo <!-- togo:" + doneInTogo.UUID + " -->\r\n" +
+		"- [ ] Apagada <!-- togo:999 -->\r\n"
+	if string(data) != want {
+		t.Errorf("synced file =\n%q\nwant\n%q", data, want)
+	}
+
+	// Uma segunda sincronização não muda nada.
+	output, err = captureOutput(t, func() error { return SyncMarkdownFuncDB([]string{path}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "0 tarefas criadas, 0 concluídas, 0 reabertas, 0 itens atualizados") {
+		t.Errorf("second sync output = %q, want no changes", output)
+	}
+}
+
+// TestSyncMarkdownRunsHooks tests that sync-md changes go through the hooks
+func TestSyncMarkdownRunsHooks(t *testing.T) {
+	clearDB(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(hooks.DisableEnv, "1")
+	dir := home + "/.togo/hooks"
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(dir+"/on-add.draft", []byte("#!/bin/sh\n"+
+		`[ "$1" = pre ] && grep -q Rascunho && { echo "rascunho não vira tarefa" >&2; exit 1; }`+"\n"+
+		"exit 0\n"), 0755)
+	os.WriteFile(dir+"/on-done.log", []byte("#!/bin/sh\n"+`[ "$1" = post ] && echo "concluída pelo sync-md"`+"\nexit 0\n"), 0755)
+
+	done := schema.Task{Description: "Revisar PR"}
+	testDB.Create(&done)
+	testDB.Model(&done).UpdateColumn("updated_at", time.Now().Add(-2*time.Hour))
+	path := filepath.Join(t.TempDir(), "notas.md")
+	os.WriteFile(path, []byte("- [ ] Rascunho da pauta\n- [ ] Nova tarefa\n- [x] Revisar PR <!-- togo:"+done.UUID+" -->\n"), 0644)
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(path, past, past)
+
+	output, err := captureOutput(t, func() error { return SyncMarkdownFuncDB([]string{path}) })
+	if err != nil {
+		t.Fatalf("SyncMarkdownFuncDB unexpected error: %v", err)
+	}
+	for _, want := range []string{"Aviso: linha 1: hook on-add.draft recusou a alteração: rascunho não vira tarefa", "concluída pelo sync-md", "1 tarefas criadas, 1 concluídas"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("SyncMarkdownFuncDB output = %q, want %q", output, want)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "- [ ] Rascunho da pauta\n") {
+		t.Errorf("refused item should stay without a marker:\n%s", data)
+	}
+}
+
+// TestSyncMarkdownKeepsOtherFiles tests that the rewrite keeps the file mode and leaves a stray .tmp alone
+func TestSyncMarkdownKeepsOtherFiles(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notas.md")
+	os.WriteFile(path, []byte("- [ ] Nova tarefa\n"), 0640)
+	os.WriteFile(path+".tmp", []byte("rascunho"), 0644)
+
+	if _, err := captureOutput(t, func() error { return SyncMarkdownFuncDB([]string{path}) }); err != nil {
+		t.Fatalf("SyncMarkdownFuncDB unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path + ".tmp"); string(data) != "rascunho" {
+		t.Errorf("stray .tmp file = %q, want it untouched", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("synced file mode = %v, want 0640", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("files after sync = %d, want no temporary files left", len(entries))
+	}
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,126 @@
+// Package plugin encontra os plugins do togo: executáveis togo-<nome> no
+// PATH, que viram o subcomando togo <nome>, como no git. O plugin roda com o
+// banco da lista atual em TOGO_DB, o nome da lista em TOGO_LIST e o próprio
+// togo em TOGO_BIN; para consultar e alterar as tarefas com segurança, ele
+// chama "$TOGO_BIN rpc" (JSON-RPC na entrada e na saída padrão).
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix é o começo do nome dos executáveis dos plugins.
+const Prefix = "togo-"
+
+// Variáveis de ambiente passadas aos plugins.
+const (
+	DBEnv   = "TOGO_DB"
+	ListEnv = "TOGO_LIST"
+	BinEnv  = "TOGO_BIN"
+)
+
+// nameRe valida o nome do subcomando de um plugin.
+var nameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Plugin é um executável togo-<nome> encontrado no PATH.
+type Plugin struct {
+	Name string
+	Path string
+	// Shadowed lista os outros executáveis com o mesmo nome, mais adiante
+	// no PATH, que não são usados.
+	Shadowed []string
+}
+
+// Find procura os plugins nos diretórios de path (no formato do PATH), em
+// ordem de nome. Como no PATH, vale o primeiro executável de cada nome.
+func Find(path string) []Plugin {
+	byName := map[string]*Plugin{}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			full := filepath.Join(dir, entry.Name())
+			if !executable(full) {
+				continue
+			}
+			if p, ok := byName[name]; ok {
+				if p.Path != full {
+					p.Shadowed = append(p.Shadowed, full)
+				}
+				continue
+			}
+			byName[name] = &Plugin{Name: name, Path: full}
+		}
+	}
+	plugins := make([]Plugin, 0, len(byName))
+	for _, p := range byName {
+		plugins = append(plugins, *p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// pluginName extrai o nome do subcomando do nome do arquivo.
+func pluginName(file string) (string, bool) {
+	if !strings.HasPrefix(file, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, Prefix)
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !strings.EqualFold(ext, ".exe") && !strings.EqualFold(ext, ".bat") && !strings.EqualFold(ext, ".cmd") {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name, nameRe.MatchString(name)
+}
+
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// Env retorna o ambiente do plugin: o do togo mais o contrato com o banco e
+// o binário.
+func Env(dbPath, list, bin string) []string {
+	return append(os.Environ(), DBEnv+"="+dbPath, ListEnv+"="+list, BinEnv+"="+bin)
+}
+
+// Run executa o plugin com args, ligado ao terminal do togo, e retorna o
+// código de saída dele.
+func Run(p Plugin, args, env []string) (int, error) {
+	cmd := exec.Command(p.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+	err := cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeFile writes a file with the given mode into dir
+func writeFile(t *testing.T, dir, name string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 0\n"), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestFind tests discovering plugins in PATH order
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins de teste são scripts sh")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	report := writeFile(t, first, "togo-report", 0755)
+	shadowed := writeFile(t, second, "togo-report", 0755)
+	stats := writeFile(t, second, "togo-stats", 0755)
+	writeFile(t, first, "togo-draft", 0644)
+	writeFile(t, first, "togo-Bad.Name", 0755)
+	writeFile(t, first, "other-tool", 0755)
+	os.Mkdir(filepath.Join(first, "togo-dir"), 0755)
+
+	path := strings.Join([]string{first, "", filepath.Join(first, "nada"), second, first}, string(os.PathListSeparator))
+	got := Find(path)
+	if len(got) != 2 {
+		t.Fatalf("Find() = %+v, want report and stats", got)
+	}
+	if got[0].Name != "report" || got[0].Path != report || len(got[0].Shadowed) != 1 || got[0].Shadowed[0] != shadowed {
+		t.Errorf("Find()[0] = %+v, want report from the first directory, shadowing the second", got[0])
+	}
+	if got[1].Name != "stats" || got[1].Path != stats || len(got[1].Shadowed) != 0 {
+		t.Errorf("Find()[1] = %+v, want stats", got[1])
+	}
+}
+
+// TestEnv tests the environment contract passed to plugins
+func TestEnv(t *testing.T) {
+	env := Env("/tmp/tasks.db", "trabalho", "/usr/bin/togo")
+	want := []string{"TOGO_DB=/tmp/tasks.db", "TOGO_LIST=trabalho", "TOGO_BIN=/usr/bin/togo"}
+	got := env[len(env)-3:]
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Env() = %v, want it to end with %v", got, want)
+			break
+		}
+	}
+}
+
+// TestRun tests that the plugin's exit code is returned
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins de teste são scripts sh")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "togo-fail")
+	os.WriteFile(path, []byte("#!/bin/sh\n[ \"$1\" = ok ] && [ \"$TOGO_LIST\" = x ] && exit 0\nexit 4\n"), 0755)
+	p := Plugin{Name: "fail", Path: path}
+
+	if code, err := Run(p, []string{"ok"}, Env("db", "x", "togo")); err != nil || code != 0 {
+		t.Errorf("Run(ok) = %d, %v, want 0", code, err)
+	}
+	if code, err := Run(p, nil, Env("db", "x", "togo")); err != nil || code != 4 {
+		t.Errorf("Run() = %d, %v, want 4", code, err)
+	}
+	if _, err := Run(Plugin{Name: "nada", Path: filepath.Join(dir, "nada")}, nil, nil); err == nil {
+		t.Error("Run() of a missing plugin expected error, got nil")
+	}
+}
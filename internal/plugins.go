@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/plugin"
+	"levyvix/togo/internal/rpc"
+	"levyvix/togo/internal/service"
+	"os"
+	"slices"
+)
+
+func PluginsFuncDB(args []string, builtins []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	plugins := plugin.Find(os.Getenv("PATH"))
+	if len(plugins) == 0 {
+		fmt.Printf("Nenhum plugin no PATH (executáveis %s<nome>).\n", plugin.Prefix)
+		return nil
+	}
+	for _, p := range plugins {
+		note := ""
+		if slices.Contains(builtins, p.Name) {
+			note = "  (ignorado: já é um comando do togo)"
+		}
+		fmt.Printf("  %-16s %s%s\n", p.Name, p.Path, note)
+		for _, other := range p.Shadowed {
+			fmt.Printf("  %-16s %s  (ignorado: vem depois no PATH)\n", "", other)
+		}
+	}
+	return nil
+}
+
+func RPCFuncDB(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	runner, err := hooks.Default()
+	if err != nil {
+		return err
+	}
+	// A saída padrão é das respostas; as mensagens dos hooks vão para a de erro.
+	runner.Out = os.Stderr
+	service.Hooks = runner
+	var s rpc.Server
+	return s.Serve(context.Background(), os.Stdin, os.Stdout)
+}
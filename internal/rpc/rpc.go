@@ -0,0 +1,277 @@
+// Package rpc expõe o serviço de tarefas por JSON-RPC 2.0 na entrada e na
+// saída padrão, uma mensagem por linha. É o caminho pelo qual os plugins
+// (togo-<nome>) consultam e alteram as tarefas pelo próprio togo, sem abrir o
+// banco por conta própria; as alterações passam pelos hooks (service.Hooks).
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+	"strconv"
+)
+
+// Códigos de erro: os do JSON-RPC 2.0 e os do togo, para as categorias de
+// erro do serviço.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeNotFound       = -32001
+	CodeConflict       = -32002
+)
+
+// maxMessage limita o tamanho de uma linha da entrada.
+const maxMessage = 1 << 20
+
+// Request é uma chamada. Sem ID, é uma notificação e não tem resposta.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response é a resposta a uma chamada: Result ou Error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error é o erro de uma resposta.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Ref indica uma tarefa pelo ID, pelo UUID ou por um prefixo do UUID; aceita
+// número ou texto no JSON.
+type Ref string
+
+func (r *Ref) UnmarshalJSON(data []byte) error {
+	var n uint64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*r = Ref(strconv.FormatUint(n, 10))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("id deve ser um número ou um texto")
+	}
+	*r = Ref(s)
+	return nil
+}
+
+// ListParams são os parâmetros de tasks.list.
+type ListParams struct {
+	Status  string `json:"status"`
+	Query   string `json:"q"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+}
+
+// TaskParams são os parâmetros de tasks.get, tasks.complete e tasks.delete.
+type TaskParams struct {
+	ID Ref `json:"id"`
+}
+
+// UpdateParams são os parâmetros de tasks.update: o ID e os campos a mudar.
+type UpdateParams struct {
+	ID Ref `json:"id"`
+	service.Patch
+}
+
+// TaskPage é o resultado de tasks.list.
+type TaskPage struct {
+	Tasks   []formats.Record `json:"tasks"`
+	Page    int              `json:"page"`
+	PerPage int              `json:"per_page"`
+	Total   int64            `json:"total"`
+}
+
+// Info é o resultado de togo.info.
+type Info struct {
+	List string `json:"list"`
+	DB   string `json:"db"`
+}
+
+// Methods são os métodos disponíveis, na ordem da ajuda.
+var Methods = []string{"togo.info", "tasks.list", "tasks.get", "tasks.create", "tasks.update", "tasks.complete", "tasks.delete"}
+
+// Server atende chamadas sobre o serviço de tarefas, com o acesso da linha
+// de comando (sem usuário).
+type Server struct {
+	tasks service.Tasks
+}
+
+// Serve lê as chamadas de r, uma por linha, e escreve cada resposta em w,
+// também uma por linha, até o fim da entrada ou de ctx.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxMessage)
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		resp := s.Handle(ctx, line)
+		if resp == nil {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler a entrada: %w", err)
+	}
+	return nil
+}
+
+// Handle atende uma mensagem e retorna a resposta, ou nil para uma
+// notificação.
+func (s *Server) Handle(ctx context.Context, msg []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		code := CodeParseError
+		if json.Valid(msg) {
+			code = CodeInvalidRequest
+		}
+		return &Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: code, Message: "mensagem inválida: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = json.RawMessage("null")
+		}
+		return &Response{JSONRPC: "2.0", ID: id, Error: &Error{Code: CodeInvalidRequest, Message: `a chamada precisa de "jsonrpc": "2.0" e de "method"`}}
+	}
+
+	result, err := s.call(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	resp := &Response{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		resp.Error = toError(err)
+	} else {
+		resp.Result = result
+	}
+	return resp
+}
+
+func (s *Server) call(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "togo.info":
+		return Info{List: database.List, DB: database.Path}, nil
+	case "tasks.list":
+		var p ListParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		page, err := s.tasks.List(ctx, service.Filter{Status: p.Status, Query: p.Query, Page: p.Page, PerPage: p.PerPage})
+		if err != nil {
+			return nil, err
+		}
+		return TaskPage{Tasks: records(page.Tasks), Page: page.Page, PerPage: page.PerPage, Total: page.Total}, nil
+	case "tasks.get", "tasks.complete", "tasks.delete":
+		var p TaskParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.ID == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "informe o id da tarefa"}
+		}
+		switch method {
+		case "tasks.get":
+			return record(s.tasks.Get(ctx, string(p.ID)))
+		case "tasks.complete":
+			return record(s.tasks.Complete(ctx, string(p.ID)))
+		default:
+			return record(s.tasks.Delete(ctx, string(p.ID)))
+		}
+	case "tasks.create":
+		var p service.Patch
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return record(s.tasks.Create(ctx, p))
+	case "tasks.update":
+		var p UpdateParams
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.ID == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: "informe o id da tarefa"}
+		}
+		return record(s.tasks.Update(ctx, string(p.ID), p.Patch))
+	}
+	return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("método desconhecido '%s'", method)}
+}
+
+// decodeParams lê os parâmetros nomeados, recusando campos desconhecidos.
+// Parâmetros ausentes valem como um objeto vazio.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: "parâmetros inválidos: " + err.Error()}
+	}
+	return nil
+}
+
+func record(t schema.Task, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return formats.NewRecord(t), nil
+}
+
+func records(tasks []schema.Task) []formats.Record {
+	out := make([]formats.Record, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, formats.NewRecord(t))
+	}
+	return out
+}
+
+// toError traduz a categoria do erro do serviço em código.
+func toError(err error) *Error {
+	var rpcErr *Error
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.Is(err, service.ErrNotFound):
+		return &Error{Code: CodeNotFound, Message: err.Error()}
+	case errors.Is(err, service.ErrInvalid):
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	case errors.Is(err, service.ErrConflict):
+		return &Error{Code: CodeConflict, Message: err.Error()}
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
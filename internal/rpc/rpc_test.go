@@ -0,0 +1,174 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/service"
+)
+
+// openTestDB opens an empty database for the test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.Open(filepath.Join(t.TempDir(), "tasks.db")); err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+		database.DB = nil
+	})
+}
+
+// response is a decoded response with the result kept raw
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// serve runs the server over the given lines and returns the responses
+func serve(t *testing.T, lines ...string) []response {
+	t.Helper()
+	var out bytes.Buffer
+	var s Server
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")), &out); err != nil {
+		t.Fatalf("Serve() unexpected error: %v", err)
+	}
+	var resps []response
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var r response
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("invalid response line %q: %v", scanner.Text(), err)
+		}
+		resps = append(resps, r)
+	}
+	return resps
+}
+
+// TestTasks tests the task methods
+func TestTasks(t *testing.T) {
+	openTestDB(t)
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"tasks.create","params":{"description":"Estudar Go","tags":["go"]}}`,
+		`{"jsonrpc":"2.0","method":"tasks.create","params":{"description":"Notificação"}}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tasks.update","params":{"id":1,"priority":"A"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tasks.complete","params":{"id":"1"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tasks.list","params":{"status":"pending"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tasks.delete","params":{"id":2}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tasks.get","params":{"id":2}}`,
+		`{"jsonrpc":"2.0","id":"info","method":"togo.info"}`,
+	)
+	if len(resps) != 7 {
+		t.Fatalf("got %d responses, want 7 (no response to the notification)", len(resps))
+	}
+	for _, r := range resps[:5] {
+		if r.Error != nil {
+			t.Fatalf("response %s error = %v", r.ID, r.Error)
+		}
+	}
+
+	var task struct {
+		ID          uint     `json:"id"`
+		Description string   `json:"description"`
+		Priority    string   `json:"priority"`
+		Tags        []string `json:"tags"`
+		Done        bool     `json:"done"`
+	}
+	json.Unmarshal(resps[2].Result, &task)
+	if task.ID != 1 || !task.Done || task.Priority != "A" || len(task.Tags) != 1 {
+		t.Errorf("completed task = %+v, want task 1 done with priority A and its tag", task)
+	}
+
+	var page TaskPage
+	json.Unmarshal(resps[3].Result, &page)
+	if page.Total != 1 || len(page.Tasks) != 1 || page.Tasks[0].Description != "Notificação" {
+		t.Errorf("tasks.list = %+v, want only the pending task", page)
+	}
+
+	if e := resps[5].Error; e == nil || e.Code != CodeNotFound {
+		t.Errorf("tasks.get of a deleted task error = %v, want code %d", e, CodeNotFound)
+	}
+	if string(resps[6].ID) != `"info"` || !strings.Contains(string(resps[6].Result), `"db"`) {
+		t.Errorf("togo.info = %s %s", resps[6].ID, resps[6].Result)
+	}
+}
+
+// TestErrors tests the error codes
+func TestErrors(t *testing.T) {
+	openTestDB(t)
+	tests := []struct {
+		line string
+		code int
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":`, CodeParseError},
+		{`[1, 2]`, CodeInvalidRequest},
+		{`{"id":1,"method":"tasks.list"}`, CodeInvalidRequest},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.purge"}`, CodeMethodNotFound},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.get","params":{}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.get","params":{"id":true}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.create","params":{"description":"x","color":"red"}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.create","params":{"description":" "}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.list","params":{"status":"late"}}`, CodeInvalidParams},
+		{`{"jsonrpc":"2.0","id":1,"method":"tasks.complete","params":{"id":42}}`, CodeNotFound},
+	}
+	for _, tt := range tests {
+		resps := serve(t, tt.line)
+		if len(resps) != 1 || resps[0].Error == nil || resps[0].Error.Code != tt.code {
+			t.Errorf("%s = %+v, want error code %d", tt.line, resps, tt.code)
+		}
+	}
+
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"tasks.create","params":{"description":"x"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tasks.complete","params":{"id":1}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tasks.complete","params":{"id":1}}`,
+	)
+	if e := resps[2].Error; e == nil || e.Code != CodeConflict {
+		t.Errorf("completing twice error = %v, want code %d", e, CodeConflict)
+	}
+}
+
+// TestHooks tests that a pre hook can refuse tasks.create and that hook output stays off the responses
+func TestHooks(t *testing.T) {
+	openTestDB(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "on-add.ticket"), []byte("#!/bin/sh\n"+
+		`[ "$1" = post ] && { echo "criada"; exit 0; }`+"\n"+
+		`grep -q '"description":"PROJ-' || { echo "falta o ticket" >&2; exit 1; }`+"\n"), 0755)
+	var out bytes.Buffer
+	service.Hooks = &hooks.Runner{Dir: dir, Timeout: 5 * time.Second, Out: &out}
+	t.Cleanup(func() { service.Hooks = nil })
+
+	resps := serve(t,
+		`{"jsonrpc":"2.0","id":1,"method":"tasks.create","params":{"description":"Corrigir login"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tasks.create","params":{"description":"PROJ-1 Corrigir login"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tasks.list"}`,
+	)
+	if e := resps[0].Error; e == nil || e.Code != CodeInvalidParams || !strings.Contains(e.Message, "falta o ticket") {
+		t.Errorf("create refused by the hook error = %v, want code %d with the hook message", e, CodeInvalidParams)
+	}
+	if resps[1].Error != nil {
+		t.Fatalf("create accepted by the hook error = %v", resps[1].Error)
+	}
+	var page struct{ Total int }
+	json.Unmarshal(resps[2].Result, &page)
+	if page.Total != 1 {
+		t.Errorf("tasks = %d, want only the accepted one", page.Total)
+	}
+	if out.String() != "criada\n" {
+		t.Errorf("hook output = %q, want the post hook feedback", out.String())
+	}
+}
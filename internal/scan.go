@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/scan"
+	"levyvix/togo/internal/webhook"
+	"levyvix/togo/schema"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Extras gravados nas tarefas criadas pelo scan.
+const (
+	scanRootExtra   = "scan_root"
+	scanSourceExtra = "source"
+	// scanClosedExtra marca tarefas concluídas porque o comentário sumiu;
+	// só elas são reabertas se o comentário voltar.
+	scanClosedExtra = "scan_closed"
+)
+
+// ScanFuncDB procura comentários TODO/FIXME/HACK em dir (padrão: diretório
+// atual) e sincroniza as tarefas: comentários novos viram tarefas, os já
+// conhecidos (pelo fingerprint) têm descrição e local atualizados, e
+// tarefas cujo comentário sumiu são concluídas.
+func ScanFuncDB(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("este comando aceita no máximo um argumento, você passou %d", len(args))
+	}
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	comments, err := scan.Walk(root)
+	if err != nil {
+		return fmt.Errorf("erro ao percorrer '%s': %w", dir, err)
+	}
+
+	var created, updated, closed int
+	err = database.Transaction(func(tx *gorm.DB) error {
+		created, updated, closed = 0, 0, 0
+
+		// Tarefas apagadas também contam: um TODO apagado pelo usuário não
+		// volta a cada scan.
+		var known []schema.Task
+		if err := tx.Unscoped().Where("fingerprint <> ''").Find(&known).Error; err != nil {
+			return fmt.Errorf("erro ao ler as tarefas: %w", err)
+		}
+		byFingerprint := make(map[string]*schema.Task, len(known))
+		for i := range known {
+			if known[i].Extras[scanRootExtra] == root {
+				byFingerprint[known[i].Fingerprint] = &known[i]
+			}
+		}
+
+		seen := map[string]bool{}
+		for _, c := range comments {
+			seen[c.Fingerprint] = true
+			t, ok := byFingerprint[c.Fingerprint]
+			if !ok {
+				t = &schema.Task{
+					Description: c.Description(),
+					Tags:        []string{strings.ToLower(c.Kind)},
+					Fingerprint: c.Fingerprint,
+					Extras:      map[string]string{scanRootExtra: root, scanSourceExtra: c.Location()},
+				}
+				if err := tx.Create(t).Error; err != nil {
+					return fmt.Errorf("erro ao salvar '%s': %w", c.Location(), err)
+				}
+				if _, err := webhook.Enqueue(tx, events.Created, t); err != nil {
+					return err
+				}
+				created++
+				continue
+			}
+			if t.DeletedAt.Valid {
+				continue
+			}
+
+			reopen := t.Done && t.Extras[scanClosedExtra] != ""
+			if t.Description == c.Description() && t.Extras[scanSourceExtra] == c.Location() && !reopen {
+				continue
+			}
+			t.Description = c.Description()
+			t.Extras[scanSourceExtra] = c.Location()
+			if reopen {
+				t.Done = false
+				t.DoneAt = nil
+				delete(t.Extras, scanClosedExtra)
+			}
+			if err := tx.Save(t).Error; err != nil {
+				return fmt.Errorf("erro ao atualizar '%s': %w", c.Location(), err)
+			}
+			if _, err := webhook.Enqueue(tx, events.Updated, t); err != nil {
+				return err
+			}
+			updated++
+		}
+
+		now := time.Now()
+		for _, t := range byFingerprint {
+			if seen[t.Fingerprint] || t.Done || t.DeletedAt.Valid {
+				continue
+			}
+			t.Done = true
+			t.DoneAt = &now
+			t.Extras[scanClosedExtra] = "true"
+			if err := tx.Save(t).Error; err != nil {
+				return fmt.Errorf("erro ao concluir a tarefa %d: %w", t.ID, err)
+			}
+			if _, err := webhook.Enqueue(tx, events.Done, t); err != nil {
+				return err
+			}
+			closed++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d comentários encontrados em %s: %d tarefas novas, %d atualizadas, %d concluídas (comentário removido)\n",
+		len(comments), root, created, updated, closed)
+	return nil
+}
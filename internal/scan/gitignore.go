@@ -0,0 +1,191 @@
+package scan
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule é uma linha de um .gitignore.
+type ignoreRule struct {
+	base    string // diretório do .gitignore, relativo à raiz do repositório ("" na raiz)
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreList acumula as regras dos .gitignore da raiz até o diretório atual.
+// Como no git, a última regra que casa decide.
+type ignoreList []ignoreRule
+
+// load lê o arquivo de regras file, cujos padrões são relativos a base
+// (relativo à raiz, com "/"), e retorna a lista com as regras dele
+// acrescentadas. Um arquivo que não existe não muda nada.
+func (l ignoreList) load(file, base string) (ignoreList, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+	defer f.Close()
+
+	out := append(ignoreList(nil), l...)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(base, scanner.Text()); ok {
+			out = append(out, rule)
+		}
+	}
+	return out, scanner.Err()
+}
+
+// repoRules procura o repositório git que contém root e retorna as regras
+// que valem para root vindas de fora dele: as do .git/info/exclude, que
+// perdem para os .gitignore, e as dos .gitignore da raiz do repositório até
+// o diretório acima de root. prefix é o caminho de root relativo à raiz do
+// repositório, com "/". Fora de um repositório, não há regras e prefix é "".
+func repoRules(root string) (rules ignoreList, prefix string, err error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, "", err
+	}
+	repo := abs
+	for {
+		if _, err := os.Stat(filepath.Join(repo, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(repo)
+		if parent == repo {
+			return nil, "", nil
+		}
+		repo = parent
+	}
+	rel, err := filepath.Rel(repo, abs)
+	if err != nil {
+		return nil, "", err
+	}
+	if rel != "." {
+		prefix = filepath.ToSlash(rel)
+	}
+
+	if rules, err = rules.load(filepath.Join(repo, ".git", "info", "exclude"), ""); err != nil {
+		return nil, "", err
+	}
+	if prefix == "" {
+		return rules, "", nil
+	}
+	dir := ""
+	for _, name := range strings.Split(prefix, "/") {
+		if rules, err = rules.load(filepath.Join(repo, filepath.FromSlash(dir), ".gitignore"), dir); err != nil {
+			return nil, "", err
+		}
+		dir = joinRel(dir, name)
+	}
+	return rules, prefix, nil
+}
+
+// ignored diz se o caminho rel (relativo à raiz, com "/") deve ser ignorado.
+func (l ignoreList) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range l {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			sub = rel[len(rule.base)+1:]
+		}
+		if rule.pattern.MatchString(sub) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func parseIgnoreRule(base, line string) (ignoreRule, bool) {
+	rule := ignoreRule{base: base}
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// Sem "/" no meio, o padrão vale em qualquer nível abaixo do .gitignore.
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	rule.pattern = regexp.MustCompile("^" + globToRegexp(line) + "$")
+	return rule, true
+}
+
+// globToRegexp converte um padrão do .gitignore (com *, ?, [...] e **) em
+// expressão regular.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// joinRel junta dir e name em um caminho relativo com "/".
+func joinRel(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return path.Join(dir, name)
+}
@@ -0,0 +1,200 @@
+// Package scan procura comentários TODO, FIXME e HACK no código-fonte para
+// transformá-los em tarefas.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxFileSize é o tamanho a partir do qual um arquivo é ignorado; arquivos
+// assim costumam ser gerados ou dados, não código.
+const maxFileSize = 2 << 20
+
+// Comment é um comentário TODO/FIXME/HACK encontrado em um arquivo.
+type Comment struct {
+	Kind string // TODO, FIXME ou HACK
+	Text string
+	File string // relativo à raiz do scan, com "/"
+	Line int
+	// Fingerprint identifica o comentário entre scans: depende do arquivo,
+	// do tipo e do texto, mas não da linha, para sobreviver a edições em
+	// volta dele.
+	Fingerprint string
+}
+
+// Location retorna "arquivo:linha".
+func (c Comment) Location() string {
+	return fmt.Sprintf("%s:%d", c.File, c.Line)
+}
+
+// Description é o texto usado como descrição da tarefa.
+func (c Comment) Description() string {
+	if c.Text == "" {
+		return fmt.Sprintf("%s em %s", c.Kind, c.File)
+	}
+	return c.Kind + ": " + c.Text
+}
+
+// commentPattern reconhece a marca logo depois de um início de comentário
+// comum (//, #, /*, --, ;, <!--), com autor opcional: "// TODO(ana): texto".
+// A marca precisa vir seguida de ":", espaço ou fim de linha, para não pegar
+// textos como "TODO/FIXME".
+var commentPattern = regexp.MustCompile(`(?:^|\s)(?://+|#+|/\*+|--|;+|<!--)` + commentMark)
+
+// markdownPattern é o commentPattern dos arquivos Markdown, onde # começa
+// um título e os exemplos de código não são comentários do arquivo: só
+// <!-- --> conta.
+var markdownPattern = regexp.MustCompile(`(?:^|\s)<!--` + commentMark)
+
+// commentMark é a parte dos padrões depois do início do comentário.
+const commentMark = `\s*(TODO|FIXME|HACK)(?:\([^)]*\))?(?::|\s|$)\s*(.*)$`
+
+var commentEnd = regexp.MustCompile(`\s*(?:\*/|-->)\s*$`)
+
+// markdownExts são as extensões dos arquivos Markdown.
+var markdownExts = map[string]bool{".md": true, ".markdown": true, ".mdown": true, ".mkd": true, ".mdx": true}
+
+// ParseLine retorna o tipo e o texto do comentário na linha, se houver.
+func ParseLine(line string) (kind, text string, ok bool) {
+	return parseLine(commentPattern, line)
+}
+
+func parseLine(pattern *regexp.Regexp, line string) (kind, text string, ok bool) {
+	m := pattern.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	text = commentEnd.ReplaceAllString(m[2], "")
+	return m[1], strings.Join(strings.Fields(text), " "), true
+}
+
+// Walk percorre root respeitando os .gitignore (e pulando .git) e retorna
+// os comentários encontrados, ordenados por arquivo e linha. Dentro de um
+// repositório git, valem também o .git/info/exclude e os .gitignore dos
+// diretórios acima de root. Arquivos binários ou muito grandes são
+// ignorados.
+func Walk(root string) ([]Comment, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s não é um diretório", root)
+	}
+
+	rules, prefix, err := repoRules(root)
+	if err != nil {
+		return nil, err
+	}
+	var comments []Comment
+	if err := walkDir(root, prefix, "", rules, &comments); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].File != comments[j].File {
+			return comments[i].File < comments[j].File
+		}
+		return comments[i].Line < comments[j].Line
+	})
+	return comments, nil
+}
+
+// walkDir percorre dir (relativo a root, com "/"). As regras usam caminhos
+// relativos à raiz do repositório, onde root fica em prefix.
+func walkDir(root, prefix, dir string, rules ignoreList, out *[]Comment) error {
+	rules, err := rules.load(filepath.Join(root, filepath.FromSlash(dir), ".gitignore"), joinRel(prefix, dir))
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		rel := joinRel(dir, e.Name())
+		if e.IsDir() {
+			if e.Name() == ".git" || rules.ignored(joinRel(prefix, rel), true) {
+				continue
+			}
+			if err := walkDir(root, prefix, rel, rules, out); err != nil {
+				return err
+			}
+			continue
+		}
+		if !e.Type().IsRegular() || rules.ignored(joinRel(prefix, rel), false) {
+			continue
+		}
+		found, err := scanFile(filepath.Join(root, filepath.FromSlash(rel)), rel)
+		if err != nil {
+			return err
+		}
+		*out = append(*out, found...)
+	}
+	return nil
+}
+
+func scanFile(path, rel string) ([]Comment, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() > maxFileSize {
+		return nil, err
+	}
+	head := make([]byte, 8000)
+	n, _ := io.ReadFull(f, head)
+	if bytes.IndexByte(head[:n], 0) >= 0 {
+		return nil, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	pattern := commentPattern
+	if markdownExts[strings.ToLower(filepath.Ext(rel))] {
+		pattern = markdownPattern
+	}
+	var comments []Comment
+	seen := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		kind, text, ok := parseLine(pattern, scanner.Text())
+		if !ok {
+			continue
+		}
+		// Comentários iguais no mesmo arquivo são diferenciados pela ordem.
+		key := kind + "\x00" + text
+		occurrence := seen[key]
+		seen[key]++
+		comments = append(comments, Comment{
+			Kind:        kind,
+			Text:        text,
+			File:        rel,
+			Line:        lineNo,
+			Fingerprint: fingerprint(rel, kind, text, occurrence),
+		})
+	}
+	return comments, scanner.Err()
+}
+
+func fingerprint(file, kind, text string, occurrence int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", file, kind, text, occurrence)))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,159 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestParseLine tests recognizing TODO/FIXME/HACK in several comment styles
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line, kind, text string
+		ok               bool
+	}{
+		{line: "\t// TODO: tratar o erro", kind: "TODO", text: "tratar o erro", ok: true},
+		{line: "x := 1 // FIXME(ana) overflow aqui", kind: "FIXME", text: "overflow aqui", ok: true},
+		{line: "# HACK:   contorna   bug do driver", kind: "HACK", text: "contorna bug do driver", ok: true},
+		{line: "/* TODO remover */", kind: "TODO", text: "remover", ok: true},
+		{line: "<!-- TODO: traduzir -->", kind: "TODO", text: "traduzir", ok: true},
+		{line: "-- FIXME", kind: "FIXME", text: "", ok: true},
+		{line: `msg := "TODO: isto é uma string"`},
+		{line: "// TODOS os itens"},
+		{line: "// todo em minúsculas"},
+		{line: "// TODO/FIXME/HACK"},
+		{line: "* TODO headline do org-mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			kind, text, ok := ParseLine(tt.line)
+			if ok != tt.ok || kind != tt.kind || text != tt.text {
+				t.Errorf("ParseLine(%q) = %q, %q, %v; want %q, %q, %v", tt.line, kind, text, ok, tt.kind, tt.text, tt.ok)
+			}
+		})
+	}
+}
+
+// TestWalk tests walking a tree while respecting nested .gitignore files
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":            "build/\n*.log\n!keep.log\n/root-only.go\n",
+		"main.go":               "package main\n\n// TODO: primeiro\nfunc main() {} // FIXME: segundo\n// TODO: primeiro\n",
+		"keep.log":              "# TODO: log mantido\n",
+		"debug.log":             "# TODO: log ignorado\n",
+		"root-only.go":          "// TODO: ignorado na raiz\n",
+		"build/out.go":          "// TODO: gerado\n",
+		"pkg/root-only.go":      "// HACK: não é a raiz\n",
+		"pkg/.gitignore":        "gen_*.go\n",
+		"pkg/gen_api.go":        "// TODO: gerado no pkg\n",
+		".git/hooks/pre-commit": "# TODO: dentro do .git\n",
+		"image.bin":             "\x00\x01// TODO: binário\n",
+	})
+
+	comments, err := Walk(root)
+	if err != nil {
+		t.Fatalf("Walk() unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range comments {
+		got = append(got, c.Location()+" "+c.Description())
+	}
+	want := []string{
+		"keep.log:1 TODO: log mantido",
+		"main.go:3 TODO: primeiro",
+		"main.go:4 FIXME: segundo",
+		"main.go:5 TODO: primeiro",
+		"pkg/root-only.go:1 HACK: não é a raiz",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Walk() found %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Walk()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if comments[1].Fingerprint == comments[3].Fingerprint {
+		t.Errorf("identical comments in the same file should have different fingerprints")
+	}
+
+	// Mover o comentário de linha não muda o fingerprint.
+	writeFiles(t, root, map[string]string{"main.go": "package main\n\n\n\n// TODO: primeiro\n"})
+	again, err := Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[1].Fingerprint != comments[1].Fingerprint || again[1].Line != 5 {
+		t.Errorf("moved comment = %+v, want same fingerprint as %+v at line 5", again[1], comments[1])
+	}
+}
+
+// TestWalkMarkdown tests that Markdown headings, prose and code samples are
+// not taken as comments, while HTML comments are
+func TestWalkMarkdown(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"README.md": "# TODO list\n\nUse # TODO: no texto.\n<!-- TODO: revisar a seção -->\n\n```go\n// TODO: exemplo\n```\n",
+		"run.sh":    "#!/bin/sh\n# TODO: tratar erros\n",
+	})
+
+	comments, err := Walk(root)
+	if err != nil {
+		t.Fatalf("Walk() unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range comments {
+		got = append(got, c.Location()+" "+c.Description())
+	}
+	want := []string{"README.md:4 TODO: revisar a seção", "run.sh:2 TODO: tratar erros"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Walk() found %q, want %q", got, want)
+	}
+}
+
+// TestWalkSubdirectory tests that scanning a directory inside a git
+// repository applies the parent .gitignore files and .git/info/exclude
+func TestWalkSubdirectory(t *testing.T) {
+	repo := t.TempDir()
+	writeFiles(t, repo, map[string]string{
+		".git/info/exclude":      "segredo.go\n",
+		".gitignore":             "*.gen.go\nvendor/\n",
+		"app/.gitignore":         "tmp/\n",
+		"app/cmd/.gitignore":     "!mantido.gen.go\n",
+		"app/cmd/main.go":        "// TODO: encontrado\n",
+		"app/cmd/api.gen.go":     "// TODO: gerado\n",
+		"app/cmd/mantido.gen.go": "// TODO: gerado, mas mantido\n",
+		"app/cmd/segredo.go":     "// TODO: excluído\n",
+		"app/cmd/tmp/x.go":       "// TODO: temporário\n",
+		"app/cmd/vendor/y.go":    "// TODO: dependência\n",
+	})
+
+	comments, err := Walk(filepath.Join(repo, "app", "cmd"))
+	if err != nil {
+		t.Fatalf("Walk() unexpected error: %v", err)
+	}
+	var got []string
+	for _, c := range comments {
+		got = append(got, c.Location()+" "+c.Description())
+	}
+	want := []string{"main.go:1 TODO: encontrado", "mantido.gen.go:1 TODO: gerado, mas mantido"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Walk() found %q, want %q", got, want)
+	}
+}
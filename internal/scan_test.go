@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"levyvix/togo/schema"
+)
+
+// TestScanFuncDB tests creating, updating and closing tasks from source comments
+func TestScanFuncDB(t *testing.T) {
+	clearDB(t)
+	root := t.TempDir()
+	source := filepath.Join(root, "main.go")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(source, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("package main\n// TODO: tratar o erro\n// FIXME: vaza memória\n")
+	output, err := captureOutput(t, func() error { return ScanFuncDB([]string{root}) })
+	if err != nil {
+		t.Fatalf("ScanFuncDB unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "2 tarefas novas") {
+		t.Errorf("first scan output = %q, want 2 new tasks", output)
+	}
+
+	var todo schema.Task
+	testDB.Where("description = ?", "TODO: tratar o erro").First(&todo)
+	if todo.Fingerprint == "" || todo.Extras["source"] != "main.go:2" || len(todo.Tags) != 1 || todo.Tags[0] != "todo" {
+		t.Errorf("scanned task = %+v, want fingerprint, source main.go:2 and tag todo", todo)
+	}
+
+	// O TODO desce uma linha e o FIXME some.
+	write("package main\n\n// TODO: tratar o erro\n")
+	output, err = captureOutput(t, func() error { return ScanFuncDB([]string{root}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output, "0 tarefas novas, 1 atualizadas, 1 concluídas") {
+		t.Errorf("second scan output = %q", output)
+	}
+	testDB.First(&todo, todo.ID)
+	if todo.Extras["source"] != "main.go:3" || todo.Done {
+		t.Errorf("moved TODO = %+v, want source main.go:3 and still pending", todo)
+	}
+	var fixme schema.Task
+	testDB.Where("description = ?", "FIXME: vaza memória").First(&fixme)
+	if !fixme.Done {
+		t.Errorf("task for the removed FIXME should be done")
+	}
+
+	// O FIXME volta: a tarefa fechada pelo scan é reaberta, sem duplicar.
+	write("package main\n\n// TODO: tratar o erro\n// FIXME: vaza memória\n")
+	if _, err := captureOutput(t, func() error { return ScanFuncDB([]string{root}) }); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	testDB.Model(&schema.Task{}).Count(&count)
+	testDB.First(&fixme, fixme.ID)
+	if count != 2 || fixme.Done {
+		t.Errorf("after the FIXME came back: %d tasks, FIXME done=%v; want 2 tasks and FIXME reopened", count, fixme.Done)
+	}
+
+	if _, err := captureOutput(t, func() error { return ScanFuncDB([]string{filepath.Join(root, "nada")}) }); err == nil {
+		t.Errorf("ScanFuncDB on a missing directory expected error, got nil")
+	}
+}
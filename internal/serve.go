@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"levyvix/togo/internal/grpcapi"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/server"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/internal/webhook"
+	"net"
+	"net/http"
+	"os"
+)
+
+// ServeFuncDB atende a API REST em addr (e a gRPC em grpcAddr, se não
+// vazio) até o processo ser interrompido, enviando os eventos aos webhooks
+// cadastrados. As alterações pela API passam pelos hooks do togo.
+func ServeFuncDB(args []string, addr, grpcAddr string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	exists, err := service.Users{}.Any(context.Background())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		fmt.Println("Aviso: nenhum usuário cadastrado, a API não exige autenticação (veja togo user add)")
+	}
+	if service.Hooks, err = hooks.Default(); err != nil {
+		return err
+	}
+	dispatcher := webhook.NewDispatcher()
+	dispatcher.Logf = func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, "togo: "+format+"\n", args...)
+	}
+	go dispatcher.Run(context.Background(), service.Bus)
+
+	errs := make(chan error, 2)
+	if grpcAddr != "" {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("API gRPC em %s (TaskService de api/togo/v1/togo.proto)\n", lis.Addr())
+		go func() { errs <- grpcapi.New().Serve(lis) }()
+	}
+	fmt.Printf("togo em http://%s (interface web em /, documentação da API em /openapi.json; Ctrl+C para parar)\n", addr)
+	go func() { errs <- http.ListenAndServe(addr, server.New()) }()
+	return <-errs
+}
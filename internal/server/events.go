@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+	"net/http"
+	"time"
+)
+
+// keepAlive é o intervalo das mensagens vazias que mantêm abertas as
+// conexões de eventos (e revelam clientes que sumiram).
+var keepAlive = 25 * time.Second
+
+// errBehind encerra a transmissão de um inscrito que ficou para trás; ele
+// deve reconectar com o ID do último evento recebido.
+var errBehind = errors.New("o cliente ficou para trás na fila de eventos")
+
+// event é a representação de um evento na API.
+type event struct {
+	ID   string      `json:"id"`
+	Type events.Type `json:"type"`
+	Time time.Time   `json:"time"`
+	Task *task       `json:"task,omitempty"`
+}
+
+// sse transmite os eventos por Server-Sent Events. O navegador reconecta
+// sozinho enviando Last-Event-ID; na primeira conexão, o parâmetro
+// last_event_id tem o mesmo efeito.
+func (s *Server) sse(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	// A inscrição vem antes da resposta: o que for publicado depois que o
+	// cliente vê o 200 chega a ele.
+	sub := service.Bus.Subscribe(lastID)
+	defer sub.Close()
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	write := func(format string, args ...any) error {
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	if write("retry: 3000\n\n") != nil {
+		return
+	}
+
+	s.stream(r, sub, func(e event, data []byte) error {
+		return write("id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	}, func() error {
+		return write(": ping\n\n")
+	})
+}
+
+// websocket transmite os eventos por WebSocket, um por mensagem de texto. O
+// parâmetro last_event_id retoma depois do evento indicado.
+func (s *Server) websocket(w http.ResponseWriter, r *http.Request) {
+	sub := service.Bus.Subscribe(r.URL.Query().Get("last_event_id"))
+	defer sub.Close()
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Com a conexão assumida, o fim dela só aparece na leitura.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		conn.readLoop()
+		cancel()
+	}()
+
+	s.stream(r.WithContext(ctx), sub, func(_ event, data []byte) error {
+		return conn.WriteText(data)
+	}, conn.Ping)
+}
+
+// stream envia os eventos de sub que o usuário da requisição pode ver:
+// primeiro os perdidos, depois os novos, até a requisição acabar ou o envio
+// falhar.
+func (s *Server) stream(r *http.Request, sub *events.Subscription, send func(e event, data []byte) error, ping func() error) error {
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	deliver := func(e events.Event) error {
+		out, ok, err := s.event(r, e)
+		if err != nil || !ok {
+			return err
+		}
+		data, err := json.Marshal(out)
+		if err != nil {
+			return err
+		}
+		return send(out, data)
+	}
+	for _, e := range sub.Missed {
+		if err := deliver(e); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case e, ok := <-sub.C:
+			if !ok {
+				return errBehind
+			}
+			if err := deliver(e); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// event converte e para a API, se o usuário da requisição puder ver a
+// tarefa.
+func (s *Server) event(r *http.Request, e events.Event) (event, bool, error) {
+	out := event{ID: e.ID, Type: e.Type, Time: e.Time}
+	if e.Task == nil {
+		return out, true, nil
+	}
+	visible, err := s.tasks.Visible(r.Context(), *e.Task)
+	if err != nil || !visible {
+		return out, false, err
+	}
+	tasks, err := s.resources(r, []schema.Task{*e.Task})
+	if err != nil {
+		return out, false, err
+	}
+	out.Task = &tasks[0]
+	return out, true, nil
+}
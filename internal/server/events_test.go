@@ -0,0 +1,270 @@
+package server
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/internal/events"
+)
+
+// subscribe opens an SSE stream and returns its events as they arrive
+func subscribe(t *testing.T, srv *httptest.Server, query, lastID string) <-chan event {
+	t.Helper()
+	req, err := http.NewRequest("GET", srv.URL+"/events"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		resp.Body.Close()
+		t.Fatalf("GET /events = %d %q, want 200 text/event-stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	ch := make(chan event, 16)
+	go func() {
+		defer close(ch)
+		var id, typ string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				typ = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				var e event
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil || e.ID != id || string(e.Type) != typ {
+					t.Errorf("invalid SSE event id=%q event=%q data=%q: %v", id, typ, line, err)
+				}
+				ch <- e
+			}
+		}
+	}()
+	return ch
+}
+
+// next waits for the next event on ch
+func next(t *testing.T, ch <-chan event) event {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("event stream closed")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return event{}
+	}
+}
+
+// TestSSE tests streaming events over SSE and resuming from Last-Event-ID
+func TestSSE(t *testing.T) {
+	srv := newTestServer(t)
+	stream := subscribe(t, srv, "", "")
+
+	call(t, srv, "POST", "/tasks", `{"description":"Estudar Go"}`, nil)
+	created := next(t, stream)
+	if created.Type != events.Created || created.Task == nil || created.Task.Description != "Estudar Go" {
+		t.Fatalf("first event = %+v, want task.created for 'Estudar Go'", created)
+	}
+	call(t, srv, "PATCH", "/tasks/1", `{"priority":"A"}`, nil)
+	call(t, srv, "POST", "/tasks/1/done", "", nil)
+	call(t, srv, "DELETE", "/tasks/1", "", nil)
+	call(t, srv, "DELETE", "/tasks", "", nil)
+
+	want := []events.Type{events.Updated, events.Done, events.Deleted, events.Cleared}
+	for _, typ := range want {
+		if e := next(t, stream); e.Type != typ {
+			t.Errorf("event = %s, want %s", e.Type, typ)
+		} else if typ == events.Deleted && (e.Task == nil || e.Task.DeletedAt == nil) {
+			t.Errorf("task.deleted event = %+v, want the task with deleted_at", e.Task)
+		}
+	}
+
+	resumed := subscribe(t, srv, "", created.ID)
+	for _, typ := range want {
+		if e := next(t, resumed); e.Type != typ {
+			t.Errorf("resumed event = %s, want %s", e.Type, typ)
+		}
+	}
+	if e := next(t, subscribe(t, srv, "?last_event_id=velho-1", "")); e.Type != events.Reset {
+		t.Errorf("event after unknown ID = %s, want reset", e.Type)
+	}
+}
+
+// TestEventVisibility tests that subscribers only get events for tasks they can see
+func TestEventVisibility(t *testing.T) {
+	srv := newTestServer(t)
+	ana := newUser(t, "ana", false)
+	bruno := newUser(t, "bruno", false)
+
+	var apiErr map[string]string
+	if status := call(t, srv, "GET", "/events?access_token=togo_invalido", "", &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("GET /events with invalid access_token status = %d, want 401", status)
+	}
+	if status := call(t, srv, "GET", "/tasks?access_token="+ana, "", &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("GET /tasks with access_token status = %d, want 401 (only events take it)", status)
+	}
+
+	stream := subscribe(t, srv, "?access_token="+bruno, "")
+	callAs(t, srv, ana, "POST", "/tasks", `{"description":"Privada"}`, nil)
+	callAs(t, srv, ana, "POST", "/tasks", `{"description":"Compartilhada"}`, nil)
+	callAs(t, srv, ana, "POST", "/tasks/2/shares", `{"user":"bruno"}`, nil)
+
+	// Visibility is checked on delivery, so the creation of the shared task
+	// may or may not arrive; the private one never does.
+	e := next(t, stream)
+	for e.Type != events.Updated {
+		if e.Task == nil || e.Task.Description != "Compartilhada" {
+			t.Fatalf("bruno got %+v, want only events for the shared task", e)
+		}
+		e = next(t, stream)
+	}
+	if e.Task == nil || e.Task.Description != "Compartilhada" {
+		t.Fatalf("bruno's event = %+v, want task.updated for the shared task", e)
+	}
+	if e.Task.Owner != "ana" || len(e.Task.SharedWith) != 1 || e.Task.SharedWith[0] != "bruno" {
+		t.Errorf("shared task owner = %q, shared_with = %v", e.Task.Owner, e.Task.SharedWith)
+	}
+}
+
+// dialWebSocket connects to path with a WebSocket handshake
+func dialWebSocket(t *testing.T, srv *httptest.Server, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, reader, resp := handshake(t, srv, path, "")
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") == "" {
+		t.Fatalf("handshake status = %d, want 101 with Sec-WebSocket-Accept", resp.StatusCode)
+	}
+	return conn, reader
+}
+
+// handshake sends a WebSocket handshake for path, with an Origin header
+// unless origin is empty, and returns the response
+func handshake(t *testing.T, srv *httptest.Server, path, origin string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	key := make([]byte, 16)
+	rand.Read(key)
+	extra := ""
+	if origin != "" {
+		extra = "Origin: " + origin + "\r\n"
+	}
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: togo\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\nSec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n%s\r\n",
+		path, base64.StdEncoding.EncodeToString(key), extra)
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, reader, resp
+}
+
+// readFrame reads an unmasked server frame
+func readFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatalf("reading frame: %v", err)
+	}
+	n := int(head[1] & 0x7F)
+	if n == 126 {
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("reading frame payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+// writeFrame sends a masked client frame
+func writeFrame(conn net.Conn, op byte, payload []byte) {
+	mask := []byte{1, 2, 3, 4}
+	frame := append([]byte{0x80 | op, 0x80 | byte(len(payload))}, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	conn.Write(frame)
+}
+
+// TestWebSocket tests streaming events over WebSocket
+func TestWebSocket(t *testing.T) {
+	srv := newTestServer(t)
+	if status := call(t, srv, "GET", "/events/ws", "", nil); status != http.StatusBadRequest {
+		t.Errorf("GET /events/ws without upgrade status = %d, want 400", status)
+	}
+
+	conn, reader := dialWebSocket(t, srv, "/events/ws")
+	call(t, srv, "POST", "/tasks", `{"description":"Estudar Go"}`, nil)
+
+	op, data := readFrame(t, reader)
+	var e event
+	if err := json.Unmarshal(data, &e); op != opText || err != nil {
+		t.Fatalf("frame = %#x %q, want a text frame with an event", op, data)
+	}
+	if e.Type != events.Created || e.Task == nil || e.Task.Description != "Estudar Go" {
+		t.Errorf("event = %+v, want task.created for 'Estudar Go'", e)
+	}
+
+	writeFrame(conn, opPing, []byte("oi"))
+	if op, data := readFrame(t, reader); op != opPong || string(data) != "oi" {
+		t.Errorf("reply to ping = %#x %q, want pong 'oi'", op, data)
+	}
+	writeFrame(conn, opClose, nil)
+	if op, _ := readFrame(t, reader); op != opClose {
+		t.Errorf("reply to close = %#x, want close", op)
+	}
+
+	_, reader = dialWebSocket(t, srv, "/events/ws?last_event_id="+e.ID)
+	call(t, srv, "POST", "/tasks/1/done", "", nil)
+	if _, data := readFrame(t, reader); !strings.Contains(string(data), `"task.done"`) {
+		t.Errorf("resumed frame = %q, want task.done", data)
+	}
+}
+
+// TestWebSocketOrigin tests that browsers on other sites cannot open the
+// event stream
+func TestWebSocketOrigin(t *testing.T) {
+	srv := newTestServer(t)
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"http://togo", http.StatusSwitchingProtocols},
+		{"https://exemplo.com", http.StatusForbidden},
+		{"http://togo.exemplo.com", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if _, _, resp := handshake(t, srv, "/events/ws", tt.origin); resp.StatusCode != tt.want {
+			t.Errorf("handshake from %q status = %d, want %d", tt.origin, resp.StatusCode, tt.want)
+		}
+	}
+}
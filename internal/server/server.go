@@ -0,0 +1,320 @@
+// Package server implementa a API REST do togo serve sobre o serviço de
+// tarefas. As tarefas usam a representação do formato json do togo
+// (formats.Record), e os erros são JSON {"error": "..."}.
+//
+// Com usuários cadastrados, toda requisição à API precisa de um token no
+// cabeçalho Authorization: Bearer. A interface web (em /) e o /openapi.json
+// são públicos; a interface pede o token quando a API o exige.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxBody limita o tamanho do corpo das requisições.
+const maxBody = 1 << 20
+
+//go:embed openapi.json
+var openAPI []byte
+
+// webFS é a interface web, sem dependências externas para funcionar offline.
+//
+//go:embed web
+var webFS embed.FS
+
+// Server é o handler HTTP da API.
+type Server struct {
+	tasks service.Tasks
+	users service.Users
+	mux   *http.ServeMux
+}
+
+// New cria o servidor sobre o banco global.
+func New() *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /tasks", s.list)
+	s.mux.HandleFunc("POST /tasks", s.create)
+	s.mux.HandleFunc("DELETE /tasks", s.clear)
+	s.mux.HandleFunc("GET /tasks/{id}", s.get)
+	s.mux.HandleFunc("PATCH /tasks/{id}", s.update)
+	s.mux.HandleFunc("DELETE /tasks/{id}", s.delete)
+	s.mux.HandleFunc("POST /tasks/{id}/done", s.complete)
+	s.mux.HandleFunc("POST /tasks/{id}/shares", s.share)
+	s.mux.HandleFunc("DELETE /tasks/{id}/shares/{user}", s.unshare)
+	s.mux.HandleFunc("GET /events", s.sse)
+	s.mux.HandleFunc("GET /events/ws", s.websocket)
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+	web, _ := fs.Sub(webFS, "web")
+	s.mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFileFS(w, r, web, "index.html")
+	})
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(web)))
+
+	// Os padrões sem método só pegam o que os de cima não pegaram, para que
+	// 404 e 405 também respondam em JSON.
+	for _, path := range []string{"/tasks", "/tasks/{id}", "/tasks/{id}/done", "/tasks/{id}/shares", "/tasks/{id}/shares/{user}", "/events", "/events/ws", "/openapi.json", "/{$}"} {
+		s.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("método %s não permitido em %s", r.Method, r.URL.Path))
+		})
+	}
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("caminho %s não existe", r.URL.Path))
+	})
+	return s
+}
+
+// ServeHTTP autentica a requisição e a repassa às rotas com o usuário no
+// contexto. Alterações vindas de páginas de outra origem são recusadas.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && !sameOrigin(r) {
+		writeError(w, http.StatusForbidden, "origem não permitida")
+		return
+	}
+	if !public(r.URL.Path) {
+		user, err := s.authenticate(r)
+		if err != nil {
+			if errors.Is(err, service.ErrUnauthorized) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="togo"`)
+			}
+			writeServiceError(w, err)
+			return
+		}
+		if user != nil {
+			r = r.WithContext(service.WithUser(r.Context(), user))
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// public informa se o caminho dispensa autenticação: a interface web e o
+// documento OpenAPI.
+func public(path string) bool {
+	return path == "/" || path == "/openapi.json" || strings.HasPrefix(path, "/static/")
+}
+
+// authenticate lê o token Bearer. Sem token, a requisição só é aceita se
+// não houver usuários cadastrados (servidor pessoal, sem autenticação).
+//
+// Como EventSource e WebSocket não enviam cabeçalhos nos navegadores, as
+// rotas de eventos aceitam também o token no parâmetro access_token.
+func (s *Server) authenticate(r *http.Request) (*schema.User, error) {
+	header := r.Header.Get("Authorization")
+	if q := r.URL.Query(); header == "" && q.Has("access_token") && strings.HasPrefix(r.URL.Path, "/events") {
+		header = "Bearer " + q.Get("access_token")
+	}
+	if header == "" {
+		exists, err := s.users.Any(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("envie o token no cabeçalho Authorization: Bearer <token>: %w", service.ErrUnauthorized)
+		}
+		return nil, nil
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return nil, fmt.Errorf("use o cabeçalho Authorization: Bearer <token>: %w", service.ErrUnauthorized)
+	}
+	return s.users.Authenticate(r.Context(), strings.TrimSpace(token))
+}
+
+// task é a representação de uma tarefa na API: o registro do formato json
+// com o dono e os usuários com quem ela foi compartilhada.
+type task struct {
+	formats.Record
+	Owner      string   `json:"owner,omitempty"`
+	SharedWith []string `json:"shared_with,omitempty"`
+}
+
+// taskPage é a resposta de GET /tasks.
+type taskPage struct {
+	Tasks   []task `json:"tasks"`
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Total   int64  `json:"total"`
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := service.Filter{Status: q.Get("status"), Query: q.Get("q")}
+	var err error
+	if filter.Page, err = intParam(q.Get("page")); err != nil {
+		writeError(w, http.StatusBadRequest, "page inválido: "+q.Get("page"))
+		return
+	}
+	if filter.PerPage, err = intParam(q.Get("per_page")); err != nil {
+		writeError(w, http.StatusBadRequest, "per_page inválido: "+q.Get("per_page"))
+		return
+	}
+
+	page, err := s.tasks.List(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	tasks, err := s.resources(r, page.Tasks)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, taskPage{Tasks: tasks, Page: page.Page, PerPage: page.PerPage, Total: page.Total})
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var p service.Patch
+	if !decode(w, r, &p) {
+		return
+	}
+	t, err := s.tasks.Create(r.Context(), p)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", t.ID))
+	s.writeTask(w, r, http.StatusCreated, t, nil)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	t, err := s.tasks.Get(r.Context(), r.PathValue("id"))
+	s.writeTask(w, r, http.StatusOK, t, err)
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	var p service.Patch
+	if !decode(w, r, &p) {
+		return
+	}
+	t, err := s.tasks.Update(r.Context(), r.PathValue("id"), p)
+	s.writeTask(w, r, http.StatusOK, t, err)
+}
+
+func (s *Server) complete(w http.ResponseWriter, r *http.Request) {
+	t, err := s.tasks.Complete(r.Context(), r.PathValue("id"))
+	s.writeTask(w, r, http.StatusOK, t, err)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.tasks.Delete(r.Context(), r.PathValue("id")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) clear(w http.ResponseWriter, r *http.Request) {
+	n, err := s.tasks.Clear(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"deleted": n})
+}
+
+func (s *Server) share(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		User string `json:"user"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	t, err := s.tasks.Share(r.Context(), r.PathValue("id"), body.User)
+	s.writeTask(w, r, http.StatusOK, t, err)
+}
+
+func (s *Server) unshare(w http.ResponseWriter, r *http.Request) {
+	t, err := s.tasks.Unshare(r.Context(), r.PathValue("id"), r.PathValue("user"))
+	s.writeTask(w, r, http.StatusOK, t, err)
+}
+
+// resources converte as tarefas para a representação da API.
+func (s *Server) resources(r *http.Request, tasks []schema.Task) ([]task, error) {
+	access, err := s.tasks.Access(r.Context(), tasks)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]task, 0, len(tasks))
+	for _, t := range tasks {
+		a := access[t.ID]
+		out = append(out, task{Record: formats.NewRecord(t), Owner: a.Owner, SharedWith: a.SharedWith})
+	}
+	return out, nil
+}
+
+// intParam lê um parâmetro inteiro opcional; vazio é zero.
+func intParam(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// decode lê o corpo JSON da requisição, recusando campos desconhecidos. O
+// Content-Type precisa ser application/json, que um formulário de outro
+// site não consegue enviar sem a permissão do CORS.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if typ, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || typ != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "o corpo precisa ser JSON (Content-Type: application/json)")
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "JSON inválido: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *Server) writeTask(w http.ResponseWriter, r *http.Request, status int, t schema.Task, err error) {
+	if err == nil {
+		var out []task
+		if out, err = s.resources(r, []schema.Task{t}); err == nil {
+			writeJSON(w, status, out[0])
+			return
+		}
+	}
+	writeServiceError(w, err)
+}
+
+// writeServiceError traduz a categoria do erro do serviço em status.
+func writeServiceError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, service.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, service.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden):
+		status = http.StatusForbidden
+	}
+	writeError(w, status, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,511 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/service"
+	"levyvix/togo/schema"
+)
+
+// newTestServer opens an empty database and serves the API over it
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	t.Setenv("TOGO_SNAPSHOTS", "0")
+	if err := database.Open(filepath.Join(t.TempDir(), "tasks.db")); err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+		database.DB = nil
+	})
+	srv := httptest.NewServer(New())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// call sends a request and decodes the JSON response into out, returning the status
+func call(t *testing.T, srv *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	return callAs(t, srv, "", method, path, body, out)
+}
+
+// callAs is call with a bearer token; an empty token sends no Authorization header
+func callAs(t *testing.T, srv *httptest.Server, token, method, path, body string, out any) int {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewBufferString(body)
+	}
+	req, err := http.NewRequest(method, srv.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q: %v", method, path, data, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// TestTaskCRUD tests creating, reading, updating, completing and deleting a task
+func TestTaskCRUD(t *testing.T) {
+	srv := newTestServer(t)
+
+	var created formats.Record
+	if status := call(t, srv, "POST", "/tasks", `{"description":"Revisar PR","priority":"B","tags":["go"]}`, &created); status != http.StatusCreated {
+		t.Fatalf("POST /tasks status = %d, want 201", status)
+	}
+	if created.ID == 0 || created.UUID == "" || created.Priority != "B" {
+		t.Errorf("created = %+v, want ID, UUID and priority B", created)
+	}
+
+	var got formats.Record
+	if status := call(t, srv, "GET", "/tasks/"+created.UUID[:8], "", &got); status != http.StatusOK || got.ID != created.ID {
+		t.Errorf("GET by UUID prefix = %d %+v, want task %d", status, got, created.ID)
+	}
+
+	var updated formats.Record
+	call(t, srv, "PATCH", "/tasks/1", `{"description":"Revisar PR #12","priority":null,"due":"2026-11-01T00:00:00Z"}`, &updated)
+	if updated.Description != "Revisar PR #12" || updated.Priority != "" || updated.Due == nil || len(updated.Tags) != 1 {
+		t.Errorf("PATCH result = %+v, want new description, no priority, a due date and the tag kept", updated)
+	}
+
+	var done formats.Record
+	if status := call(t, srv, "POST", "/tasks/1/done", "", &done); status != http.StatusOK || !done.Done || done.DoneAt == nil {
+		t.Errorf("POST done = %d %+v, want done", status, done)
+	}
+	var apiErr map[string]string
+	if status := call(t, srv, "POST", "/tasks/1/done", "", &apiErr); status != http.StatusConflict || apiErr["error"] == "" {
+		t.Errorf("second POST done = %d %v, want 409 with error", status, apiErr)
+	}
+
+	if status := call(t, srv, "DELETE", "/tasks/1", "", nil); status != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want 204", status)
+	}
+	if status := call(t, srv, "GET", "/tasks/1", "", &apiErr); status != http.StatusNotFound {
+		t.Errorf("GET deleted task status = %d, want 404", status)
+	}
+}
+
+// TestListTasks tests status filtering and pagination
+func TestListTasks(t *testing.T) {
+	srv := newTestServer(t)
+	for i := 0; i < 5; i++ {
+		database.DB.Create(&schema.Task{Description: fmt.Sprintf("Tarefa %d", i+1), Done: i%2 == 0})
+	}
+	database.DB.Model(&schema.Task{}).Where("id = ?", 4).Update("description", "Revisar 100% do PR")
+
+	tests := []struct {
+		query     string
+		wantIDs   []uint
+		wantTotal int64
+	}{
+		{query: "", wantIDs: []uint{1, 2, 3, 4, 5}, wantTotal: 5},
+		{query: "?status=done", wantIDs: []uint{1, 3, 5}, wantTotal: 3},
+		{query: "?status=pending", wantIDs: []uint{2, 4}, wantTotal: 2},
+		{query: "?per_page=2&page=2", wantIDs: []uint{3, 4}, wantTotal: 5},
+		{query: "?per_page=2&page=9", wantIDs: nil, wantTotal: 5},
+		{query: "?q=tarefa&status=pending", wantIDs: []uint{2}, wantTotal: 1},
+		{query: "?q=100%25", wantIDs: []uint{4}, wantTotal: 1},
+		{query: "?q=%25", wantIDs: []uint{4}, wantTotal: 1},
+	}
+	for _, tt := range tests {
+		var page taskPage
+		if status := call(t, srv, "GET", "/tasks"+tt.query, "", &page); status != http.StatusOK {
+			t.Errorf("GET /tasks%s status = %d, want 200", tt.query, status)
+			continue
+		}
+		var ids []uint
+		for _, r := range page.Tasks {
+			ids = append(ids, r.ID)
+		}
+		if len(ids) != len(tt.wantIDs) || page.Total != tt.wantTotal {
+			t.Errorf("GET /tasks%s = %v (total %d), want %v (total %d)", tt.query, ids, page.Total, tt.wantIDs, tt.wantTotal)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.wantIDs[i] {
+				t.Errorf("GET /tasks%s = %v, want %v", tt.query, ids, tt.wantIDs)
+				break
+			}
+		}
+	}
+
+	var deleted map[string]int64
+	if status := call(t, srv, "DELETE", "/tasks", "", &deleted); status != http.StatusOK || deleted["deleted"] != 5 {
+		t.Errorf("DELETE /tasks = %d %v, want 5 deleted", status, deleted)
+	}
+}
+
+// TestErrors tests that invalid requests get JSON errors with the right status
+func TestErrors(t *testing.T) {
+	srv := newTestServer(t)
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"POST", "/tasks", `{"priority":"A"}`, http.StatusBadRequest},
+		{"POST", "/tasks", `{"description":"x","owner":"ana"}`, http.StatusBadRequest},
+		{"POST", "/tasks", `{"description":"x","priority":"AA"}`, http.StatusBadRequest},
+		{"POST", "/tasks", `not json`, http.StatusBadRequest},
+		{"GET", "/tasks?status=late", "", http.StatusBadRequest},
+		{"GET", "/tasks?per_page=1000", "", http.StatusBadRequest},
+		{"GET", "/tasks?page=x", "", http.StatusBadRequest},
+		{"GET", "/tasks/42", "", http.StatusNotFound},
+		{"GET", "/tasks/xyz", "", http.StatusBadRequest},
+		{"PUT", "/tasks/1", "{}", http.StatusMethodNotAllowed},
+		{"GET", "/nada", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		var body map[string]string
+		status := call(t, srv, tt.method, tt.path, tt.body, &body)
+		if status != tt.want || body["error"] == "" {
+			t.Errorf("%s %s = %d %v, want %d with error message", tt.method, tt.path, status, body, tt.want)
+		}
+	}
+}
+
+// TestOpenAPI tests that the OpenAPI document is served and valid JSON
+func TestOpenAPI(t *testing.T) {
+	srv := newTestServer(t)
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if status := call(t, srv, "GET", "/openapi.json", "", &doc); status != http.StatusOK {
+		t.Fatalf("GET /openapi.json status = %d, want 200", status)
+	}
+	for path, methods := range map[string][]string{
+		"/tasks":           {"get", "post", "delete"},
+		"/tasks/{id}":      {"get", "patch", "delete"},
+		"/tasks/{id}/done": {"post"},
+	} {
+		for _, m := range methods {
+			if _, ok := doc.Paths[path][m]; !ok {
+				t.Errorf("OpenAPI document lacks %s %s", m, path)
+			}
+		}
+	}
+}
+
+// TestWebUI tests that the web interface is served without authentication
+func TestWebUI(t *testing.T) {
+	srv := newTestServer(t)
+	newUser(t, "ana", false)
+
+	for path, wantType := range map[string]string{
+		"/":                 "text/html",
+		"/static/app.js":    "text/javascript",
+		"/static/style.css": "text/css",
+	} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), wantType) || len(body) == 0 {
+			t.Errorf("GET %s = %d %q, want 200 %s", path, resp.StatusCode, resp.Header.Get("Content-Type"), wantType)
+		}
+	}
+
+	var apiErr map[string]string
+	if status := call(t, srv, "GET", "/static/nada.js", "", nil); status != http.StatusNotFound {
+		t.Errorf("GET /static/nada.js status = %d, want 404", status)
+	}
+	if status := call(t, srv, "POST", "/", "", &apiErr); status != http.StatusMethodNotAllowed {
+		t.Errorf("POST / status = %d, want 405", status)
+	}
+	if status := call(t, srv, "GET", "/tasks", "", &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("GET /tasks without token status = %d, want 401", status)
+	}
+}
+
+// newUser creates a user and returns a token for it
+func newUser(t *testing.T, name string, admin bool) string {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := (service.Users{}).Add(ctx, name, admin); err != nil {
+		t.Fatalf("Add(%q) unexpected error: %v", name, err)
+	}
+	token, _, err := (service.Users{}).CreateToken(ctx, name, "teste")
+	if err != nil {
+		t.Fatalf("CreateToken(%q) unexpected error: %v", name, err)
+	}
+	return token
+}
+
+// TestAuthorization tests tokens, ownership and sharing
+func TestAuthorization(t *testing.T) {
+	srv := newTestServer(t)
+	database.DB.Create(&schema.Task{Description: "Da linha de comando"})
+	ana := newUser(t, "ana", false)
+	bruno := newUser(t, "bruno", false)
+	admin := newUser(t, "root", true)
+
+	var apiErr map[string]string
+	if status := call(t, srv, "GET", "/tasks", "", &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("GET /tasks without token = %d, want 401", status)
+	}
+	if status := callAs(t, srv, "togo_invalido", "GET", "/tasks", "", &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("GET /tasks with invalid token = %d, want 401", status)
+	}
+	if status := call(t, srv, "GET", "/openapi.json", "", nil); status != http.StatusOK {
+		t.Errorf("GET /openapi.json without token = %d, want 200", status)
+	}
+
+	var created task
+	callAs(t, srv, ana, "POST", "/tasks", `{"description":"Da Ana"}`, &created)
+	if created.Owner != "ana" {
+		t.Errorf("created owner = %q, want ana", created.Owner)
+	}
+	path := fmt.Sprintf("/tasks/%d", created.ID)
+
+	tests := []struct {
+		name                string
+		token, method, path string
+		body                string
+		want                int
+	}{
+		// Tarefas de outros usuários nem aparecem: 404, não 403.
+		{"other user cannot edit", bruno, "PATCH", path, `{"priority":"A"}`, http.StatusNotFound},
+		{"other user cannot complete", bruno, "POST", path + "/done", "", http.StatusNotFound},
+		{"other user cannot delete", bruno, "DELETE", path, "", http.StatusNotFound},
+		{"other user cannot share", bruno, "POST", path + "/shares", `{"user":"bruno"}`, http.StatusNotFound},
+		{"ownerless tasks are read-only", ana, "PATCH", "/tasks/1", `{"priority":"A"}`, http.StatusForbidden},
+		{"only admins clear", ana, "DELETE", "/tasks", "", http.StatusForbidden},
+		{"owner shares", ana, "POST", path + "/shares", `{"user":"bruno"}`, http.StatusOK},
+		{"unknown user", ana, "POST", path + "/shares", `{"user":"carla"}`, http.StatusNotFound},
+		{"shared user edits", bruno, "PATCH", path, `{"priority":"A"}`, http.StatusOK},
+		{"admin edits anything", admin, "PATCH", "/tasks/1", `{"priority":"B"}`, http.StatusOK},
+		{"owner unshares", ana, "DELETE", path + "/shares/bruno", "", http.StatusOK},
+		{"unshared user cannot edit", bruno, "POST", path + "/done", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if status := callAs(t, srv, tt.token, tt.method, tt.path, tt.body, nil); status != tt.want {
+			t.Errorf("%s: %s %s = %d, want %d", tt.name, tt.method, tt.path, status, tt.want)
+		}
+	}
+
+	// Bruno não vê mais a tarefa da Ana, só a sem dono.
+	var page taskPage
+	callAs(t, srv, bruno, "GET", "/tasks", "", &page)
+	if page.Total != 1 || page.Tasks[0].ID != 1 {
+		t.Errorf("bruno sees %d tasks, want only the ownerless one", page.Total)
+	}
+	if status := callAs(t, srv, bruno, "GET", path, "", nil); status != http.StatusNotFound {
+		t.Errorf("GET another user's task = %d, want 404", status)
+	}
+	callAs(t, srv, admin, "GET", "/tasks", "", &page)
+	if page.Total != 2 {
+		t.Errorf("admin sees %d tasks, want 2", page.Total)
+	}
+}
+
+// TestTokenLastUsed tests that requests record token use at most once per
+// service.LastUsedInterval
+func TestTokenLastUsed(t *testing.T) {
+	srv := newTestServer(t)
+	ana := newUser(t, "ana", false)
+	lastUsed := func() *time.Time {
+		var token schema.Token
+		database.DB.First(&token)
+		return token.LastUsedAt
+	}
+
+	callAs(t, srv, ana, "GET", "/tasks", "", nil)
+	first := lastUsed()
+	if first == nil {
+		t.Fatal("last_used_at not recorded on the first request")
+	}
+	callAs(t, srv, ana, "GET", "/tasks", "", nil)
+	if again := lastUsed(); !again.Equal(*first) {
+		t.Errorf("last_used_at = %v after a second request, want it kept at %v", again, first)
+	}
+
+	old := first.Add(-2 * service.LastUsedInterval)
+	database.DB.Model(&schema.Token{}).Where("1 = 1").Update("last_used_at", old)
+	callAs(t, srv, ana, "GET", "/tasks", "", nil)
+	if again := lastUsed(); !again.After(old) {
+		t.Errorf("last_used_at = %v, want it updated after the interval", again)
+	}
+}
+
+// TestHooks tests that API changes go through the hooks
+func TestHooks(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "on-add.ticket"), []byte("#!/bin/sh\n"+
+		`[ "$1" = post ] && { echo "criada"; exit 0; }`+"\n"+
+		"task=$(cat)\n"+
+		`echo "$task" | grep -q '"description":"PROJ-' || { echo "falta o ticket" >&2; exit 1; }`+"\n"+
+		`echo "$task" | sed 's/"done":false/"done":false,"tags":["ticket"]/'`+"\n"), 0755)
+	var out bytes.Buffer
+	service.Hooks = &hooks.Runner{Dir: dir, Timeout: 5 * time.Second, Out: &out}
+	t.Cleanup(func() { service.Hooks = nil })
+
+	var apiErr map[string]string
+	if status := call(t, srv, "POST", "/tasks", `{"description":"Corrigir login"}`, &apiErr); status != http.StatusBadRequest || !strings.Contains(apiErr["error"], "falta o ticket") {
+		t.Errorf("POST refused by the hook = %d %v, want 400 with the hook message", status, apiErr)
+	}
+	var page struct{ Total int }
+	if call(t, srv, "GET", "/tasks", "", &page); page.Total != 0 {
+		t.Fatalf("refused task was saved")
+	}
+
+	var created formats.Record
+	if status := call(t, srv, "POST", "/tasks", `{"description":"PROJ-1 Corrigir login"}`, &created); status != http.StatusCreated {
+		t.Fatalf("POST /tasks status = %d, want 201", status)
+	}
+	if len(created.Tags) != 1 || created.Tags[0] != "ticket" {
+		t.Errorf("created tags = %v, want the hook's tag", created.Tags)
+	}
+	if out.String() != "criada\n" {
+		t.Errorf("hook output = %q, want the post hook feedback", out.String())
+	}
+}
+
+// TestHooksDoneWithChanges tests that completing a task while changing
+// other fields runs both the on-modify and the on-done hooks
+func TestHooksDoneWithChanges(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	for _, name := range []string{"on-modify", "on-done"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+
+			`echo "$1 `+name+`" >> `+log+"\n"+
+			`[ "$1" = pre ] && cat`+"\n"), 0755)
+	}
+	service.Hooks = &hooks.Runner{Dir: dir, Timeout: 5 * time.Second, Out: io.Discard}
+	t.Cleanup(func() { service.Hooks = nil })
+
+	call(t, srv, "POST", "/tasks", `{"description":"Estudar Go"}`, nil)
+	if status := call(t, srv, "PATCH", "/tasks/1", `{"description":"Estudar Go hoje","done":true}`, nil); status != http.StatusOK {
+		t.Fatalf("PATCH status = %d, want 200", status)
+	}
+	data, _ := os.ReadFile(log)
+	want := "pre on-modify\npre on-done\npost on-modify\npost on-done\n"
+	if string(data) != want {
+		t.Errorf("hooks run = %q, want %q", data, want)
+	}
+}
+
+// TestHooksConcurrentChange tests that a change made while the pre hooks
+// run is not overwritten
+func TestHooksConcurrentChange(t *testing.T) {
+	srv := newTestServer(t)
+	dir := t.TempDir()
+	started, resume := filepath.Join(dir, "started"), filepath.Join(dir, "resume")
+	os.WriteFile(filepath.Join(dir, "on-modify"), []byte("#!/bin/sh\n"+
+		`[ "$1" = post ] && exit 0`+"\n"+
+		"task=$(cat)\n"+
+		"touch "+started+"\n"+
+		"while [ ! -e "+resume+" ]; do sleep 0.01; done\n"+
+		`echo "$task"`+"\n"), 0755)
+	service.Hooks = &hooks.Runner{Dir: dir, Timeout: 5 * time.Second, Out: io.Discard}
+	t.Cleanup(func() { service.Hooks = nil })
+
+	call(t, srv, "POST", "/tasks", `{"description":"Estudar Go"}`, nil)
+	status := make(chan int)
+	go func() {
+		status <- call(t, srv, "PATCH", "/tasks/1", `{"description":"Pela API"}`, nil)
+	}()
+	for i := 0; i < 500; i++ {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	database.DB.Model(&schema.Task{}).Where("id = ?", 1).Update("description", "Pela linha de comando")
+	os.WriteFile(resume, nil, 0644)
+
+	if got := <-status; got != http.StatusConflict {
+		t.Errorf("PATCH status = %d, want 409", got)
+	}
+	var task formats.Record
+	call(t, srv, "GET", "/tasks/1", "", &task)
+	if task.Description != "Pela linha de comando" {
+		t.Errorf("description = %q, want the concurrent change kept", task.Description)
+	}
+}
+
+// TestCrossSiteRequests tests that changes from forms and foreign pages are rejected
+func TestCrossSiteRequests(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, err := http.Post(srv.URL+"/tasks", "text/plain", strings.NewReader(`{"description":"Via formulário"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("POST text/plain status = %d, want 415", resp.StatusCode)
+	}
+
+	call(t, srv, "POST", "/tasks", `{"description":"Estudar Go"}`, nil)
+	for _, tt := range []struct{ method, path, body string }{
+		{"POST", "/tasks", `{"description":"Via outro site"}`},
+		{"POST", "/tasks/1/done", ""},
+		{"DELETE", "/tasks/1", ""},
+	} {
+		req, _ := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "https://evil.example")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s %s from another origin status = %d, want 403", tt.method, tt.path, resp.StatusCode)
+		}
+	}
+
+	var page struct {
+		Tasks []formats.Record
+	}
+	call(t, srv, "GET", "/tasks", "", &page)
+	if len(page.Tasks) != 1 || page.Tasks[0].Done {
+		t.Errorf("tasks = %+v, want only the pending task created by the API", page.Tasks)
+	}
+
+	req, _ := http.NewRequest("POST", srv.URL+"/tasks/1/done", nil)
+	req.Header.Set("Origin", srv.URL)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST done from the same origin status = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,261 @@
+// Interface web do togo serve: usa só a API REST do mesmo servidor.
+"use strict";
+
+const perPage = 50;
+const state = { page: 1, total: 0, tasks: new Map() };
+const $ = (id) => document.getElementById(id);
+
+// token guarda o token da API no navegador, quando a API exige um.
+const token = {
+  get: () => localStorage.getItem("togo-token") || "",
+  set: (t) => localStorage.setItem("togo-token", t),
+  clear: () => localStorage.removeItem("togo-token"),
+};
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+async function api(method, path, body) {
+  const headers = {};
+  if (token.get()) headers["Authorization"] = "Bearer " + token.get();
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  const resp = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const data = resp.status === 204 ? null : await resp.json();
+  if (!resp.ok) throw new APIError(resp.status, data && data.error ? data.error : resp.statusText);
+  return data;
+}
+
+function showError(err) {
+  if (err instanceof APIError && err.status === 401) {
+    showLogin();
+    return;
+  }
+  $("error").textContent = err.message;
+  $("error").hidden = false;
+}
+
+function clearError() {
+  $("error").hidden = true;
+}
+
+function showLogin() {
+  if (source) source.close();
+  $("app").hidden = true;
+  $("logout").hidden = true;
+  $("login").hidden = false;
+  if (token.get()) {
+    token.clear();
+    $("error").textContent = "Token inválido ou revogado.";
+    $("error").hidden = false;
+  }
+}
+
+// Datas: os campos date do formulário são dias locais; a API usa RFC 3339.
+function toISODate(value) {
+  return value ? new Date(value + "T00:00:00").toISOString() : null;
+}
+
+function fromISODate(value) {
+  if (!value) return "";
+  const d = new Date(value);
+  const pad = (n) => String(n).padStart(2, "0");
+  return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}`;
+}
+
+function formatDate(value) {
+  return new Date(value).toLocaleDateString("pt-BR");
+}
+
+function splitList(value) {
+  const items = value.split(",").map((s) => s.trim()).filter(Boolean);
+  return items.length ? items : null;
+}
+
+function fillPriorities(select) {
+  select.add(new Option("Sem prioridade", ""));
+  for (let c = 65; c <= 90; c++) select.add(new Option(String.fromCharCode(c)));
+}
+
+// listen relê a lista a cada alteração feita por qualquer pessoa, via
+// Server-Sent Events. O navegador reconecta sozinho, retomando do último
+// evento recebido.
+let source;
+let reloadTimer;
+function listen() {
+  if (source) source.close();
+  const params = token.get() ? "?" + new URLSearchParams({ access_token: token.get() }) : "";
+  source = new EventSource("/events" + params);
+  const reload = () => {
+    clearTimeout(reloadTimer);
+    reloadTimer = setTimeout(load, 200);
+  };
+  for (const type of ["task.created", "task.updated", "task.done", "task.deleted", "tasks.cleared", "reset"]) {
+    source.addEventListener(type, reload);
+  }
+  source.onopen = () => $("live").classList.add("on");
+  source.onerror = () => $("live").classList.remove("on");
+}
+
+async function load() {
+  const filter = $("filter");
+  const params = new URLSearchParams({
+    status: filter.status.value,
+    page: state.page,
+    per_page: perPage,
+  });
+  if (filter.q.value.trim()) params.set("q", filter.q.value.trim());
+  try {
+    const data = await api("GET", "/tasks?" + params);
+    $("login").hidden = true;
+    $("app").hidden = false;
+    $("logout").hidden = !token.get();
+    render(data);
+    if (!source || source.readyState === EventSource.CLOSED) listen();
+  } catch (err) {
+    showError(err);
+  }
+}
+
+function render(data) {
+  state.total = data.total;
+  state.tasks = new Map(data.tasks.map((t) => [t.id, t]));
+  const list = $("tasks");
+  list.replaceChildren(...data.tasks.map(renderTask));
+  $("empty").hidden = data.tasks.length > 0;
+
+  const pages = Math.max(1, Math.ceil(data.total / data.per_page));
+  $("pageinfo").textContent = `Página ${data.page} de ${pages} (${data.total} tarefas)`;
+  $("prev").disabled = data.page <= 1;
+  $("next").disabled = data.page >= pages;
+}
+
+function renderTask(t) {
+  const li = $("task").content.firstElementChild.cloneNode(true);
+  li.dataset.id = t.id;
+  li.classList.toggle("is-done", t.done);
+  li.classList.toggle("overdue", !t.done && t.due && new Date(t.due) < new Date());
+  li.querySelector(".done").checked = t.done;
+  li.querySelector(".description").textContent = t.description;
+  li.querySelector(".priority").textContent = t.priority || "";
+
+  const meta = [`#${t.id}`];
+  if (t.due) meta.push("prazo " + formatDate(t.due));
+  for (const p of t.projects || []) meta.push("+" + p);
+  for (const c of t.contexts || []) meta.push("@" + c);
+  for (const tag of t.tags || []) meta.push(`[${tag}]`);
+  if (t.owner) meta.push("de " + t.owner);
+  li.querySelector(".meta").textContent = meta.join("  ");
+  return li;
+}
+
+async function run(fn) {
+  clearError();
+  try {
+    await fn();
+  } catch (err) {
+    showError(err);
+  }
+  await load();
+}
+
+$("tasks").addEventListener("click", (ev) => {
+  const li = ev.target.closest(".task");
+  if (!li) return;
+  const t = state.tasks.get(Number(li.dataset.id));
+  if (ev.target.classList.contains("done")) {
+    run(() => (t.done ? api("PATCH", `/tasks/${t.id}`, { done: false }) : api("POST", `/tasks/${t.id}/done`)));
+  } else if (ev.target.classList.contains("edit")) {
+    openEditor(t);
+  } else if (ev.target.classList.contains("delete")) {
+    if (confirm(`Apagar a tarefa "${t.description}"?`)) run(() => api("DELETE", `/tasks/${t.id}`));
+  }
+});
+
+function openEditor(t) {
+  const form = $("edit").querySelector("form");
+  $("edit-id").textContent = "#" + t.id;
+  form.dataset.id = t.id;
+  form.description.value = t.description;
+  form.priority.value = t.priority || "";
+  form.due.value = fromISODate(t.due);
+  form.scheduled.value = fromISODate(t.scheduled);
+  form.projects.value = (t.projects || []).join(", ");
+  form.contexts.value = (t.contexts || []).join(", ");
+  form.tags.value = (t.tags || []).join(", ");
+  form.recurrence.value = t.recurrence || "";
+  $("edit").showModal();
+}
+
+$("edit").addEventListener("close", () => {
+  if ($("edit").returnValue !== "save") return;
+  const form = $("edit").querySelector("form");
+  run(() =>
+    api("PATCH", `/tasks/${form.dataset.id}`, {
+      description: form.description.value,
+      priority: form.priority.value,
+      due: toISODate(form.due.value),
+      scheduled: toISODate(form.scheduled.value),
+      projects: splitList(form.projects.value),
+      contexts: splitList(form.contexts.value),
+      tags: splitList(form.tags.value),
+      recurrence: form.recurrence.value.trim(),
+    })
+  );
+});
+
+$("create").addEventListener("submit", (ev) => {
+  ev.preventDefault();
+  const form = ev.target;
+  const body = { description: form.description.value };
+  if (form.priority.value) body.priority = form.priority.value;
+  if (form.due.value) body.due = toISODate(form.due.value);
+  run(async () => {
+    await api("POST", "/tasks", body);
+    form.reset();
+  });
+});
+
+let searchTimer;
+$("filter").addEventListener("input", () => {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(() => {
+    state.page = 1;
+    load();
+  }, 250);
+});
+$("filter").addEventListener("submit", (ev) => ev.preventDefault());
+
+$("prev").addEventListener("click", () => {
+  state.page--;
+  load();
+});
+$("next").addEventListener("click", () => {
+  state.page++;
+  load();
+});
+
+$("login").addEventListener("submit", (ev) => {
+  ev.preventDefault();
+  token.set(ev.target.token.value.trim());
+  ev.target.reset();
+  source = null;
+  clearError();
+  load();
+});
+
+$("logout").addEventListener("click", () => {
+  token.clear();
+  showLogin();
+});
+
+fillPriorities($("create").priority);
+fillPriorities($("edit").querySelector("form").priority);
+load();
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/schema"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+type userKey struct{}
+
+// WithUser associa ao contexto o usuário autenticado da requisição. Sem
+// usuário (linha de comando, ou servidor sem usuários cadastrados), o
+// serviço não restringe nada.
+func WithUser(ctx context.Context, u *schema.User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// UserFrom retorna o usuário associado ao contexto, ou nil.
+func UserFrom(ctx context.Context) *schema.User {
+	u, _ := ctx.Value(userKey{}).(*schema.User)
+	return u
+}
+
+// Access descreve quem pode alterar uma tarefa: o dono e os usuários com
+// quem ela foi compartilhada, por nome.
+type Access struct {
+	Owner      string
+	SharedWith []string
+}
+
+// readable restringe a consulta às tarefas que u pode ver: as suas, as
+// compartilhadas com ele e as sem dono (criadas pela linha de comando).
+func readable(tx *gorm.DB, u *schema.User) *gorm.DB {
+	if u == nil || u.Admin {
+		return tx
+	}
+	shared := tx.Session(&gorm.Session{NewDB: true}).Model(&schema.TaskShare{}).Select("task_id").Where("user_id = ?", u.ID)
+	return tx.Where("owner_id IS NULL OR owner_id = ? OR id IN (?)", u.ID, shared)
+}
+
+// checkWrite verifica se u pode alterar t: administradores podem tudo; os
+// demais, só as próprias tarefas e as compartilhadas com eles.
+func checkWrite(tx *gorm.DB, u *schema.User, t schema.Task) error {
+	if u == nil || u.Admin || t.OwnerID != nil && *t.OwnerID == u.ID {
+		return nil
+	}
+	if t.OwnerID != nil {
+		var count int64
+		if err := tx.Model(&schema.TaskShare{}).Where("task_id = ? AND user_id = ?", t.ID, u.ID).Count(&count).Error; err != nil {
+			return fmt.Errorf("erro ao verificar o compartilhamento: %w", err)
+		}
+		if count > 0 {
+			return nil
+		}
+	}
+	return newError(ErrForbidden, "sem permissão para alterar a tarefa %d: ela não é sua nem foi compartilhada com você", t.ID)
+}
+
+// checkOwner verifica se u pode compartilhar t: só o dono ou um
+// administrador.
+func checkOwner(u *schema.User, t schema.Task) error {
+	if u == nil || u.Admin || t.OwnerID != nil && *t.OwnerID == u.ID {
+		return nil
+	}
+	return newError(ErrForbidden, "só o dono pode compartilhar a tarefa %d", t.ID)
+}
+
+// Access retorna o dono e os compartilhamentos das tarefas, por ID.
+func (Tasks) Access(ctx context.Context, tasks []schema.Task) (map[uint]Access, error) {
+	out := map[uint]Access{}
+	if len(tasks) == 0 {
+		return out, nil
+	}
+	ids := make([]uint, 0, len(tasks))
+	for _, t := range tasks {
+		ids = append(ids, t.ID)
+	}
+
+	var shares []schema.TaskShare
+	if err := db(ctx).Where("task_id IN ?", ids).Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler os compartilhamentos: %w", err)
+	}
+	var users []schema.User
+	if err := db(ctx).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler os usuários: %w", err)
+	}
+	names := map[uint]string{}
+	for _, u := range users {
+		names[u.ID] = u.Name
+	}
+
+	for _, t := range tasks {
+		var a Access
+		if t.OwnerID != nil {
+			a.Owner = names[*t.OwnerID]
+		}
+		out[t.ID] = a
+	}
+	for _, s := range shares {
+		a := out[s.TaskID]
+		a.SharedWith = append(a.SharedWith, names[s.UserID])
+		out[s.TaskID] = a
+	}
+	for id, a := range out {
+		sort.Strings(a.SharedWith)
+		out[id] = a
+	}
+	return out, nil
+}
+
+// Share dá ao usuário userName acesso de escrita à tarefa indicada por ref.
+func (Tasks) Share(ctx context.Context, ref, userName string) (schema.Task, error) {
+	return updateShare(ctx, ref, userName, true)
+}
+
+// Unshare remove o acesso de userName à tarefa indicada por ref.
+func (Tasks) Unshare(ctx context.Context, ref, userName string) (schema.Task, error) {
+	return updateShare(ctx, ref, userName, false)
+}
+
+func updateShare(ctx context.Context, ref, userName string, share bool) (schema.Task, error) {
+	u := UserFrom(ctx)
+	var t schema.Task
+	err := transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		if t, err = Resolve(readable(tx, u), ref); err != nil {
+			return err
+		}
+		if err := checkOwner(u, t); err != nil {
+			return err
+		}
+		if t.OwnerID == nil {
+			return newError(ErrInvalid, "a tarefa %d não tem dono; só tarefas criadas pela API podem ser compartilhadas", t.ID)
+		}
+
+		var target schema.User
+		result := tx.Where("name = ?", userName).Limit(1).Find(&target)
+		if result.Error != nil {
+			return fmt.Errorf("erro ao procurar o usuário '%s': %w", userName, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return newError(ErrNotFound, "usuário '%s' não existe", userName)
+		}
+		if target.ID == *t.OwnerID {
+			return newError(ErrInvalid, "'%s' já é o dono da tarefa %d", userName, t.ID)
+		}
+
+		row := schema.TaskShare{TaskID: t.ID, UserID: target.ID}
+		if share {
+			err = tx.Where(row).FirstOrCreate(&row).Error
+		} else {
+			err = tx.Where(row).Delete(&schema.TaskShare{}).Error
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao gravar o compartilhamento: %w", err)
+		}
+		return nil
+	})
+	return publish(events.Updated, t, err)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Categorias dos erros do serviço, para errors.Is. O servidor HTTP as
+// traduz em códigos de status.
+var (
+	ErrNotFound     = errors.New("não encontrada")
+	ErrInvalid      = errors.New("inválida")
+	ErrConflict     = errors.New("conflito")
+	ErrUnauthorized = errors.New("não autenticado")
+	ErrForbidden    = errors.New("sem permissão")
+)
+
+// ErrAlreadyDone é retornado ao concluir uma tarefa já concluída.
+var ErrAlreadyDone = fmt.Errorf("tarefa já concluída: %w", ErrConflict)
+
+// kindError é um erro com mensagem própria e uma categoria.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+func newError(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/schema"
+	"reflect"
+)
+
+// Bus recebe um evento a cada alteração feita pelo serviço, depois que a
+// transação é confirmada. Alterações feitas direto no banco (pelo import,
+// por exemplo) não geram eventos.
+var Bus = events.NewBus(events.DefaultBuffer)
+
+// Hooks roda os hooks de ~/.togo/hooks em volta de cada criação,
+// alteração, conclusão e remoção feita pelo serviço; nil, o padrão, não
+// roda nenhum. A linha de comando, o togo serve e o togo rpc o definem com
+// hooks.Default. As falhas dos hooks post viram avisos em Hooks.Out.
+var Hooks *hooks.Runner
+
+// hookEvents são os eventos dos hooks de cada tipo de evento do serviço.
+var hookEvents = map[events.Type]hooks.Event{
+	events.Created: hooks.Add,
+	events.Updated: hooks.Modify,
+	events.Done:    hooks.Done,
+	events.Deleted: hooks.Delete,
+}
+
+// hookEventsFor retorna os eventos dos hooks de uma alteração do tipo typ
+// que levou a tarefa de before a after: concluir mudando também outros
+// campos é um on-modify seguido de um on-done.
+func hookEventsFor(typ events.Type, before, after schema.Task) []hooks.Event {
+	if typ == events.Done {
+		edited := before
+		hooks.CopyEdits(&edited, after)
+		if !reflect.DeepEqual(edited, before) {
+			return []hooks.Event{hooks.Modify, hooks.Done}
+		}
+	}
+	return []hooks.Event{hookEvents[typ]}
+}
+
+// preHooks passa t pelos hooks pre de cada evento, em ordem, e retorna a
+// tarefa que eles devolvem. Uma recusa é um erro ErrInvalid.
+func preHooks(t schema.Task, evs ...hooks.Event) (schema.Task, error) {
+	if Hooks == nil {
+		return t, nil
+	}
+	for _, e := range evs {
+		var err error
+		t, err = Hooks.Pre(e, t)
+		if errors.Is(err, hooks.ErrRefused) {
+			return t, newError(ErrInvalid, "%s", err)
+		}
+		if err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+// publish publica o evento de t e roda os hooks post dos eventos evs (sem
+// eles, os do evento typ) se err for nil, e repassa os valores.
+func publish(typ events.Type, t schema.Task, err error, evs ...hooks.Event) (schema.Task, error) {
+	if err != nil {
+		return t, err
+	}
+	Bus.Publish(typ, &t)
+	if Hooks == nil {
+		return t, nil
+	}
+	if len(evs) == 0 {
+		evs = []hooks.Event{hookEvents[typ]}
+	}
+	for _, e := range evs {
+		if err := Hooks.Post(e, t); err != nil && Hooks.Out != nil {
+			fmt.Fprintln(Hooks.Out, "Aviso:", err)
+		}
+	}
+	return t, nil
+}
+
+// Visible informa se o usuário do contexto pode ver a tarefa de um evento,
+// mesmo que ela já tenha sido apagada.
+func (Tasks) Visible(ctx context.Context, t schema.Task) (bool, error) {
+	u := UserFrom(ctx)
+	if u == nil || u.Admin || t.OwnerID == nil || *t.OwnerID == u.ID {
+		return true, nil
+	}
+	var count int64
+	if err := db(ctx).Model(&schema.TaskShare{}).Where("task_id = ? AND user_id = ?", t.ID, u.ID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("erro ao verificar o compartilhamento: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,413 @@
+// Package service reúne as operações sobre as tarefas usadas pela linha de
+// comando e pelos modos servidor do togo (API HTTP), com validação e erros
+// categorizados, sem nada de apresentação.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/internal/webhook"
+	"levyvix/togo/schema"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status filtra as tarefas de List.
+const (
+	StatusAll     = "all"
+	StatusPending = "pending"
+	StatusDone    = "done"
+)
+
+// DefaultPerPage e MaxPerPage limitam o tamanho das páginas de List.
+const (
+	DefaultPerPage = 50
+	MaxPerPage     = 500
+)
+
+// Filter seleciona uma página de tarefas.
+type Filter struct {
+	// Status é StatusAll (ou vazio), StatusPending ou StatusDone.
+	Status string
+	// Query, se não vazio, seleciona as tarefas cuja descrição o contém
+	// (sem diferenciar maiúsculas).
+	Query string
+	// Page começa em 1; PerPage zero usa DefaultPerPage.
+	Page, PerPage int
+}
+
+// Page é uma página de tarefas, em ordem de ID.
+type Page struct {
+	Tasks   []schema.Task
+	Page    int
+	PerPage int
+	// Total conta as tarefas do filtro em todas as páginas.
+	Total int64
+}
+
+// Optional é um campo de Patch. Set indica se o campo veio na requisição,
+// para distinguir "não mudar" de "limpar" (null).
+type Optional[T any] struct {
+	Set   bool
+	Value T
+}
+
+// Some retorna um Optional preenchido com v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Set: true, Value: v}
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	return json.Unmarshal(data, &o.Value)
+}
+
+// Patch são os campos de uma tarefa a definir na criação ou na alteração.
+// Campos ausentes não mudam.
+type Patch struct {
+	Description Optional[string]     `json:"description"`
+	Done        Optional[bool]       `json:"done"`
+	Priority    Optional[string]     `json:"priority"`
+	Projects    Optional[[]string]   `json:"projects"`
+	Contexts    Optional[[]string]   `json:"contexts"`
+	Tags        Optional[[]string]   `json:"tags"`
+	Due         Optional[*time.Time] `json:"due"`
+	Scheduled   Optional[*time.Time] `json:"scheduled"`
+	Recurrence  Optional[string]     `json:"recurrence"`
+}
+
+// Tasks é o serviço de tarefas sobre o banco global. Com um usuário no
+// contexto (ver WithUser), as operações respeitam o dono e os
+// compartilhamentos de cada tarefa.
+type Tasks struct{}
+
+// db retorna o banco global com o contexto da requisição.
+func db(ctx context.Context) *gorm.DB {
+	return database.DB.WithContext(ctx)
+}
+
+// transaction roda fn em database.Transaction com o contexto da requisição.
+func transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		return fn(tx.WithContext(ctx))
+	})
+}
+
+// List retorna uma página das tarefas não apagadas visíveis ao usuário.
+func (Tasks) List(ctx context.Context, f Filter) (Page, error) {
+	page := Page{Page: f.Page, PerPage: f.PerPage}
+	if page.Page == 0 {
+		page.Page = 1
+	}
+	if page.PerPage == 0 {
+		page.PerPage = DefaultPerPage
+	}
+	if page.Page < 1 {
+		return page, newError(ErrInvalid, "página inválida: %d", f.Page)
+	}
+	if page.PerPage < 1 || page.PerPage > MaxPerPage {
+		return page, newError(ErrInvalid, "tamanho de página inválido: %d (use de 1 a %d)", f.PerPage, MaxPerPage)
+	}
+
+	query := readable(db(ctx).Model(&schema.Task{}), UserFrom(ctx))
+	switch f.Status {
+	case "", StatusAll:
+	case StatusPending:
+		query = query.Where("done = ?", false)
+	case StatusDone:
+		query = query.Where("done = ?", true)
+	default:
+		return page, newError(ErrInvalid, "status inválido '%s' (use %s, %s ou %s)", f.Status, StatusAll, StatusPending, StatusDone)
+	}
+	if f.Query != "" {
+		query = query.Where(`description LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(f.Query)+"%")
+	}
+
+	if err := query.Count(&page.Total).Error; err != nil {
+		return page, fmt.Errorf("erro ao contar as tarefas: %w", err)
+	}
+	page.Tasks = []schema.Task{}
+	err := query.Order("id asc").Offset((page.Page - 1) * page.PerPage).Limit(page.PerPage).Find(&page.Tasks).Error
+	if err != nil {
+		return page, fmt.Errorf("erro ao ler as tarefas: %w", err)
+	}
+	return page, nil
+}
+
+// likeEscaper protege os curingas do LIKE no texto buscado.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Get retorna a tarefa indicada por ref (ver Resolve). Tarefas que o
+// usuário não pode ver não são encontradas.
+func (Tasks) Get(ctx context.Context, ref string) (schema.Task, error) {
+	return Resolve(readable(db(ctx), UserFrom(ctx)), ref)
+}
+
+// Create cria uma tarefa com os campos de p, do usuário do contexto; a
+// descrição é obrigatória.
+func (Tasks) Create(ctx context.Context, p Patch) (schema.Task, error) {
+	var t schema.Task
+	if !p.Description.Set {
+		return t, newError(ErrInvalid, "a descrição não pode estar vazia")
+	}
+	if err := p.apply(&t); err != nil {
+		return t, err
+	}
+	if u := UserFrom(ctx); u != nil {
+		t.OwnerID = &u.ID
+	}
+	t, err := preHooks(t, hooks.Add)
+	if err != nil {
+		return t, err
+	}
+	err = transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&t).Error; err != nil {
+			return fmt.Errorf("erro ao salvar a tarefa no banco de dados: %w", err)
+		}
+		_, err := webhook.Enqueue(tx, events.Created, &t)
+		return err
+	})
+	if err != nil {
+		return t, err
+	}
+	return publish(events.Created, t, nil)
+}
+
+// Update altera os campos de p na tarefa indicada por ref.
+// Concluir pela alteração (done: true) publica events.Done; se a
+// alteração mudar também outros campos, roda os hooks on-modify e on-done.
+func (Tasks) Update(ctx context.Context, ref string, p Patch) (schema.Task, error) {
+	t, typ, evs, err := modify(ctx, ref, func(t *schema.Task) (events.Type, error) {
+		wasDone := t.Done
+		if err := p.apply(t); err != nil {
+			return "", err
+		}
+		if t.Done && !wasDone {
+			return events.Done, nil
+		}
+		return events.Updated, nil
+	}, save)
+	return publish(typ, t, err, evs...)
+}
+
+// Complete conclui a tarefa indicada por ref.
+func (Tasks) Complete(ctx context.Context, ref string) (schema.Task, error) {
+	t, typ, evs, err := modify(ctx, ref, markDone, save)
+	return publish(typ, t, err, evs...)
+}
+
+// Delete apaga (soft delete) a tarefa indicada por ref.
+func (Tasks) Delete(ctx context.Context, ref string) (schema.Task, error) {
+	t, typ, evs, err := modify(ctx, ref, func(*schema.Task) (events.Type, error) {
+		return events.Deleted, nil
+	}, func(tx *gorm.DB, t *schema.Task) error {
+		if err := tx.Delete(t).Error; err != nil {
+			return fmt.Errorf("erro ao deletar a tarefa: %w", err)
+		}
+		// Relê a tarefa para que o evento traga o deleted_at.
+		return tx.Unscoped().First(t, t.ID).Error
+	})
+	return publish(typ, t, err, evs...)
+}
+
+// modify encontra a tarefa indicada por ref, verifica se o usuário pode
+// alterá-la, aplica edit, grava com write e enfileira o evento para os
+// webhooks, tudo em uma transação, e retorna também os eventos dos hooks da
+// alteração. Com Hooks, edit e os hooks pre rodam antes, fora da transação,
+// e a transação grava o resultado deles; se a tarefa mudou nesse meio
+// tempo (outro updated_at), nada é gravado e o erro é ErrConflict.
+func modify(ctx context.Context, ref string, edit func(t *schema.Task) (events.Type, error), write func(tx *gorm.DB, t *schema.Task) error) (schema.Task, events.Type, []hooks.Event, error) {
+	u := UserFrom(ctx)
+	change := func(t *schema.Task) (events.Type, []hooks.Event, error) {
+		before := *t
+		typ, err := edit(t)
+		if err != nil {
+			return typ, nil, err
+		}
+		return typ, hookEventsFor(typ, before, *t), nil
+	}
+
+	var preview *schema.Task
+	var read time.Time
+	var typ events.Type
+	var evs []hooks.Event
+	if Hooks != nil {
+		t, err := writable(db(ctx), u, ref)
+		if err != nil {
+			return t, "", nil, err
+		}
+		read = t.UpdatedAt
+		if typ, evs, err = change(&t); err != nil {
+			return t, typ, evs, err
+		}
+		if t, err = preHooks(t, evs...); err != nil {
+			return t, typ, evs, err
+		}
+		preview = &t
+	}
+
+	var t schema.Task
+	err := transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		if t, err = writable(tx, u, ref); err != nil {
+			return err
+		}
+		if preview != nil {
+			if !t.UpdatedAt.Equal(read) {
+				return newError(ErrConflict, "a tarefa %d mudou enquanto os hooks rodavam; tente de novo", t.ID)
+			}
+			t = *preview
+		} else if typ, evs, err = change(&t); err != nil {
+			return err
+		}
+		if err := write(tx, &t); err != nil {
+			return err
+		}
+		_, err = webhook.Enqueue(tx, typ, &t)
+		return err
+	})
+	return t, typ, evs, err
+}
+
+// writable encontra a tarefa indicada por ref e verifica se o usuário pode
+// alterá-la.
+func writable(tx *gorm.DB, u *schema.User, ref string) (schema.Task, error) {
+	t, err := Resolve(readable(tx, u), ref)
+	if err != nil {
+		return t, err
+	}
+	return t, checkWrite(tx, u, t)
+}
+
+// save grava todos os campos de t.
+func save(tx *gorm.DB, t *schema.Task) error {
+	if err := tx.Save(t).Error; err != nil {
+		return fmt.Errorf("erro ao salvar a tarefa no banco de dados: %w", err)
+	}
+	return nil
+}
+
+// Clear apaga todas as tarefas, como o togo clear (com snapshot antes, se
+// TOGO_SNAPSHOTS estiver ativo), e retorna quantas foram apagadas. Na API,
+// só administradores podem fazê-lo.
+func (Tasks) Clear(ctx context.Context) (int64, error) {
+	if u := UserFrom(ctx); u != nil && !u.Admin {
+		return 0, newError(ErrForbidden, "só administradores podem apagar todas as tarefas")
+	}
+	if _, err := database.Snapshot("clear"); err != nil {
+		return 0, fmt.Errorf("erro ao criar snapshot antes de limpar: %w", err)
+	}
+	var count int64
+	err := transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&schema.Task{})
+		if result.Error != nil {
+			return result.Error
+		}
+		count = result.RowsAffected
+		_, err := webhook.Enqueue(tx, events.Cleared, nil)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("erro ao tentar limpar a tabela: %w", err)
+	}
+	Bus.Publish(events.Cleared, nil)
+	return count, nil
+}
+
+// apply valida p e copia os campos presentes para t.
+func (p Patch) apply(t *schema.Task) error {
+	if p.Description.Set {
+		if strings.TrimSpace(p.Description.Value) == "" {
+			return newError(ErrInvalid, "a descrição não pode estar vazia")
+		}
+		t.Description = p.Description.Value
+	}
+	if p.Priority.Set {
+		if v := p.Priority.Value; v != "" && (len(v) != 1 || v[0] < 'A' || v[0] > 'Z') {
+			return newError(ErrInvalid, "prioridade inválida '%s' (use uma letra de A a Z)", v)
+		}
+		t.Priority = p.Priority.Value
+	}
+	if p.Projects.Set {
+		t.Projects = p.Projects.Value
+	}
+	if p.Contexts.Set {
+		t.Contexts = p.Contexts.Value
+	}
+	if p.Tags.Set {
+		t.Tags = p.Tags.Value
+	}
+	if p.Due.Set {
+		t.Due = p.Due.Value
+	}
+	if p.Scheduled.Set {
+		t.Scheduled = p.Scheduled.Value
+	}
+	if p.Recurrence.Set {
+		t.Recurrence = p.Recurrence.Value
+	}
+	if p.Done.Set && p.Done.Value != t.Done {
+		t.Done = p.Done.Value
+		t.DoneAt = nil
+		if t.Done {
+			now := time.Now()
+			t.DoneAt = &now
+		}
+	}
+	return nil
+}
+
+// markDone conclui a tarefa t, ou recusa se ela já estiver concluída.
+func markDone(t *schema.Task) (events.Type, error) {
+	if t.Done {
+		return "", &kindError{kind: ErrAlreadyDone, msg: fmt.Sprintf("tarefa %d já está concluída", t.ID)}
+	}
+	t.Done = true
+	now := time.Now()
+	t.DoneAt = &now
+	return events.Done, nil
+}
+
+// uuidPrefixPattern reconhece um UUID ou o começo de um.
+var uuidPrefixPattern = regexp.MustCompile(`^[0-9a-fA-F][0-9a-fA-F-]*$`)
+
+// Resolve encontra a tarefa indicada por ref: o ID numérico ou o UUID,
+// inteiro ou só o começo, desde que nenhuma outra tarefa comece igual. Um
+// ref só com dígitos é sempre lido como ID.
+func Resolve(tx *gorm.DB, ref string) (schema.Task, error) {
+	var t schema.Task
+	if id, err := strconv.Atoi(ref); err == nil {
+		result := tx.Limit(1).Find(&t, id)
+		if result.Error != nil {
+			return t, fmt.Errorf("erro ao procurar a tarefa %d: %w", id, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return t, newError(ErrNotFound, "tarefa com ID %d não existe", id)
+		}
+		return t, nil
+	}
+	if !uuidPrefixPattern.MatchString(ref) {
+		return t, newError(ErrInvalid, "'%s' não é um ID nem um prefixo de UUID", ref)
+	}
+
+	var matches []schema.Task
+	if err := tx.Where("uuid LIKE ?", strings.ToLower(ref)+"%").Limit(2).Find(&matches).Error; err != nil {
+		return t, fmt.Errorf("erro ao procurar a tarefa '%s': %w", ref, err)
+	}
+	switch len(matches) {
+	case 0:
+		return t, newError(ErrNotFound, "nenhuma tarefa com UUID começando em '%s'", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return t, newError(ErrInvalid, "o prefixo '%s' corresponde a mais de uma tarefa; use mais caracteres do UUID", ref)
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"levyvix/togo/schema"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenPrefix começa todos os tokens, para que sejam fáceis de reconhecer
+// (e de achar em um vazamento).
+const TokenPrefix = "togo_"
+
+var userNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Users é o serviço de usuários e tokens da API.
+type Users struct{}
+
+// TokenInfo é um token com o nome do seu usuário.
+type TokenInfo struct {
+	schema.Token
+	UserName string
+}
+
+// Add cadastra um usuário.
+func (Users) Add(ctx context.Context, name string, admin bool) (schema.User, error) {
+	u := schema.User{Name: name, Admin: admin}
+	if !userNameRe.MatchString(name) {
+		return u, newError(ErrInvalid, "nome de usuário inválido '%s': use letras minúsculas, dígitos, '.', '-' e '_'", name)
+	}
+	err := transaction(ctx, func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&schema.User{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return newError(ErrConflict, "o usuário '%s' já existe", name)
+		}
+		return tx.Create(&u).Error
+	})
+	return u, err
+}
+
+// List retorna os usuários, em ordem de nome.
+func (Users) List(ctx context.Context) ([]schema.User, error) {
+	var users []schema.User
+	if err := db(ctx).Order("name asc").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler os usuários: %w", err)
+	}
+	return users, nil
+}
+
+// Any informa se há algum usuário cadastrado. Sem usuários, o togo serve
+// não exige autenticação.
+func (Users) Any(ctx context.Context) (bool, error) {
+	var count int64
+	if err := db(ctx).Model(&schema.User{}).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("erro ao ler os usuários: %w", err)
+	}
+	return count > 0, nil
+}
+
+// CreateToken cria um token para o usuário userName e retorna o token em
+// si, que não é guardado e não pode ser recuperado depois.
+func (Users) CreateToken(ctx context.Context, userName, label string) (string, schema.Token, error) {
+	var token schema.Token
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", token, err
+	}
+	plain := TokenPrefix + hex.EncodeToString(secret)
+
+	err := transaction(ctx, func(tx *gorm.DB) error {
+		var u schema.User
+		result := tx.Where("name = ?", userName).Limit(1).Find(&u)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return newError(ErrNotFound, "usuário '%s' não existe (crie com togo user add)", userName)
+		}
+		token = schema.Token{UserID: u.ID, Name: label, Hash: hashToken(plain)}
+		return tx.Create(&token).Error
+	})
+	if err != nil {
+		return "", token, err
+	}
+	return plain, token, nil
+}
+
+// Tokens retorna os tokens de todos os usuários, em ordem de criação.
+func (Users) Tokens(ctx context.Context) ([]TokenInfo, error) {
+	var tokens []TokenInfo
+	err := db(ctx).Model(&schema.Token{}).
+		Select("tokens.*, users.name AS user_name").
+		Joins("JOIN users ON users.id = tokens.user_id").
+		Order("tokens.id asc").Scan(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler os tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken apaga o token de ID id.
+func (Users) RevokeToken(ctx context.Context, id uint) error {
+	return transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Delete(&schema.Token{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("erro ao revogar o token: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return newError(ErrNotFound, "token %d não existe", id)
+		}
+		return nil
+	})
+}
+
+// LastUsedInterval é de quanto em quanto tempo o uso de um token é gravado.
+// Gravar a cada requisição tomaria o lock de escrita do banco (e, com o banco
+// criptografado, regravaria o arquivo) até nas leituras.
+const LastUsedInterval = time.Minute
+
+// Authenticate retorna o usuário dono do token e registra o uso, se o
+// último registrado tiver mais de LastUsedInterval.
+func (Users) Authenticate(ctx context.Context, plain string) (*schema.User, error) {
+	if !strings.HasPrefix(plain, TokenPrefix) {
+		return nil, newError(ErrUnauthorized, "token inválido")
+	}
+	var token schema.Token
+	result := db(ctx).Where("hash = ?", hashToken(plain)).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, newError(ErrUnauthorized, "token inválido")
+	}
+	var u schema.User
+	if err := db(ctx).First(&u, token.UserID).Error; err != nil {
+		return nil, newError(ErrUnauthorized, "token inválido")
+	}
+
+	now := time.Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= LastUsedInterval {
+		err := transaction(ctx, func(tx *gorm.DB) error {
+			return tx.Model(&token).Update("last_used_at", now).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &u, nil
+}
+
+// hashToken é o que fica guardado no banco: o SHA-256 do token. Tokens são
+// aleatórios e longos, então não precisam de um hash lento.
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"levyvix/togo/internal/crdt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/gitsync"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// SyncFuncDB sincroniza as tarefas com o repositório git remoto; remote
+// configura (ou troca) o remoto, vazio usa o já configurado.
+func SyncFuncDB(args []string, remote string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+
+	report, err := gitsync.Sync(remote)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sincronizado (%s): %d tarefas criadas, %d atualizadas e %d removidas localmente\n",
+		gitsync.Dir(), report.Created, report.Updated, report.Removed)
+	if len(report.Conflicts) > 0 {
+		fmt.Printf("Editadas dos dois lados (valeu a edição mais recente): %s\n", strings.Join(report.Conflicts, ", "))
+	}
+	if report.Pushed {
+		fmt.Printf("Commit %s enviado ao remoto\n", shortSHA(report.Commit))
+	} else {
+		fmt.Println("Nada a enviar: o remoto já está atualizado")
+	}
+	return nil
+}
+
+// SyncPushFuncDB envia ao peer (um arquivo de banco ou a URL de um togo sync
+// serve) as operações que ele ainda não tem.
+func SyncPushFuncDB(args []string) error {
+	peer, err := dialPeer(args)
+	if err != nil {
+		return err
+	}
+	defer peer.Close()
+
+	result, err := crdt.Push(crdt.Local(), peer)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Enviadas %d operações para %s; %d tarefas criadas ou alteradas lá\n", result.Ops, args[0], result.Tasks)
+	return nil
+}
+
+// SyncPullFuncDB traz do peer as operações que o banco local ainda não tem.
+func SyncPullFuncDB(args []string) error {
+	peer, err := dialPeer(args)
+	if err != nil {
+		return err
+	}
+	defer peer.Close()
+
+	result, err := crdt.Pull(crdt.Local(), peer)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recebidas %d operações de %s; %d tarefas criadas ou alteradas\n", result.Ops, args[0], result.Tasks)
+	return nil
+}
+
+// SyncServeFuncDB atende push e pull de outras máquinas por HTTP em addr.
+func SyncServeFuncDB(args []string, addr string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	fmt.Printf("Atendendo togo sync push/pull em http://%s (Ctrl+C para parar)\n", addr)
+	return http.ListenAndServe(addr, crdt.Handler(crdt.Local()))
+}
+
+func dialPeer(args []string) (crdt.Peer, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("informe o peer: o caminho de um banco ou a URL de um togo sync serve")
+	}
+	target := args[0]
+	if !strings.Contains(target, "://") {
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return nil, err
+		}
+		if local, err := filepath.Abs(database.Path); err == nil && abs == local {
+			return nil, fmt.Errorf("%s é o próprio banco aberto", target)
+		}
+		target = abs
+	}
+	return crdt.Dial(target)
+}
@@ -0,0 +1,296 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/schema"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportFuncDB grava todas as tarefas no formato informado, em output ou na
+// saída padrão se output for vazio.
+func ExportFuncDB(args []string, format, output string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando nao aceita argumentos. voce passou %d argumentos", len(args))
+	}
+
+	f, err := formats.Get(format)
+	if err != nil {
+		return err
+	}
+
+	// Formatos completos levam também as tarefas apagadas.
+	query := database.DB.Order("id asc")
+	if _, ok := f.(formats.Archive); ok {
+		query = query.Unscoped()
+	}
+	var tasks []schema.Task
+	if err := query.Find(&tasks).Error; err != nil {
+		return fmt.Errorf("erro ao ler as tarefas: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("erro ao criar '%s': %w", output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := f.Export(w, tasks); err != nil {
+		return fmt.Errorf("erro ao exportar: %w", err)
+	}
+	if output != "" {
+		fmt.Printf("%d tarefas exportadas para %s\n", len(tasks), output)
+	}
+	return nil
+}
+
+// Políticas de --on-conflict para importar um formato completo
+// (formats.Archive) cujo ID já existe no banco.
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRenumber  = "renumber"
+)
+
+// ImportFuncDB lê as tarefas de um arquivo e as cria no banco, todas em uma
+// única transação. Sem format, o formato é detectado pela extensão. Em
+// formatos com identificador (formats.Upserter), tarefas já existentes são
+// atualizadas. Formatos completos (formats.Archive) preservam os IDs e usam
+// onConflict quando um ID já existe; vazio equivale a ConflictSkip. Um
+// arquivo Markdown é sincronizado como no sync-md, que grava os marcadores.
+func ImportFuncDB(args []string, format, onConflict string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento, você passou %d", len(args))
+	}
+
+	path := args[0]
+	var f formats.Format
+	var err error
+	if format != "" {
+		f, err = formats.Get(format)
+	} else {
+		f, err = formats.Detect(path)
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := f.(formats.Markdown); ok {
+		// Sem os marcadores no arquivo, um sync-md posterior criaria os
+		// mesmos itens de novo.
+		if onConflict != "" {
+			return fmt.Errorf("--on-conflict só vale para o formato json")
+		}
+		return SyncMarkdownFuncDB([]string{path})
+	}
+	_, archive := f.(formats.Archive)
+	switch {
+	case onConflict != "" && !archive:
+		return fmt.Errorf("--on-conflict só vale para o formato json")
+	case onConflict == "":
+		onConflict = ConflictSkip
+	case onConflict != ConflictSkip && onConflict != ConflictOverwrite && onConflict != ConflictRenumber:
+		return fmt.Errorf("--on-conflict inválido '%s' (use %s, %s ou %s)", onConflict, ConflictSkip, ConflictOverwrite, ConflictRenumber)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	tasks, err := f.Import(file)
+	if err != nil {
+		return fmt.Errorf("erro ao ler '%s': %w", path, err)
+	}
+	if archive {
+		return importArchive(path, tasks, onConflict)
+	}
+
+	upserter, _ := f.(formats.Upserter)
+	created, updated := 0, 0
+	err = database.Transaction(func(tx *gorm.DB) error {
+		created, updated = 0, 0
+		existing, err := existingByKey(tx, upserter)
+		if err != nil {
+			return err
+		}
+		for i := range tasks {
+			t := &tasks[i]
+			if upserter != nil {
+				if old, ok := existing[upserter.Key(*t)]; ok {
+					if err := updateImported(tx, old, t, upserter.Fields()); err != nil {
+						return fmt.Errorf("erro ao atualizar '%s': %w", t.Description, err)
+					}
+					updated++
+					continue
+				}
+			}
+			// O ID vindo do arquivo só serve para casar com uma tarefa existente.
+			t.ID = 0
+			if err := tx.Create(t).Error; err != nil {
+				return fmt.Errorf("erro ao salvar '%s': %w", t.Description, err)
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if updated > 0 {
+		fmt.Printf("%d tarefas importadas e %d atualizadas de %s\n", created, updated, path)
+	} else {
+		fmt.Printf("%d tarefas importadas de %s\n", created, path)
+	}
+	return nil
+}
+
+// existingByKey indexa as tarefas do banco pelo identificador do formato.
+// As apagadas entram também: o identificador costuma ser o UUID, que é
+// único mesmo entre elas.
+func existingByKey(tx *gorm.DB, upserter formats.Upserter) (map[string]schema.Task, error) {
+	if upserter == nil {
+		return nil, nil
+	}
+	var tasks []schema.Task
+	if err := tx.Unscoped().Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler as tarefas: %w", err)
+	}
+	byKey := make(map[string]schema.Task, len(tasks))
+	for _, t := range tasks {
+		if key := upserter.Key(t); key != "" {
+			byKey[key] = t
+		}
+	}
+	return byKey, nil
+}
+
+// updateImported sobrescreve em old apenas os campos carregados pelo
+// formato. Uma tarefa importada como apagada apaga a existente; uma
+// importada sem estar apagada restaura a existente, se ela estiver.
+func updateImported(tx *gorm.DB, old schema.Task, t *schema.Task, fields []string) error {
+	if t.DeletedAt.Valid {
+		return tx.Delete(&old).Error
+	}
+	t.ID = old.ID
+	t.UpdatedAt = time.Now()
+	t.DeletedAt = gorm.DeletedAt{}
+	// Outros aplicativos podem concluir a tarefa sem informar quando.
+	if t.Done && t.DoneAt == nil {
+		t.DoneAt = old.DoneAt
+		if t.DoneAt == nil {
+			t.DoneAt = &t.UpdatedAt
+		}
+	}
+	return tx.Unscoped().Model(&old).Select(append(fields, "UpdatedAt", "DeletedAt")).Updates(t).Error
+}
+
+// archiveReport resume uma importação de formato completo.
+type archiveReport struct {
+	created, overwritten, skipped int
+	renumbered                    [][2]uint
+}
+
+// importArchive cria as tarefas com os IDs do arquivo. Tarefas que já
+// existem (mesmo apagadas), pelo UUID ou pelo ID, seguem a política: são
+// ignoradas, sobrescrevem a existente ou são criadas como cópia, com ID e
+// UUID novos. As renumeradas são criadas por último, para não tomarem o ID
+// de outra tarefa do arquivo.
+func importArchive(path string, tasks []schema.Task, policy string) error {
+	var report archiveReport
+	err := database.Transaction(func(tx *gorm.DB) error {
+		report = archiveReport{}
+		var renumber []*schema.Task
+		for i := range tasks {
+			t := &tasks[i]
+			existing, found, err := archiveConflict(tx, t)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case !found:
+				if err := tx.Create(t).Error; err != nil {
+					return fmt.Errorf("erro ao salvar a tarefa %d: %w", t.ID, err)
+				}
+				report.created++
+			case policy == ConflictSkip:
+				report.skipped++
+			case policy == ConflictOverwrite:
+				// O mesmo UUID é a mesma tarefa, ainda que com outro ID aqui.
+				// UpdateColumns não mexe em updated_at, preservando as datas do
+				// arquivo.
+				t.ID = existing.ID
+				err := tx.Unscoped().Model(&schema.Task{}).Where("id = ?", t.ID).Select("*").UpdateColumns(t).Error
+				if err != nil {
+					return fmt.Errorf("erro ao sobrescrever a tarefa %d: %w", t.ID, err)
+				}
+				report.overwritten++
+			case policy == ConflictRenumber:
+				if existing.UUID == t.UUID {
+					t.UUID = ""
+				}
+				renumber = append(renumber, t)
+			}
+		}
+
+		for _, t := range renumber {
+			old := t.ID
+			t.ID = 0
+			if err := tx.Create(t).Error; err != nil {
+				return fmt.Errorf("erro ao salvar a tarefa %d: %w", old, err)
+			}
+			report.renumbered = append(report.renumbered, [2]uint{old, t.ID})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Importação de %s concluída:\n", path)
+	fmt.Printf("  %d criadas\n", report.created)
+	if report.overwritten > 0 {
+		fmt.Printf("  %d sobrescritas\n", report.overwritten)
+	}
+	if report.skipped > 0 {
+		fmt.Printf("  %d ignoradas (o ID ou o UUID já existe; use --on-conflict)\n", report.skipped)
+	}
+	if len(report.renumbered) > 0 {
+		fmt.Printf("  %d renumeradas:\n", len(report.renumbered))
+		for _, ids := range report.renumbered {
+			fmt.Printf("    %d → %d\n", ids[0], ids[1])
+		}
+	}
+	return nil
+}
+
+// archiveConflict procura a tarefa local que conflita com t: a de mesmo
+// UUID, que é a mesma tarefa, ou senão a que ocupa o ID de t.
+func archiveConflict(tx *gorm.DB, t *schema.Task) (schema.Task, bool, error) {
+	var existing schema.Task
+	if t.UUID != "" {
+		result := tx.Unscoped().Where("uuid = ?", t.UUID).Limit(1).Find(&existing)
+		if result.Error != nil {
+			return existing, false, fmt.Errorf("erro ao ler a tarefa %s: %w", t.UUID, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return existing, true, nil
+		}
+	}
+	result := tx.Unscoped().Where("id = ?", t.ID).Limit(1).Find(&existing)
+	if result.Error != nil {
+		return existing, false, fmt.Errorf("erro ao ler a tarefa %d: %w", t.ID, result.Error)
+	}
+	return existing, result.RowsAffected > 0, nil
+}
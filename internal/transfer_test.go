@@ -0,0 +1,410 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"levyvix/togo/internal/hooks"
+	"levyvix/togo/schema"
+
+	"gorm.io/gorm"
+)
+
+// TestImportExportTodoTxt tests importing a todo.txt file and exporting it back
+func TestImportExportTodoTxt(t *testing.T) {
+	clearDB(t)
+	dir := t.TempDir()
+	input := "(A) 2025-01-01 Ligar para a mãe +família @telefone due:2025-02-01\n" +
+		"x 2025-01-03 2024-12-01 Fazer compras pri:B\n"
+	in := filepath.Join(dir, "todo.txt")
+	if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", "") }); err != nil {
+		t.Fatalf("ImportFuncDB(%q) unexpected error: %v", in, err)
+	}
+
+	var tasks []schema.Task
+	testDB.Order("id asc").Find(&tasks)
+	if len(tasks) != 2 {
+		t.Fatalf("ImportFuncDB should have created 2 tasks, created %d", len(tasks))
+	}
+	if tasks[0].Priority != "A" || tasks[0].Due == nil || tasks[0].Due.Format("2006-01-02") != "2025-02-01" {
+		t.Errorf("imported task = %+v, want priority A and due date", tasks[0])
+	}
+	if got := tasks[1].CreatedAt.Format("2006-01-02"); got != "2024-12-01" {
+		t.Errorf("imported CreatedAt = %s, want 2024-12-01 (not the import time)", got)
+	}
+
+	out := filepath.Join(dir, "export.txt")
+	if _, err := captureOutput(t, func() error { return ExportFuncDB(nil, "todotxt", out) }); err != nil {
+		t.Fatalf("ExportFuncDB unexpected error: %v", err)
+	}
+	exported, _ := os.ReadFile(out)
+	want := "(A) 2025-01-01 Ligar para a mãe +família @telefone due:2025-02-01 uuid:" + tasks[0].UUID + "\n" +
+		"x 2025-01-03 2024-12-01 Fazer compras pri:B uuid:" + tasks[1].UUID + "\n"
+	if string(exported) != want {
+		t.Errorf("exported file =\n%s\nwant\n%s", exported, want)
+	}
+
+	// Com o uuid no arquivo, importá-lo de novo não duplica as tarefas.
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{out}, "", "") }); err != nil {
+		t.Fatalf("ImportFuncDB(%q) unexpected error: %v", out, err)
+	}
+	var count int64
+	testDB.Model(&schema.Task{}).Count(&count)
+	if count != 2 {
+		t.Errorf("tasks after reimporting the export = %d, want 2", count)
+	}
+}
+
+// TestImportRestoresDeleted tests that importing an export after clear brings the tasks back
+func TestImportRestoresDeleted(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	t.Setenv("TOGO_SNAPSHOTS", "0")
+	dir := t.TempDir()
+	in := filepath.Join(dir, "todo.txt")
+	os.WriteFile(in, []byte("Ligar para a mãe +família\n"), 0644)
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", "") }); err != nil {
+		t.Fatal(err)
+	}
+	var task schema.Task
+	testDB.First(&task)
+	if _, err := captureOutput(t, func() error { return DoneFuncDB([]string{itoa(task.ID)}) }); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "out.txt")
+	if _, err := captureOutput(t, func() error { return ExportFuncDB(nil, "todotxt", out) }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureOutput(t, func() error { return ClearDB(nil) }); err != nil {
+		t.Fatal(err)
+	}
+	output, err := captureOutput(t, func() error { return ImportFuncDB([]string{out}, "", "") })
+	if err != nil {
+		t.Fatalf("ImportFuncDB unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "0 tarefas importadas e 1 atualizadas") {
+		t.Errorf("ImportFuncDB output = %q, want the task updated", output)
+	}
+
+	var tasks []schema.Task
+	testDB.Find(&tasks)
+	if len(tasks) != 1 || !tasks[0].Done || tasks[0].Description != "Ligar para a mãe" {
+		t.Errorf("visible tasks after import = %+v, want the restored done task", tasks)
+	}
+}
+
+// TestImportMarkdownWritesMarkers tests that a later sync-md does not create the imported items again
+func TestImportMarkdownWritesMarkers(t *testing.T) {
+	t.Setenv(hooks.DisableEnv, "0")
+	clearDB(t)
+	path := filepath.Join(t.TempDir(), "notas.md")
+	os.WriteFile(path, []byte("# Reunião\n\n- [ ] Enviar a ata\n- [ ] Marcar a próxima\n- [x] Já feita\n"), 0644)
+
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{path}, "", "") }); err != nil {
+		t.Fatalf("ImportFuncDB unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), "<!-- togo:") != 2 {
+		t.Errorf("imported file should have a marker per created task:\n%s", data)
+	}
+	if _, err := captureOutput(t, func() error { return SyncMarkdownFuncDB([]string{path}) }); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	testDB.Model(&schema.Task{}).Count(&count)
+	if count != 2 {
+		t.Errorf("tasks after import and sync-md = %d, want 2", count)
+	}
+}
+
+// TestImportFuncDBInvalid tests that a bad line aborts the whole import
+func TestImportFuncDBInvalid(t *testing.T) {
+	clearDB(t)
+	in := filepath.Join(t.TempDir(), "todo.txt")
+	if err := os.WriteFile(in, []byte("Tarefa boa\n+so-projeto\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", "") }); err == nil {
+		t.Errorf("ImportFuncDB with invalid line expected error, got nil")
+	}
+	var count int64
+	testDB.Model(&schema.Task{}).Count(&count)
+	if count != 0 {
+		t.Errorf("ImportFuncDB with invalid line created %d tasks, want 0", count)
+	}
+
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in, "extra"}, "", "") }); err == nil {
+		t.Errorf("ImportFuncDB with two arguments expected error, got nil")
+	}
+}
+
+// TestImportTaskwarriorTimestamps tests that imported timestamps are not overwritten by gorm
+func TestImportTaskwarriorTimestamps(t *testing.T) {
+	clearDB(t)
+	input := `[{"description":"Antiga","entry":"20200101T120000Z","modified":"20200105T120000Z","status":"pending","uuid":"u1"},
+{"description":"Apagada","entry":"20200101T120000Z","end":"20200102T120000Z","status":"deleted","uuid":"u2"}]`
+	in := filepath.Join(t.TempDir(), "tw.json")
+	if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "taskwarrior", "") }); err != nil {
+		t.Fatalf("ImportFuncDB unexpected error: %v", err)
+	}
+
+	var task schema.Task
+	testDB.Where("description = ?", "Antiga").First(&task)
+	if got := task.CreatedAt.UTC().Format(time.RFC3339); got != "2020-01-01T12:00:00Z" {
+		t.Errorf("CreatedAt = %s, want 2020-01-01T12:00:00Z", got)
+	}
+	if got := task.UpdatedAt.UTC().Format(time.RFC3339); got != "2020-01-05T12:00:00Z" {
+		t.Errorf("UpdatedAt = %s, want 2020-01-05T12:00:00Z", got)
+	}
+
+	var active, all int64
+	testDB.Model(&schema.Task{}).Count(&active)
+	testDB.Unscoped().Model(&schema.Task{}).Count(&all)
+	if active != 1 || all != 2 {
+		t.Errorf("tasks = %d active / %d total, want 1 / 2 (deleted task kept as soft-deleted)", active, all)
+	}
+}
+
+// TestImportICalUpsert tests that re-importing an edited ics file updates tasks by UID
+func TestImportICalUpsert(t *testing.T) {
+	clearDB(t)
+	annotated := schema.Task{Description: "Pagar conta", Contexts: []string{"casa"}}
+	testDB.Create(&annotated)
+	testDB.Create(&schema.Task{Description: "Ler livro"})
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "tarefas.ics")
+	if _, err := captureOutput(t, func() error { return ExportFuncDB(nil, "ics", out) }); err != nil {
+		t.Fatalf("ExportFuncDB unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	edited := strings.Replace(string(data), "SUMMARY:Pagar conta\r\nSTATUS:NEEDS-ACTION", "SUMMARY:Pagar conta de luz\r\nSTATUS:COMPLETED", 1)
+	edited = strings.Replace(edited, "END:VCALENDAR", "BEGIN:VTODO\r\nUID:nova@outro-app\r\nSUMMARY:Nova\r\nEND:VTODO\r\nEND:VCALENDAR", 1)
+	if err := os.WriteFile(out, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := captureOutput(t, func() error { return ImportFuncDB([]string{out}, "", "") })
+	if err != nil {
+		t.Fatalf("ImportFuncDB unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "1 tarefas importadas e 2 atualizadas") {
+		t.Errorf("ImportFuncDB output = %q, want 1 created and 2 updated", output)
+	}
+
+	var tasks []schema.Task
+	testDB.Order("id asc").Find(&tasks)
+	if len(tasks) != 3 {
+		t.Fatalf("tasks after import = %d, want 3", len(tasks))
+	}
+	first := tasks[0]
+	if first.Description != "Pagar conta de luz" || !first.Done || first.DoneAt == nil {
+		t.Errorf("updated task = %q done=%v doneAt=%v, want new summary and done", first.Description, first.Done, first.DoneAt)
+	}
+	if len(first.Contexts) != 1 || first.Contexts[0] != "casa" {
+		t.Errorf("updated task contexts = %v, fields not in ics must be kept", first.Contexts)
+	}
+
+	// Reimportar o mesmo arquivo não cria nada.
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{out}, "", "") }); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	testDB.Model(&schema.Task{}).Count(&count)
+	if count != 3 {
+		t.Errorf("tasks after second import = %d, want 3", count)
+	}
+}
+
+// TestImportOrgUpsert tests that the ID property updates existing tasks and unknown IDs create new ones
+func TestImportOrgUpsert(t *testing.T) {
+	clearDB(t)
+	task := schema.Task{Description: "Revisar PR", Annotations: []schema.Annotation{{Entry: time.Now(), Description: "nota"}}}
+	testDB.Create(&task)
+
+	input := "* DONE [#A] Revisar PR de novo :review:\n  :PROPERTIES:\n  :ID: " + task.UUID + "\n  :END:\n" +
+		"* TODO Veio de outro banco\n  :PROPERTIES:\n  :ID: 6d1c0f5e-3b2a-4c9d-8e7f-102132435465\n  :END:\n"
+	in := filepath.Join(t.TempDir(), "tarefas.org")
+	if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", "") }); err != nil {
+		t.Fatalf("ImportFuncDB unexpected error: %v", err)
+	}
+
+	var tasks []schema.Task
+	testDB.Order("id asc").Find(&tasks)
+	if len(tasks) != 2 {
+		t.Fatalf("tasks after import = %d, want 2", len(tasks))
+	}
+	if got := tasks[0]; got.Description != "Revisar PR de novo" || !got.Done || got.Priority != "A" || len(got.Annotations) != 1 {
+		t.Errorf("updated task = %+v, want new fields and the annotation kept", got)
+	}
+	if tasks[1].UUID != "6d1c0f5e-3b2a-4c9d-8e7f-102132435465" {
+		t.Errorf("a task with an unknown ID should be created with it as UUID, got %q", tasks[1].UUID)
+	}
+}
+
+// TestImportJSONConflicts tests the skip, overwrite and renumber policies
+func TestImportJSONConflicts(t *testing.T) {
+	created := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	input := `{"format": "togo", "version": 1, "tasks": [
+{"id": 1, "created_at": "2024-06-01T10:00:00Z", "updated_at": "2024-06-02T10:00:00Z", "description": "Do arquivo 1", "owner_id": 3},
+{"id": 2, "created_at": "2024-06-01T10:00:00Z", "description": "Do arquivo 2", "deleted_at": "2024-06-03T10:00:00Z"},
+{"id": 5, "created_at": "2024-06-01T10:00:00Z", "description": "Do arquivo 5"}
+]}`
+	in := filepath.Join(t.TempDir(), "lista.json")
+	if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	setup := func(t *testing.T) {
+		clearDB(t)
+		testDB.Create(&schema.Task{Model: gorm.Model{ID: 1}, Description: "Local 1"})
+	}
+	description := func(id uint) string {
+		var task schema.Task
+		testDB.Unscoped().First(&task, id)
+		return task.Description
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		setup(t)
+		output, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", "") })
+		if err != nil {
+			t.Fatalf("ImportFuncDB unexpected error: %v", err)
+		}
+		if description(1) != "Local 1" || description(5) != "Do arquivo 5" {
+			t.Errorf("skip: tasks 1/5 = %q/%q", description(1), description(5))
+		}
+		if !strings.Contains(output, "2 criadas") || !strings.Contains(output, "1 ignoradas") {
+			t.Errorf("skip: report = %q", output)
+		}
+		var deleted schema.Task
+		testDB.Unscoped().First(&deleted, 2)
+		if !deleted.DeletedAt.Valid {
+			t.Errorf("skip: task 2 should be imported as soft-deleted")
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		setup(t)
+		if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", ConflictOverwrite) }); err != nil {
+			t.Fatalf("ImportFuncDB unexpected error: %v", err)
+		}
+		var task schema.Task
+		testDB.First(&task, 1)
+		if task.Description != "Do arquivo 1" || !task.CreatedAt.Equal(created) || task.OwnerID == nil || *task.OwnerID != 3 {
+			t.Errorf("overwrite: task 1 = %q created %v owner %v, want file contents", task.Description, task.CreatedAt, task.OwnerID)
+		}
+		if got := task.UpdatedAt.UTC().Format(time.RFC3339); got != "2024-06-02T10:00:00Z" {
+			t.Errorf("overwrite: UpdatedAt = %s, want the file timestamp", got)
+		}
+	})
+
+	t.Run("renumber", func(t *testing.T) {
+		setup(t)
+		output, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", ConflictRenumber) })
+		if err != nil {
+			t.Fatalf("ImportFuncDB unexpected error: %v", err)
+		}
+		var moved schema.Task
+		testDB.Where("description = ?", "Do arquivo 1").First(&moved)
+		if description(1) != "Local 1" || moved.ID <= 5 {
+			t.Errorf("renumber: task 1 = %q, file task got ID %d, want a new ID after 5", description(1), moved.ID)
+		}
+		if !strings.Contains(output, fmt.Sprintf("1 → %d", moved.ID)) {
+			t.Errorf("renumber: report = %q", output)
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		setup(t)
+		if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", "merge") }); err == nil {
+			t.Errorf("ImportFuncDB with --on-conflict=merge expected error, got nil")
+		}
+		if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "todotxt", ConflictSkip) }); err == nil {
+			t.Errorf("ImportFuncDB with --on-conflict on todotxt expected error, got nil")
+		}
+	})
+}
+
+// TestImportJSONSameUUID tests that a record whose UUID exists locally under another ID is the same task
+func TestImportJSONSameUUID(t *testing.T) {
+	const id = "c2a4e6f8-0b1d-4e3f-8a5b-7c9d1e2f3a4b"
+	input := `{"format": "togo", "version": 2, "tasks": [
+{"id": 9, "uuid": "` + id + `", "created_at": "2024-06-01T10:00:00Z", "description": "Do outro banco"}
+]}`
+	in := filepath.Join(t.TempDir(), "lista.json")
+	if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	setup := func(t *testing.T) {
+		clearDB(t)
+		testDB.Create(&schema.Task{Model: gorm.Model{ID: 7}, UUID: id, Description: "Local"})
+	}
+
+	t.Run("overwrite", func(t *testing.T) {
+		setup(t)
+		if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", ConflictOverwrite) }); err != nil {
+			t.Fatalf("ImportFuncDB unexpected error: %v", err)
+		}
+		var tasks []schema.Task
+		testDB.Find(&tasks)
+		if len(tasks) != 1 || tasks[0].ID != 7 || tasks[0].Description != "Do outro banco" {
+			t.Errorf("overwrite: tasks = %+v, want task 7 overwritten", tasks)
+		}
+	})
+
+	t.Run("renumber", func(t *testing.T) {
+		setup(t)
+		if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{in}, "", ConflictRenumber) }); err != nil {
+			t.Fatalf("ImportFuncDB unexpected error: %v", err)
+		}
+		var copied schema.Task
+		testDB.Where("description = ?", "Do outro banco").First(&copied)
+		if copied.UUID == "" || copied.UUID == id {
+			t.Errorf("renumber: copy UUID = %q, want a new one", copied.UUID)
+		}
+	})
+}
+
+// TestExportJSONIncludesDeleted tests that the json export carries soft-deleted tasks
+func TestExportJSONIncludesDeleted(t *testing.T) {
+	clearDB(t)
+	kept := schema.Task{Description: "Ativa"}
+	gone := schema.Task{Description: "Apagada"}
+	testDB.Create(&kept)
+	testDB.Create(&gone)
+	testDB.Delete(&gone)
+
+	out := filepath.Join(t.TempDir(), "lista.json")
+	if _, err := captureOutput(t, func() error { return ExportFuncDB(nil, "json", out) }); err != nil {
+		t.Fatalf("ExportFuncDB unexpected error: %v", err)
+	}
+
+	clearDB(t)
+	if _, err := captureOutput(t, func() error { return ImportFuncDB([]string{out}, "", "") }); err != nil {
+		t.Fatalf("ImportFuncDB unexpected error: %v", err)
+	}
+	var all []schema.Task
+	testDB.Unscoped().Order("id asc").Find(&all)
+	if len(all) != 2 || all[0].ID != kept.ID || all[1].ID != gone.ID || !all[1].DeletedAt.Valid {
+		t.Errorf("tasks after round trip = %+v, want both with the same IDs and the deleted one kept deleted", all)
+	}
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"levyvix/togo/internal/service"
+	"strconv"
+)
+
+func UserAddFuncDB(args []string, admin bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento (o nome), você passou %d", len(args))
+	}
+	u, err := service.Users{}.Add(context.Background(), args[0], admin)
+	if err != nil {
+		return err
+	}
+	role := "usuário"
+	if u.Admin {
+		role = "administrador"
+	}
+	fmt.Printf("Usuário '%s' criado (%s). Crie um token com: togo token create %s\n", u.Name, role, u.Name)
+	return nil
+}
+
+func UserListFuncDB(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	users, err := service.Users{}.List(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		fmt.Println("Nenhum usuário cadastrado: o togo serve não exige autenticação.")
+		return nil
+	}
+	for _, u := range users {
+		role := ""
+		if u.Admin {
+			role = " (administrador)"
+		}
+		fmt.Printf("%s%s, criado em %s\n", u.Name, role, formatDate(u.CreatedAt))
+	}
+	return nil
+}
+
+func TokenCreateFuncDB(args []string, name string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento (o usuário), você passou %d", len(args))
+	}
+	plain, token, err := service.Users{}.CreateToken(context.Background(), args[0], name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Token %d criado para '%s'. Guarde-o agora; ele não será mostrado de novo:\n\n", token.ID, args[0])
+	fmt.Printf("  %s\n\n", plain)
+	fmt.Println("Use no cabeçalho: Authorization: Bearer <token>")
+	return nil
+}
+
+func TokenListFuncDB(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	tokens, err := service.Users{}.Tokens(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Println("Nenhum token criado.")
+		return nil
+	}
+	for _, t := range tokens {
+		used := "nunca usado"
+		if t.LastUsedAt != nil {
+			used = "usado em " + formatDate(*t.LastUsedAt)
+		}
+		label := ""
+		if t.Name != "" {
+			label = " " + t.Name
+		}
+		fmt.Printf("[%d] %s%s, criado em %s, %s\n", t.ID, t.UserName, label, formatDate(t.CreatedAt), used)
+	}
+	return nil
+}
+
+func TokenRevokeFuncDB(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento (o ID do token), você passou %d", len(args))
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("ID de token inválido '%s'", args[0])
+	}
+	if err := (service.Users{}).RevokeToken(context.Background(), uint(id)); err != nil {
+		return err
+	}
+	fmt.Printf("Token %d revogado.\n", id)
+	return nil
+}
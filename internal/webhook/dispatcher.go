@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/schema"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Dispatcher envia as entregas da fila, tentando de novo as que falham.
+type Dispatcher struct {
+	Client *http.Client
+	// MaxAttempts é o número de tentativas antes de a entrega ser dada como
+	// falha.
+	MaxAttempts int
+	// Backoff é a espera antes da tentativa seguinte à de número attempt.
+	Backoff func(attempt int) time.Duration
+	// Poll é o intervalo em que a fila é verificada sem eventos novos.
+	Poll time.Duration
+	// Logf recebe os erros que não são de uma entrega (que ficam no
+	// registro de entregas); nil os descarta.
+	Logf func(format string, args ...any)
+
+	now func() time.Time
+}
+
+// NewDispatcher cria um Dispatcher com os valores padrão: 8 tentativas, com
+// esperas de 30s dobrando até 1h.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 8,
+		Backoff:     Backoff,
+		Poll:        5 * time.Second,
+		now:         time.Now,
+	}
+}
+
+// Backoff é a espera padrão depois da tentativa attempt: 30s, 1min, 2min...
+// até no máximo 1h.
+func Backoff(attempt int) time.Duration {
+	wait := 30 * time.Second
+	for i := 1; i < attempt && wait < time.Hour; i++ {
+		wait *= 2
+	}
+	return min(wait, time.Hour)
+}
+
+func (d *Dispatcher) logf(format string, args ...any) {
+	if d.Logf != nil {
+		d.Logf(format, args...)
+	}
+}
+
+// Run envia as entregas da fila até ctx acabar, incluindo as pendentes de
+// execuções anteriores e as enfileiradas por outros processos (a linha de
+// comando), verificadas a cada Poll. Os eventos de bus só adiantam o envio
+// das entregas das alterações feitas neste processo.
+func (d *Dispatcher) Run(ctx context.Context, bus *events.Bus) {
+	sub := bus.Subscribe("")
+	defer func() { sub.Close() }()
+	ticker := time.NewTicker(d.Poll)
+	defer ticker.Stop()
+
+	d.deliverDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case _, ok := <-sub.C:
+			if !ok {
+				// Ficou para trás; a fila no banco já tem tudo.
+				sub = bus.Subscribe("")
+			}
+		}
+		d.deliverDue(ctx)
+	}
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	if _, err := d.DeliverDue(ctx); err != nil {
+		d.logf("webhooks: %v", err)
+	}
+}
+
+// DeliverDue envia as entregas pendentes cuja hora chegou e retorna
+// quantas foram tentadas.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	var due []schema.WebhookDelivery
+	err := database.DB.Where("status = ? AND next_attempt <= ?", schema.DeliveryPending, d.now()).
+		Order("next_attempt asc, id asc").Limit(100).Find(&due).Error
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ler a fila de entregas: %w", err)
+	}
+	hooks := map[uint]schema.Webhook{}
+	for i := range due {
+		if ctx.Err() != nil {
+			return i, nil
+		}
+		hook, ok := hooks[due[i].WebhookID]
+		if !ok {
+			if err := database.DB.First(&hook, due[i].WebhookID).Error; err != nil {
+				return i, fmt.Errorf("erro ao ler o webhook %d: %w", due[i].WebhookID, err)
+			}
+			hooks[hook.ID] = hook
+		}
+		d.attempt(ctx, hook, &due[i])
+		if ctx.Err() != nil {
+			// Interrompida pelo fim do servidor: continua pendente.
+			return i, nil
+		}
+		if err := database.Transaction(func(tx *gorm.DB) error { return tx.Save(&due[i]).Error }); err != nil {
+			return i, fmt.Errorf("erro ao gravar a entrega %d: %w", due[i].ID, err)
+		}
+	}
+	return len(due), nil
+}
+
+// attempt faz uma tentativa de entrega e registra o resultado em del.
+func (d *Dispatcher) attempt(ctx context.Context, hook schema.Webhook, del *schema.WebhookDelivery) {
+	del.Attempts++
+	del.LastCode, del.LastError = 0, ""
+	err := d.post(ctx, hook, del)
+	now := d.now()
+	switch {
+	case err == nil:
+		del.Status = schema.DeliveryDelivered
+		del.DeliveredAt = &now
+	case del.Attempts >= d.MaxAttempts:
+		del.Status = schema.DeliveryFailed
+		del.LastError = err.Error()
+	default:
+		del.NextAttempt = now.Add(d.Backoff(del.Attempts))
+		del.LastError = err.Error()
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, hook schema.Webhook, del *schema.WebhookDelivery) error {
+	body := []byte(del.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "togo-webhook")
+	req.Header.Set(HeaderEvent, del.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(del.ID), 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	del.LastCode = resp.StatusCode
+	if resp.StatusCode/100 != 2 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	return nil
+}
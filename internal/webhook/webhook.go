@@ -0,0 +1,231 @@
+// Package webhook envia os eventos de tarefas, por POST com JSON assinado
+// (HMAC-SHA256), aos webhooks cadastrados. Cada alteração, pela API ou pela
+// linha de comando, põe as suas entregas em uma fila no banco na mesma
+// transação (Enqueue); o togo serve as envia, com novas tentativas em
+// intervalos crescentes, e elas ficam depois como registro do resultado.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/internal/formats"
+	"levyvix/togo/schema"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Cabeçalhos de cada entrega.
+const (
+	HeaderEvent     = "X-Togo-Event"
+	HeaderDelivery  = "X-Togo-Delivery"
+	HeaderSignature = "X-Togo-Signature"
+)
+
+// eventNames são os nomes curtos aceitos em --events, na ordem da ajuda.
+var eventNames = []struct {
+	name string
+	typ  events.Type
+}{
+	{"create", events.Created},
+	{"update", events.Updated},
+	{"done", events.Done},
+	{"delete", events.Deleted},
+	{"clear", events.Cleared},
+}
+
+// EventNames retorna os nomes curtos dos eventos, para a ajuda.
+func EventNames() []string {
+	names := make([]string, 0, len(eventNames))
+	for _, e := range eventNames {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+// ParseEvents lê a lista de eventos separada por vírgulas, com os nomes
+// curtos (create, done...) ou os tipos (task.created, task.done...), e
+// retorna os tipos. Vazio ou "all" assina todos e retorna nil.
+func ParseEvents(list string) ([]string, error) {
+	if strings.TrimSpace(list) == "" || strings.TrimSpace(list) == "all" {
+		return nil, nil
+	}
+	var types []string
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		typ := ""
+		for _, e := range eventNames {
+			if item == e.name || item == string(e.typ) {
+				typ = string(e.typ)
+			}
+		}
+		if typ == "" {
+			return nil, fmt.Errorf("evento desconhecido '%s' (use %s ou all)", item, strings.Join(EventNames(), ", "))
+		}
+		if !slices.Contains(types, typ) {
+			types = append(types, typ)
+		}
+	}
+	return types, nil
+}
+
+// Add cadastra um webhook para rawURL. Sem secret, gera uma chave
+// aleatória.
+func Add(rawURL string, types []string, secret string) (schema.Webhook, error) {
+	hook := schema.Webhook{URL: rawURL, Events: types, Secret: secret}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return hook, fmt.Errorf("URL inválida '%s': use um endereço http:// ou https://", rawURL)
+	}
+	if hook.Secret == "" {
+		key := make([]byte, 24)
+		if _, err := rand.Read(key); err != nil {
+			return hook, err
+		}
+		hook.Secret = hex.EncodeToString(key)
+	}
+	err = database.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&hook).Error
+	})
+	if err != nil {
+		return hook, fmt.Errorf("erro ao salvar o webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// List retorna os webhooks, em ordem de cadastro.
+func List() ([]schema.Webhook, error) {
+	var hooks []schema.Webhook
+	if err := database.DB.Order("id asc").Find(&hooks).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler os webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// Remove apaga o webhook de ID id e as suas entregas.
+func Remove(id uint) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&schema.Webhook{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("erro ao apagar o webhook: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("webhook %d não existe", id)
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&schema.WebhookDelivery{}).Error
+	})
+}
+
+// Log retorna as últimas limit entregas, da mais recente para a mais
+// antiga; com webhookID diferente de zero, só as desse webhook.
+func Log(webhookID uint, limit int) ([]schema.WebhookDelivery, error) {
+	query := database.DB.Order("id desc").Limit(limit)
+	if webhookID != 0 {
+		query = query.Where("webhook_id = ?", webhookID)
+	}
+	var deliveries []schema.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("erro ao ler as entregas: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Retry devolve à fila uma entrega que falhou, para ser tentada de novo
+// pelo togo serve assim que possível.
+func Retry(id uint) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		var d schema.WebhookDelivery
+		result := tx.Limit(1).Find(&d, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("entrega %d não existe", id)
+		}
+		if d.Status != schema.DeliveryFailed {
+			return fmt.Errorf("a entrega %d não falhou (está %s)", id, d.Status)
+		}
+		return tx.Model(&d).Updates(map[string]any{
+			"status":       schema.DeliveryPending,
+			"attempts":     0,
+			"next_attempt": time.Now(),
+		}).Error
+	})
+}
+
+// Payload é o corpo JSON de uma entrega.
+type Payload struct {
+	ID   string          `json:"id"`
+	Type events.Type     `json:"type"`
+	Time time.Time       `json:"time"`
+	Task *formats.Record `json:"task,omitempty"`
+}
+
+// Sign retorna a assinatura do corpo enviada em X-Togo-Signature:
+// "sha256=" e o HMAC-SHA256 de body com a chave do webhook, em hex.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify confere a assinatura de um corpo recebido, em tempo constante.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// subscribed informa se o webhook assina eventos do tipo typ.
+func subscribed(hook schema.Webhook, typ events.Type) bool {
+	return len(hook.Events) == 0 || slices.Contains(hook.Events, string(typ))
+}
+
+// Enqueue põe na fila, dentro de tx, uma entrega do evento typ sobre t
+// (nil em events.Cleared) para cada webhook que assina o tipo, e retorna
+// quantas foram criadas. Deve rodar na transação da própria alteração, para
+// que o evento só seja entregue se ela for confirmada, venha ela da API ou
+// da linha de comando.
+func Enqueue(tx *gorm.DB, typ events.Type, t *schema.Task) (int, error) {
+	payload := Payload{ID: uuid.NewString(), Type: typ, Time: time.Now().UTC()}
+	if t != nil {
+		record := formats.NewRecord(*t).UTC()
+		payload.Task = &record
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	var hooks []schema.Webhook
+	if err := tx.Find(&hooks).Error; err != nil {
+		return 0, fmt.Errorf("erro ao ler os webhooks: %w", err)
+	}
+	count := 0
+	for _, hook := range hooks {
+		if !subscribed(hook, typ) {
+			continue
+		}
+		d := schema.WebhookDelivery{
+			WebhookID:   hook.ID,
+			EventID:     payload.ID,
+			Event:       string(typ),
+			Payload:     string(body),
+			Status:      schema.DeliveryPending,
+			NextAttempt: time.Now(),
+		}
+		if err := tx.Create(&d).Error; err != nil {
+			return count, fmt.Errorf("erro ao enfileirar o evento %s: %w", payload.ID, err)
+		}
+		count++
+	}
+	return count, nil
+}
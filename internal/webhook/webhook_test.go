@@ -0,0 +1,274 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"levyvix/togo/internal/database"
+	"levyvix/togo/internal/events"
+	"levyvix/togo/schema"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB opens an empty database for the test
+func openTestDB(t *testing.T) {
+	t.Helper()
+	if err := database.Open(filepath.Join(t.TempDir(), "tasks.db")); err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+		database.DB = nil
+	})
+}
+
+// received is a request seen by a test receiver
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a webhook receiver that answers with status
+func newReceiver(t *testing.T, status int) (*httptest.Server, <-chan received) {
+	t.Helper()
+	ch := make(chan received, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ch <- received{header: r.Header, body: body}
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, ch
+}
+
+// TestParseEvents tests parsing the --events list
+func TestParseEvents(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{list: "", want: nil},
+		{list: "all", want: nil},
+		{list: "done,create", want: []string{"task.done", "task.created"}},
+		{list: " done , task.done,delete", want: []string{"task.done", "task.deleted"}},
+		{list: "done,finish", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseEvents(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEvents(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseEvents(%q) = %v, want %v", tt.list, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParseEvents(%q) = %v, want %v", tt.list, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// TestBackoff tests that the default backoff doubles up to an hour
+func TestBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		8:  time.Hour,
+		50: time.Hour,
+	} {
+		if got := Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+// TestAddValidatesURL tests that only http(s) URLs are accepted
+func TestAddValidatesURL(t *testing.T) {
+	openTestDB(t)
+	for _, u := range []string{"ftp://exemplo.com", "exemplo.com/hook", "http://"} {
+		if _, err := Add(u, nil, ""); err == nil {
+			t.Errorf("Add(%q) expected error, got nil", u)
+		}
+	}
+	hook, err := Add("https://exemplo.com/hook", nil, "")
+	if err != nil || len(hook.Secret) != 48 {
+		t.Errorf("Add() = %+v, %v, want a generated secret", hook, err)
+	}
+}
+
+// enqueue queues an event in its own transaction and returns the number of deliveries
+func enqueue(t *testing.T, typ events.Type, task *schema.Task) int {
+	t.Helper()
+	var n int
+	err := database.Transaction(func(tx *gorm.DB) error {
+		var err error
+		n, err = Enqueue(tx, typ, task)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Enqueue(%s) unexpected error: %v", typ, err)
+	}
+	return n
+}
+
+// TestEnqueueRollback tests that deliveries of a rolled back change are not queued
+func TestEnqueueRollback(t *testing.T) {
+	openTestDB(t)
+	Add("https://exemplo.com/hook", nil, "segredo")
+	database.Transaction(func(tx *gorm.DB) error {
+		task := schema.Task{Description: "Estudar Go"}
+		tx.Create(&task)
+		if n, err := Enqueue(tx, events.Created, &task); err != nil || n != 1 {
+			t.Errorf("Enqueue() = %d, %v, want 1 delivery", n, err)
+		}
+		return errors.New("desfaz")
+	})
+	if log, _ := Log(0, 10); len(log) != 0 {
+		t.Errorf("deliveries after rollback = %d, want 0", len(log))
+	}
+}
+
+// TestDeliverAndRetry tests signed deliveries, event filtering, backoff and the delivery log
+func TestDeliverAndRetry(t *testing.T) {
+	openTestDB(t)
+	ok, okCh := newReceiver(t, http.StatusOK)
+	broken, brokenCh := newReceiver(t, http.StatusInternalServerError)
+	doneHook, _ := Add(ok.URL, []string{string(events.Done)}, "segredo")
+	allHook, _ := Add(broken.URL, nil, "outro")
+
+	task := &schema.Task{Description: "Estudar Go", UUID: "0b7c1c3e-0000-4000-8000-000000000001"}
+	if n := enqueue(t, events.Created, task); n != 1 {
+		t.Fatalf("Enqueue(created) = %d, want 1 delivery", n)
+	}
+	task.Done = true
+	if n := enqueue(t, events.Done, task); n != 2 {
+		t.Fatalf("Enqueue(done) = %d, want 2 deliveries", n)
+	}
+
+	now := time.Now()
+	d := NewDispatcher()
+	d.MaxAttempts = 2
+	d.now = func() time.Time { return now }
+	if n, err := d.DeliverDue(context.Background()); err != nil || n != 3 {
+		t.Fatalf("DeliverDue() = %d, %v, want 3 attempts", n, err)
+	}
+
+	got := <-okCh
+	if !Verify("segredo", got.body, got.header.Get(HeaderSignature)) {
+		t.Errorf("signature %q does not match the body", got.header.Get(HeaderSignature))
+	}
+	if got.header.Get(HeaderEvent) != "task.done" || got.header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want task.done JSON", got.header)
+	}
+	var payload Payload
+	if err := json.Unmarshal(got.body, &payload); err != nil || payload.Type != events.Done || payload.Task == nil || !payload.Task.Done {
+		t.Errorf("payload = %s, want the done task", got.body)
+	}
+	if len(okCh) != 0 {
+		t.Error("webhook subscribed to done received another event")
+	}
+	if len(brokenCh) != 2 {
+		t.Errorf("broken receiver got %d requests, want 2", len(brokenCh))
+	}
+
+	log, _ := Log(allHook.ID, 10)
+	for _, del := range log {
+		if del.Status != schema.DeliveryPending || del.Attempts != 1 || del.LastCode != 500 || del.LastError == "" {
+			t.Errorf("failed delivery = %+v, want pending after 1 attempt with HTTP 500", del)
+		}
+		if !del.NextAttempt.Equal(now.Add(30 * time.Second)) {
+			t.Errorf("next attempt = %v, want %v", del.NextAttempt, now.Add(30*time.Second))
+		}
+	}
+	if log, _ := Log(doneHook.ID, 10); len(log) != 1 || log[0].Status != schema.DeliveryDelivered || log[0].DeliveredAt == nil {
+		t.Errorf("delivered log = %+v, want one delivered entry", log)
+	}
+
+	if n, _ := d.DeliverDue(context.Background()); n != 0 {
+		t.Errorf("DeliverDue() before the backoff = %d, want 0", n)
+	}
+	now = now.Add(30 * time.Second)
+	if n, _ := d.DeliverDue(context.Background()); n != 2 {
+		t.Errorf("DeliverDue() after the backoff = %d, want 2", n)
+	}
+	log, _ = Log(allHook.ID, 10)
+	for _, del := range log {
+		if del.Status != schema.DeliveryFailed || del.Attempts != 2 {
+			t.Errorf("delivery = %+v, want failed after 2 attempts", del)
+		}
+	}
+
+	if err := Retry(log[0].ID); err != nil {
+		t.Fatalf("Retry() unexpected error: %v", err)
+	}
+	if err := Retry(log[0].ID); err == nil {
+		t.Error("Retry() of a pending delivery expected error, got nil")
+	}
+	if n, _ := d.DeliverDue(context.Background()); n != 1 {
+		t.Errorf("DeliverDue() after Retry = %d, want 1", n)
+	}
+
+	if err := Remove(allHook.ID); err != nil {
+		t.Fatalf("Remove() unexpected error: %v", err)
+	}
+	if log, _ := Log(allHook.ID, 10); len(log) != 0 {
+		t.Errorf("removed webhook still has %d deliveries", len(log))
+	}
+}
+
+// TestRun tests that the dispatcher drains deliveries queued by any process
+func TestRun(t *testing.T) {
+	openTestDB(t)
+	srv, ch := newReceiver(t, http.StatusNoContent)
+	Add(srv.URL, nil, "segredo")
+
+	bus := events.NewBus(10)
+	d := NewDispatcher()
+	d.Poll = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		d.Run(ctx, bus)
+		close(stopped)
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	// Events on the bus alone are not delivered: only the queue is.
+	bus.Publish(events.Created, &schema.Task{Description: "Só no barramento"})
+	select {
+	case got := <-ch:
+		t.Fatalf("delivery %s without a queued event", got.header.Get(HeaderEvent))
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A change from the command line only queues the delivery.
+	enqueue(t, events.Cleared, nil)
+	select {
+	case got := <-ch:
+		if got.header.Get(HeaderEvent) != string(events.Cleared) {
+			t.Errorf("event = %s, want tasks.cleared", got.header.Get(HeaderEvent))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+	}
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"fmt"
+	"levyvix/togo/internal/webhook"
+	"levyvix/togo/schema"
+	"strconv"
+	"strings"
+)
+
+func WebhookAddFuncDB(args []string, eventList, secret string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("este comando aceita apenas um argumento (a URL), você passou %d", len(args))
+	}
+	types, err := webhook.ParseEvents(eventList)
+	if err != nil {
+		return err
+	}
+	hook, err := webhook.Add(args[0], types, secret)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Webhook %d cadastrado para %s (%s).\n", hook.ID, hook.URL, describeEvents(hook))
+	if secret == "" {
+		fmt.Printf("Chave para conferir a assinatura (%s):\n\n  %s\n\n", webhook.HeaderSignature, hook.Secret)
+	}
+	fmt.Println("As entregas são feitas pelo togo serve enquanto ele estiver rodando.")
+	return nil
+}
+
+func WebhookListFuncDB(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	hooks, err := webhook.List()
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		fmt.Println("Nenhum webhook cadastrado.")
+		return nil
+	}
+	for _, h := range hooks {
+		fmt.Printf("[%d] %s (%s), criado em %s\n", h.ID, h.URL, describeEvents(h), formatDate(h.CreatedAt))
+	}
+	return nil
+}
+
+func WebhookRemoveFuncDB(args []string) error {
+	id, err := parseWebhookID(args, "o ID do webhook")
+	if err != nil {
+		return err
+	}
+	if err := webhook.Remove(id); err != nil {
+		return err
+	}
+	fmt.Printf("Webhook %d removido.\n", id)
+	return nil
+}
+
+func WebhookLogFuncDB(args []string, webhookID uint, limit int) error {
+	if len(args) != 0 {
+		return fmt.Errorf("este comando não aceita argumentos, você passou %d", len(args))
+	}
+	deliveries, err := webhook.Log(webhookID, limit)
+	if err != nil {
+		return err
+	}
+	if len(deliveries) == 0 {
+		fmt.Println("Nenhuma entrega registrada.")
+		return nil
+	}
+	for _, d := range deliveries {
+		status := map[string]string{
+			schema.DeliveryPending:   "⏳ pendente",
+			schema.DeliveryDelivered: "✓ entregue",
+			schema.DeliveryFailed:    "✗ falhou",
+		}[d.Status]
+		fmt.Printf("[%d] %s  webhook %d  %-13s %s, %d tentativa(s)", d.ID, formatDate(d.CreatedAt), d.WebhookID, d.Event, status, d.Attempts)
+		if d.LastCode != 0 {
+			fmt.Printf(", HTTP %d", d.LastCode)
+		}
+		fmt.Println()
+		if d.Status == schema.DeliveryPending && d.Attempts > 0 {
+			fmt.Printf("    próxima tentativa em %s\n", formatDate(d.NextAttempt))
+		}
+		if d.Status != schema.DeliveryDelivered && d.LastError != "" {
+			fmt.Printf("    erro: %s\n", d.LastError)
+		}
+	}
+	return nil
+}
+
+func WebhookRetryFuncDB(args []string) error {
+	id, err := parseWebhookID(args, "o ID da entrega")
+	if err != nil {
+		return err
+	}
+	if err := webhook.Retry(id); err != nil {
+		return err
+	}
+	fmt.Printf("Entrega %d devolvida à fila.\n", id)
+	return nil
+}
+
+// parseWebhookID lê o único argumento, um ID numérico descrito por what.
+func parseWebhookID(args []string, what string) (uint, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("este comando aceita apenas um argumento (%s), você passou %d", what, len(args))
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ID inválido '%s'", args[0])
+	}
+	return uint(id), nil
+}
+
+func describeEvents(h schema.Webhook) string {
+	if len(h.Events) == 0 {
+		return "todos os eventos"
+	}
+	return "eventos " + strings.Join(h.Events, ", ")
+}
@@ -7,9 +7,8 @@ import (
 )
 
 func main() {
-	err := database.InitDB()
-	if err != nil {
+	cmd.Execute()
+	if err := database.Close(); err != nil {
 		log.Fatalf("Erro: %v", err)
 	}
-	cmd.Execute()
 }
@@ -0,0 +1,14 @@
+package schema
+
+import "time"
+
+// CommitLink liga uma tarefa a um commit do git que a menciona
+// ("togo #12" ou "closes togo #12" na mensagem).
+type CommitLink struct {
+	ID        uint   `gorm:"primarykey"`
+	TaskID    uint   `gorm:"uniqueIndex:idx_commit_links_task_sha"`
+	SHA       string `gorm:"uniqueIndex:idx_commit_links_task_sha"`
+	Branch    string
+	Subject   string
+	CreatedAt time.Time
+}
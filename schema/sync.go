@@ -0,0 +1,30 @@
+package schema
+
+// SyncNode guarda a identidade do banco no togo sync push/pull e o último
+// instante do seu relógio lógico híbrido. A tabela tem uma linha só.
+type SyncNode struct {
+	ID    uint `gorm:"primarykey"`
+	Node  string
+	Clock string
+}
+
+// SyncOp é uma operação do log do sync: a atribuição de um valor (em JSON)
+// a um campo de uma tarefa, no instante Time do relógio do banco Node.
+// Time inclui o Node e identifica a operação entre todos os bancos.
+type SyncOp struct {
+	ID       uint   `gorm:"primarykey"`
+	Time     string `gorm:"uniqueIndex"`
+	Node     string `gorm:"index"`
+	TaskUUID string
+	Field    string
+	Value    string
+}
+
+// SyncRegister é o valor atual de um campo de uma tarefa: o da operação
+// mais recente (maior Time) entre as recebidas.
+type SyncRegister struct {
+	TaskUUID string `gorm:"primaryKey"`
+	Field    string `gorm:"primaryKey"`
+	Time     string
+	Value    string
+}
@@ -3,12 +3,58 @@ package schema
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Task struct {
 	gorm.Model
+	// UUID identifica a tarefa entre bancos (sync, exportações), já que o ID
+	// é um auto-incremento local. É gerado na criação; tarefas importadas
+	// mantêm o identificador do arquivo (uuid do Taskwarrior, UID do
+	// iCalendar).
+	UUID        string `gorm:"uniqueIndex"`
 	Description string
 	Done        bool
 	DoneAt      *time.Time
+
+	// Priority é uma letra de A (mais alta) a Z, ou vazio.
+	Priority string
+	// Projects e Contexts correspondem aos tokens +projeto e @contexto do todo.txt.
+	Projects []string `gorm:"serializer:json"`
+	Contexts []string `gorm:"serializer:json"`
+	// Extras guarda pares chave:valor sem campo próprio.
+	Extras map[string]string `gorm:"serializer:json"`
+
+	Due *time.Time
+	// Scheduled é a data em que se pretende começar a tarefa (SCHEDULED do
+	// org-mode), diferente do prazo.
+	Scheduled *time.Time
+	// Recurrence é uma regra de repetição no formato RRULE do iCalendar
+	// (RFC 5545), como "FREQ=WEEKLY;BYDAY=MO".
+	Recurrence  string
+	Tags        []string     `gorm:"serializer:json"`
+	Annotations []Annotation `gorm:"serializer:json"`
+
+	// Fingerprint identifica a tarefa criada a partir de um comentário
+	// TODO/FIXME/HACK pelo togo scan; vazio nas demais tarefas.
+	Fingerprint string `gorm:"index"`
+
+	// OwnerID é o usuário da API que criou a tarefa; nil nas tarefas criadas
+	// pela linha de comando.
+	OwnerID *uint `gorm:"index"`
+}
+
+// BeforeCreate gera o UUID das tarefas que ainda não têm um.
+func (t *Task) BeforeCreate(tx *gorm.DB) error {
+	if t.UUID == "" {
+		t.UUID = uuid.NewString()
+	}
+	return nil
+}
+
+// Annotation é uma nota com data anexada a uma tarefa.
+type Annotation struct {
+	Entry       time.Time `json:"entry"`
+	Description string    `json:"description"`
 }
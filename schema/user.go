@@ -0,0 +1,30 @@
+package schema
+
+import "time"
+
+// User é um usuário da API do togo serve. Administradores podem alterar
+// todas as tarefas; os demais, só as suas e as compartilhadas com eles.
+type User struct {
+	ID        uint   `gorm:"primarykey"`
+	Name      string `gorm:"uniqueIndex"`
+	Admin     bool
+	CreatedAt time.Time
+}
+
+// Token é um token de acesso de um usuário à API. Só o hash SHA-256 do
+// token é guardado; o token em si é mostrado uma vez, na criação.
+type Token struct {
+	ID         uint `gorm:"primarykey"`
+	UserID     uint `gorm:"index"`
+	Name       string
+	Hash       string `gorm:"uniqueIndex"`
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// TaskShare dá a um usuário acesso de escrita a uma tarefa de outro.
+type TaskShare struct {
+	TaskID    uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,42 @@
+package schema
+
+import "time"
+
+// Webhook é um endereço que recebe, por POST, os eventos de tarefas do
+// togo serve. Events lista os tipos assinados (vazio assina todos), e
+// Secret é a chave do HMAC que assina cada entrega.
+type Webhook struct {
+	ID        uint `gorm:"primarykey"`
+	URL       string
+	Events    []string `gorm:"serializer:json"`
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Estados de uma WebhookDelivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery é uma entrega de evento a um webhook: enquanto pendente,
+// é a fila de envio (com a próxima tentativa em NextAttempt); depois,
+// fica como registro do resultado.
+type WebhookDelivery struct {
+	ID          uint `gorm:"primarykey"`
+	WebhookID   uint `gorm:"index"`
+	EventID     string
+	Event       string
+	Payload     string
+	Status      string `gorm:"index"`
+	Attempts    int
+	NextAttempt time.Time `gorm:"index"`
+	// LastCode é o status HTTP da última tentativa (0 se não houve
+	// resposta), e LastError o motivo da falha.
+	LastCode    int
+	LastError   string
+	DeliveredAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}